@@ -1,6 +1,7 @@
 package main
 
 import (
+	"io"
 	"testing"
 	"time"
 
@@ -9,14 +10,14 @@ import (
 
 func BenchmarkGetTasksOldWay(b *testing.B) {
 	// Ensure review config is set up
-	if err := taskwarrior.EnsureReviewConfig(); err != nil {
+	if err := taskwarrior.EnsureReviewConfig(b.Context(), io.Discard); err != nil {
 		b.Fatal(err)
 	}
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		// Get UUIDs
-		uuids, err := taskwarrior.GetTasksForReview()
+		uuids, err := taskwarrior.GetTasksForReview(b.Context())
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -28,7 +29,7 @@ func BenchmarkGetTasksOldWay(b *testing.B) {
 
 		// Get each task individually (old way)
 		for _, uuid := range uuids {
-			_, err := taskwarrior.GetTaskInfo(uuid)
+			_, err := taskwarrior.GetTaskInfo(b.Context(), uuid)
 			if err != nil {
 				b.Fatal(err)
 			}
@@ -38,14 +39,14 @@ func BenchmarkGetTasksOldWay(b *testing.B) {
 
 func BenchmarkGetTasksNewWay(b *testing.B) {
 	// Ensure review config is set up
-	if err := taskwarrior.EnsureReviewConfig(); err != nil {
+	if err := taskwarrior.EnsureReviewConfig(b.Context(), io.Discard); err != nil {
 		b.Fatal(err)
 	}
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		// Get all tasks with data in one call
-		tasks, err := taskwarrior.GetTasksForReviewWithData()
+		tasks, err := taskwarrior.GetTasksForReviewWithData(b.Context())
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -62,13 +63,13 @@ func BenchmarkGetTasksNewWay(b *testing.B) {
 
 func TestPerformanceComparison(t *testing.T) {
 	// Ensure review config is set up
-	if err := taskwarrior.EnsureReviewConfig(); err != nil {
+	if err := taskwarrior.EnsureReviewConfig(t.Context(), io.Discard); err != nil {
 		t.Fatal(err)
 	}
 
 	// Test old way
 	start := time.Now()
-	uuids, err := taskwarrior.GetTasksForReview()
+	uuids, err := taskwarrior.GetTasksForReview(t.Context())
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -79,7 +80,7 @@ func TestPerformanceComparison(t *testing.T) {
 	}
 
 	for _, uuid := range uuids {
-		_, err := taskwarrior.GetTaskInfo(uuid)
+		_, err := taskwarrior.GetTaskInfo(t.Context(), uuid)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -88,7 +89,7 @@ func TestPerformanceComparison(t *testing.T) {
 
 	// Test new way
 	start = time.Now()
-	tasks, err := taskwarrior.GetTasksForReviewWithData()
+	tasks, err := taskwarrior.GetTasksForReviewWithData(t.Context())
 	if err != nil {
 		t.Fatal(err)
 	}
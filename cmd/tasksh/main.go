@@ -1,44 +1,67 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"strconv"
+	"os/signal"
 
 	"github.com/emiller/tasksh/internal/cli"
 	"github.com/emiller/tasksh/internal/planning"
 	"github.com/emiller/tasksh/internal/review"
+	"github.com/emiller/tasksh/internal/taskwarrior"
+	"github.com/emiller/tasksh/internal/waiting"
 )
 
 func main() {
-	args := os.Args[1:]
+	args, err := cli.SelectProfile(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 
 	if len(args) == 0 {
 		fmt.Println("tasksh - Interactive task management shell")
 		fmt.Println("Usage:")
-		fmt.Println("  tasksh review [limit]     - Start task review")
+		fmt.Println("  tasksh review [queue] [limit] [--filter F] [--resume] - Start or resume task review")
 		fmt.Println("  tasksh plan today         - Plan today's tasks")
 		fmt.Println("  tasksh plan tomorrow      - Plan tomorrow's tasks")
 		fmt.Println("  tasksh plan week          - Plan upcoming week")
 		fmt.Println("  tasksh plan quick         - Quick planning (3 critical tasks)")
+		fmt.Println("  tasksh graph [--dot]      - Show task dependencies")
+		fmt.Println("  tasksh waiting            - Show what you're waiting for")
+		fmt.Println("  tasksh timedb import      - Import completion times from Taskwarrior")
+		fmt.Println("  tasksh timedb backfill    - Record time tracked in Timewarrior")
 		fmt.Println("  tasksh preview            - Preview UI states")
 		fmt.Println("  tasksh help               - Show help")
 		fmt.Println("  tasksh diagnostics        - Show diagnostics")
+		fmt.Println()
+		fmt.Println("Add --profile NAME to use a profile from the tasksh config")
 		os.Exit(0)
 	}
 
+	// Ctrl-C while tasks load (before the TUI takes the terminal) cancels
+	// the running task command
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	backend, err := cli.NewBackend()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	switch args[0] {
 	case "review":
-		limit := 0
-		if len(args) > 1 {
-			if l, err := strconv.Atoi(args[1]); err == nil {
-				limit = l
-			}
-		}
-		if err := review.Run(limit); err != nil {
+		opts, err := cli.ParseReviewArgs(args[1:])
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
+		if err := review.RunWithOptions(ctx, backend, opts); err != nil {
+			fmt.Fprintln(os.Stderr, taskwarrior.FormatError(err))
+			os.Exit(1)
+		}
 	case "plan":
 		if len(args) < 2 {
 			fmt.Println("Usage: tasksh plan <today|tomorrow|week|quick>")
@@ -61,8 +84,23 @@ func main() {
 			os.Exit(1)
 		}
 		
-		if err := planning.Run(horizon); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		if err := planning.RunWithBackend(ctx, horizon, backend); err != nil {
+			fmt.Fprintln(os.Stderr, taskwarrior.FormatError(err))
+			os.Exit(1)
+		}
+	case "graph":
+		if err := cli.RunGraph(ctx, backend, args[1:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, taskwarrior.FormatError(err))
+			os.Exit(1)
+		}
+	case "waiting":
+		if err := waiting.RunWithBackend(ctx, backend); err != nil {
+			fmt.Fprintln(os.Stderr, taskwarrior.FormatError(err))
+			os.Exit(1)
+		}
+	case "timedb":
+		if err := cli.RunTimeDB(ctx, backend, args[1:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, taskwarrior.FormatError(err))
 			os.Exit(1)
 		}
 	case "preview":
@@ -28,7 +28,7 @@ func TestBuildAnalysisPrompt(t *testing.T) {
 		Description: "Implement new feature",
 		Project:     "myproject",
 		Priority:    "H",
-		Due:         "2024-12-31",
+		Due:         taskwarrior.NewDate(time.Date(2024, 12, 31, 0, 0, 0, 0, time.Local)),
 		Status:      "pending",
 	}
 
@@ -55,6 +55,10 @@ func TestBuildAnalysisPrompt(t *testing.T) {
 		t.Error("Prompt missing project")
 	}
 
+	if !strings.Contains(prompt, "**Due Date**: 2024-12-31 (") {
+		t.Error("Prompt missing local due date")
+	}
+
 	if !strings.Contains(prompt, "3.5 hours") {
 		t.Error("Prompt missing time estimate")
 	}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/openai/openai-go"
 	"github.com/openai/openai-go/option"
@@ -37,16 +38,30 @@ type TaskAnalysis struct {
 
 // Analyzer handles AI-powered task analysis
 type Analyzer struct {
-	timeDB *timedb.TimeDB
+	timeDB  *timedb.TimeDB
+	backend taskwarrior.TaskBackend
 }
 
 // NewAnalyzer creates a new AI analyzer
 func NewAnalyzer(timeDB *timedb.TimeDB) *Analyzer {
-	return &Analyzer{timeDB: timeDB}
+	return NewAnalyzerWithBackend(timeDB, taskwarrior.NewExecBackend())
+}
+
+// NewAnalyzerWithBackend creates a new AI analyzer using the given task backend
+func NewAnalyzerWithBackend(timeDB *timedb.TimeDB, backend taskwarrior.TaskBackend) *Analyzer {
+	return &Analyzer{timeDB: timeDB, backend: backend}
+}
+
+// Close closes the analyzer's time database
+func (ai *Analyzer) Close() error {
+	if ai.timeDB == nil {
+		return nil
+	}
+	return ai.timeDB.Close()
 }
 
 // AnalyzeTask performs AI analysis of a task using OpenAI API
-func (ai *Analyzer) AnalyzeTask(task *taskwarrior.Task) (*TaskAnalysis, error) {
+func (ai *Analyzer) AnalyzeTask(ctx context.Context, task *taskwarrior.Task) (*TaskAnalysis, error) {
 	if err := CheckOpenAIAvailable(); err != nil {
 		return nil, err
 	}
@@ -68,7 +83,6 @@ func (ai *Analyzer) AnalyzeTask(task *taskwarrior.Task) (*TaskAnalysis, error) {
 	client := openai.NewClient(option.WithAPIKey(apiKey))
 
 	// Call OpenAI API
-	ctx := context.Background()
 	resp, err := client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
 		Messages: []openai.ChatCompletionMessageParamUnion{
 			openai.UserMessage(prompt),
@@ -88,7 +102,7 @@ func (ai *Analyzer) AnalyzeTask(task *taskwarrior.Task) (*TaskAnalysis, error) {
 }
 
 // GenerateCommands uses AI to convert a natural language prompt into taskwarrior/timewarrior commands
-func (ai *Analyzer) GenerateCommands(task *taskwarrior.Task, prompt string) ([]string, string, error) {
+func (ai *Analyzer) GenerateCommands(ctx context.Context, task *taskwarrior.Task, prompt string) ([]string, string, error) {
 	if err := CheckOpenAIAvailable(); err != nil {
 		return nil, "", err
 	}
@@ -106,7 +120,6 @@ func (ai *Analyzer) GenerateCommands(task *taskwarrior.Task, prompt string) ([]s
 	client := openai.NewClient(option.WithAPIKey(apiKey))
 
 	// Call OpenAI API
-	ctx := context.Background()
 	resp, err := client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
 		Messages: []openai.ChatCompletionMessageParamUnion{
 			openai.UserMessage(agentPrompt),
@@ -138,7 +151,7 @@ func (ai *Analyzer) buildAnalysisPrompt(task *taskwarrior.Task, estimate float64
 	prompt.WriteString(fmt.Sprintf("- **Description**: %s\n", task.Description))
 	prompt.WriteString(fmt.Sprintf("- **Project**: %s\n", getValueOrEmpty(task.Project)))
 	prompt.WriteString(fmt.Sprintf("- **Priority**: %s\n", getValueOrEmpty(task.Priority)))
-	prompt.WriteString(fmt.Sprintf("- **Due Date**: %s\n", getValueOrEmpty(task.Due)))
+	prompt.WriteString(fmt.Sprintf("- **Due Date**: %s\n", getValueOrEmpty(formatPromptDate(task.Due))))
 	prompt.WriteString(fmt.Sprintf("- **Status**: %s\n", task.Status))
 	
 	// Historical context
@@ -225,8 +238,8 @@ func (ai *Analyzer) buildAgentPrompt(task *taskwarrior.Task, userPrompt string)
 	prompt.WriteString(fmt.Sprintf("- **Project**: %s\n", getValueOrEmpty(task.Project)))
 	prompt.WriteString(fmt.Sprintf("- **Priority**: %s\n", getValueOrEmpty(task.Priority)))
 	prompt.WriteString(fmt.Sprintf("- **Status**: %s\n", task.Status))
-	if task.Due != "" {
-		prompt.WriteString(fmt.Sprintf("- **Due**: %s\n", task.Due))
+	if !task.Due.IsZero() {
+		prompt.WriteString(fmt.Sprintf("- **Due**: %s\n", formatPromptDate(task.Due)))
 	}
 	prompt.WriteString("\n")
 	
@@ -332,4 +345,12 @@ func getValueOrEmpty(value string) string {
 		return "none"
 	}
 	return value
+}
+
+// formatPromptDate renders a due date in local time with how far off it is
+func formatPromptDate(due taskwarrior.Date) string {
+	if due.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%s (%s)", due.LocalString(), due.DueRelative(time.Now()))
 }
\ No newline at end of file
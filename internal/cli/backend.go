@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/emiller/tasksh/internal/taskwarrior"
+	"github.com/emiller/tasksh/internal/taskwarrior/memory"
+)
+
+// NewBackend returns the task backend selected by TASKSH_BACKEND.
+// Supported values are "task" (the default, runs the task command),
+// "memory" (an empty in-memory store) and "memory:FILE", which seeds the
+// in-memory store from a `task export` JSON file.
+func NewBackend() (taskwarrior.TaskBackend, error) {
+	spec := os.Getenv("TASKSH_BACKEND")
+	name, path, _ := strings.Cut(spec, ":")
+
+	switch name {
+	case "", "task":
+		return taskwarrior.NewExecBackend(), nil
+	case "memory":
+		backend := memory.New()
+		if path == "" {
+			return backend, nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open task export: %w", err)
+		}
+		defer f.Close()
+		if err := backend.Load(f); err != nil {
+			return nil, fmt.Errorf("failed to load task export: %w", err)
+		}
+		return backend, nil
+	default:
+		return nil, fmt.Errorf("unknown task backend %q (expected task or memory)", name)
+	}
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/emiller/tasksh/internal/ai"
@@ -16,11 +17,42 @@ func ShowDiagnostics() {
 	fmt.Println()
 	
 	// Check if task command is available
-	if err := taskwarrior.CheckAvailable(); err != nil {
+	if err := taskwarrior.CheckAvailable(context.Background()); err != nil {
 		fmt.Printf("Taskwarrior: NOT FOUND - %v\n", err)
 	} else {
 		fmt.Println("Taskwarrior: Available")
 	}
+
+	// Report the profile selected with --profile or the tasksh config
+	if name := taskwarrior.ActiveProfile(); name != "" {
+		fmt.Printf("Profile: %s\n", name)
+	} else {
+		fmt.Println("Profile: none (add --profile NAME to select one)")
+	}
+
+	// Report the config file and what it defines
+	if rc, err := taskwarrior.LoadTaskrc(); err != nil {
+		fmt.Printf("Taskrc: NOT READABLE - %v\n", err)
+	} else {
+		fmt.Printf("Taskrc: %s (%d file(s) with includes)\n", rc.Path, len(rc.Files))
+		fmt.Printf("Data Location: %s\n", rc.DataLocation())
+		fmt.Printf("Contexts: %d defined, current: %s\n", len(rc.Contexts()), rc.CurrentContext())
+		fmt.Printf("UDAs: %d defined\n", len(rc.UDAs()))
+	}
+
+	// Report whether tasks are read directly from TaskChampion storage
+	if taskwarrior.DirectReadEnabled() {
+		fmt.Println("Direct Read: Enabled (TaskChampion storage, falls back to export)")
+	} else {
+		fmt.Println("Direct Read: Disabled (set TASKSH_DIRECT_READ=1 to enable)")
+	}
+
+	// Report the per-call timeout for task commands
+	if timeout := taskwarrior.CommandTimeout(); timeout > 0 {
+		fmt.Printf("Task Timeout: %s per call (set TASKSH_TIMEOUT to change)\n", timeout)
+	} else {
+		fmt.Println("Task Timeout: Disabled")
+	}
 	
 	// Check if OpenAI API is available  
 	if err := ai.CheckOpenAIAvailable(); err != nil {
@@ -35,6 +67,7 @@ func ShowDiagnostics() {
 		fmt.Printf("Time Database: ERROR - %v\n", err)
 	} else {
 		db.Close()
-		fmt.Println("Time Database: Available")
+		path, _ := timedb.Path()
+		fmt.Printf("Time Database: Available (%s)\n", path)
 	}
 }
\ No newline at end of file
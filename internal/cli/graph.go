@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/emiller/tasksh/internal/taskwarrior"
+	"github.com/emiller/tasksh/internal/taskwarrior/filter"
+)
+
+// RunGraph prints the dependency graph of the open tasks matching the filter
+// in args as an ASCII tree, or as Graphviz DOT with --dot. Finished blockers
+// are included so the whole chain is visible.
+func RunGraph(ctx context.Context, backend taskwarrior.TaskBackend, args []string, w io.Writer) error {
+	dot := false
+	var filterArgs []string
+	for _, arg := range args {
+		if arg == "--dot" {
+			dot = true
+		} else {
+			filterArgs = append(filterArgs, arg)
+		}
+	}
+
+	var query filter.Expr = filter.Or(filter.HasTag("PENDING"), filter.HasTag("WAITING"))
+	if len(filterArgs) > 0 {
+		expr, err := filter.Parse(filterArgs)
+		if err != nil {
+			return fmt.Errorf("invalid filter: %w", err)
+		}
+		query = filter.And(expr, query)
+	}
+
+	uuids, err := backend.FilterUUIDs(ctx, query.Args())
+	if err != nil {
+		return fmt.Errorf("failed to find tasks: %w", err)
+	}
+	tasks, err := backend.GetTasksWithDataProgress(ctx, uuids, nil)
+	if err != nil {
+		return fmt.Errorf("failed to load tasks: %w", err)
+	}
+	graph, err := taskwarrior.LoadDependencyGraph(ctx, backend, tasks)
+	if err != nil {
+		return err
+	}
+
+	if dot {
+		writeDOT(w, graph)
+	} else {
+		writeASCII(w, graph)
+	}
+	return nil
+}
+
+// linked returns the tasks that block or are blocked by another task
+func linked(graph *taskwarrior.DependencyGraph) []*taskwarrior.TaskData {
+	var tasks []*taskwarrior.TaskData
+	for _, td := range graph.Tasks() {
+		if len(graph.BlockedBy(td.UUID)) > 0 || len(graph.Blocking(td.UUID)) > 0 {
+			tasks = append(tasks, td)
+		}
+	}
+	return tasks
+}
+
+// graphLabel names a task in the graph by ID, or by status once finished
+func graphLabel(td *taskwarrior.TaskData) string {
+	if !taskwarrior.IsOpen(td) {
+		return fmt.Sprintf("%s (%s)", td.Description, td.Status)
+	}
+	if td.ID > 0 {
+		return fmt.Sprintf("[%d] %s", td.ID, td.Description)
+	}
+	return td.Description
+}
+
+// writeASCII draws each task that nothing depends on as a tree of the
+// tasks blocking it. Tasks already drawn are not expanded again.
+func writeASCII(w io.Writer, graph *taskwarrior.DependencyGraph) {
+	tasks := linked(graph)
+	if len(tasks) == 0 {
+		fmt.Fprintln(w, "No task dependencies found.")
+		return
+	}
+
+	drawn := make(map[string]bool)
+	var draw func(td *taskwarrior.TaskData, prefix, branch string, path []string)
+	draw = func(td *taskwarrior.TaskData, prefix, branch string, path []string) {
+		label := graphLabel(td)
+		switch {
+		case slices.Contains(path, td.UUID):
+			fmt.Fprintf(w, "%s%s%s (cycle)\n", prefix, branch, label)
+			return
+		case drawn[td.UUID]:
+			fmt.Fprintf(w, "%s%s%s (see above)\n", prefix, branch, label)
+			return
+		}
+		fmt.Fprintf(w, "%s%s%s\n", prefix, branch, label)
+		drawn[td.UUID] = true
+
+		switch branch {
+		case "├── ":
+			prefix += "│   "
+		case "└── ":
+			prefix += "    "
+		}
+		blockers := graph.BlockedBy(td.UUID)
+		for i, blocker := range blockers {
+			next := "├── "
+			if i == len(blockers)-1 {
+				next = "└── "
+			}
+			draw(blocker, prefix, next, append(path, td.UUID))
+		}
+	}
+
+	// Start from the tasks nothing depends on, then anything left in cycles
+	for _, td := range tasks {
+		if len(graph.Blocking(td.UUID)) == 0 {
+			draw(td, "", "", nil)
+		}
+	}
+	for _, td := range tasks {
+		if !drawn[td.UUID] {
+			draw(td, "", "", nil)
+		}
+	}
+}
+
+// writeDOT writes the graph in Graphviz DOT, with edges from each blocker to
+// the task it blocks
+func writeDOT(w io.Writer, graph *taskwarrior.DependencyGraph) {
+	fmt.Fprintln(w, "digraph tasks {")
+	fmt.Fprintln(w, "\trankdir=LR;")
+	fmt.Fprintln(w, "\tnode [shape=box];")
+	tasks := linked(graph)
+	for _, td := range tasks {
+		attrs := fmt.Sprintf("label=%q", graphLabel(td))
+		if !taskwarrior.IsOpen(td) {
+			attrs += ", style=dashed"
+		}
+		fmt.Fprintf(w, "\t%q [%s];\n", td.UUID, attrs)
+	}
+	for _, td := range tasks {
+		for _, blocker := range graph.BlockedBy(td.UUID) {
+			fmt.Fprintf(w, "\t%q -> %q;\n", blocker.UUID, td.UUID)
+		}
+	}
+	fmt.Fprintln(w, "}")
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/emiller/tasksh/internal/taskwarrior/memory"
+)
+
+// newGraphBackend returns a backend with a release blocked by a finished
+// and an open task, and an unrelated task
+func newGraphBackend(t *testing.T) (*memory.Backend, map[string]string) {
+	t.Helper()
+	backend := memory.New()
+	uuids := make(map[string]string)
+	add := func(name, description string, mods ...string) {
+		uuid, err := backend.Add(description, mods...)
+		if err != nil {
+			t.Fatalf("Failed to add %s: %v", name, err)
+		}
+		uuids[name] = uuid
+	}
+
+	add("runner", "Update runner")
+	add("ci", "Fix CI", "depends:"+uuids["runner"])
+	add("docs", "Write docs", "project:Docs")
+	add("release", "Ship release", "depends:"+uuids["docs"]+","+uuids["ci"])
+	add("other", "Unrelated")
+	if err := backend.CompleteTask(t.Context(), uuids["docs"]); err != nil {
+		t.Fatalf("Failed to complete docs: %v", err)
+	}
+	return backend, uuids
+}
+
+func TestRunGraphASCII(t *testing.T) {
+	backend, _ := newGraphBackend(t)
+
+	var out bytes.Buffer
+	if err := RunGraph(t.Context(), backend, nil, &out); err != nil {
+		t.Fatalf("RunGraph failed: %v", err)
+	}
+
+	want := `[3] Ship release
+├── Write docs (completed)
+└── [2] Fix CI
+    └── [1] Update runner
+`
+	if out.String() != want {
+		t.Errorf("RunGraph output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestRunGraphDOT(t *testing.T) {
+	backend, uuids := newGraphBackend(t)
+
+	var out bytes.Buffer
+	if err := RunGraph(t.Context(), backend, []string{"--dot"}, &out); err != nil {
+		t.Fatalf("RunGraph failed: %v", err)
+	}
+
+	got := out.String()
+	for _, want := range []string{
+		"digraph tasks {",
+		`"` + uuids["docs"] + `" [label="Write docs (completed)", style=dashed];`,
+		`"` + uuids["ci"] + `" -> "` + uuids["release"] + `";`,
+		`"` + uuids["runner"] + `" -> "` + uuids["ci"] + `";`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("DOT output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, uuids["other"]) {
+		t.Errorf("Tasks without dependencies should be left out:\n%s", got)
+	}
+}
+
+func TestRunGraphFilter(t *testing.T) {
+	backend, _ := newGraphBackend(t)
+
+	var out bytes.Buffer
+	if err := RunGraph(t.Context(), backend, []string{"description:Unrelated"}, &out); err != nil {
+		t.Fatalf("RunGraph failed: %v", err)
+	}
+	if out.String() != "No task dependencies found.\n" {
+		t.Errorf("Unexpected output for a task without dependencies: %q", out.String())
+	}
+}
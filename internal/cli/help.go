@@ -18,10 +18,34 @@ func ShowHelp() {
 	fmt.Println("  plan tomorrow      Plan tomorrow's tasks with time estimates")
 	fmt.Println("  plan week          Plan upcoming week's tasks")
 	fmt.Println("  review [N]         Review tasks (optionally limit to N tasks)")
+	fmt.Println("  review QUEUE [N]   Review a named queue from the tasksh config")
+	fmt.Println("    --filter F       Only review tasks matching the Taskwarrior filter F")
+	fmt.Println("    --resume         Continue the review you quit, where you left off")
+	fmt.Println("    --report FILE    Write a session report to FILE (.md, or .json for JSON)")
+	fmt.Println("  graph [--dot] [F]  Show dependencies of tasks matching filter F (DOT for Graphviz)")
+	fmt.Println("  waiting            Show waiting tasks by person and reason; nudge or un-wait them")
+	fmt.Println("  timedb import      Record completed tasks' time taken (duration UDA, start/end or journal.time)")
+	fmt.Println("    --dry-run        List what would be added without writing")
+	fmt.Println("    --uda NAME       Duration UDA to read (default: duration)")
+	fmt.Println("  timedb backfill    Record completed tasks' time tracked in Timewarrior")
+	fmt.Println("    --dry-run        Count what would be recorded without writing")
 	fmt.Println("  preview            Preview UI states for design iteration")
 	fmt.Println("  help               Show this help")
 	fmt.Println("  diagnostics        Show system diagnostics")
 	fmt.Println()
+	fmt.Println("Options:")
+	fmt.Println("  --profile NAME     Use a profile from the tasksh config (~/.config/tasksh/config)")
+	fmt.Println()
+	fmt.Println("Review queues are defined in the tasksh config:")
+	fmt.Println("  queue.bugs.filter=project:work +bug")
+	fmt.Println("  queue.bugs.sort=urgency-")
+	fmt.Println("  queue.bugs.interval=2days")
+	fmt.Println("Review intervals can also be set per tag, project prefix or priority,")
+	fmt.Println("for the default queue or a named one:")
+	fmt.Println("  review.interval.project.Work.Fast=1d")
+	fmt.Println("  review.interval.tag.someday=quarterly")
+	fmt.Println("  queue.bugs.interval.priority.H=1d")
+	fmt.Println()
 	fmt.Println("Planning Features:")
 	fmt.Println("  - Smart task selection based on urgency and due dates")
 	fmt.Println("  - Time estimation using historical data")
@@ -41,7 +65,7 @@ func ShowHelp() {
 	
 	fmt.Println("  - Complete task (with optional time tracking)")
 	fmt.Println("  - Delete task")
-	fmt.Println("  - Wait task (set waiting status with date and reason)")
+	fmt.Println("  - Wait task (set waiting status with date, reason and who it waits on)")
 	fmt.Println("  - Due date (set or modify task due date)")
 	fmt.Println("  - Skip task (will need review again later)")
 	fmt.Println("  - Mark as reviewed")
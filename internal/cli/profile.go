@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/emiller/tasksh/internal/taskwarrior"
+)
+
+// SelectProfile removes a --profile NAME (or --profile=NAME) flag from args
+// and switches to that profile. Without the flag, TASKSH_PROFILE and then
+// the profile= default from the tasksh config are used.
+func SelectProfile(args []string) ([]string, error) {
+	name := ""
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "--profile":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("--profile needs a profile name")
+			}
+			i++
+			name = args[i]
+		case strings.HasPrefix(arg, "--profile="):
+			name = strings.TrimPrefix(arg, "--profile=")
+		default:
+			rest = append(rest, arg)
+		}
+	}
+
+	if name == "" {
+		name = os.Getenv("TASKSH_PROFILE")
+	}
+	if name == "" {
+		_, defaultName, err := taskwarrior.LoadProfiles()
+		if err != nil {
+			return nil, err
+		}
+		name = defaultName
+	}
+	if name == "" {
+		return rest, nil
+	}
+
+	profile, err := taskwarrior.FindProfile(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := taskwarrior.UseProfile(profile); err != nil {
+		return nil, err
+	}
+	return rest, nil
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/emiller/tasksh/internal/taskwarrior"
+)
+
+func TestSelectProfile(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config")
+	content := "profile=home\nprofile.home.data=/data/home\nprofile.work.data=/data/work\n"
+	if err := os.WriteFile(config, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	t.Setenv("TASKSH_CONFIG", config)
+	t.Setenv("TASKSH_PROFILE", "")
+	t.Setenv("TASKDATA", "")
+	t.Setenv("TASKRC", "")
+	t.Setenv("TASKSH_TIMEDB", "")
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantArgs []string
+		wantData string
+	}{
+		{"flag", []string{"--profile", "work", "review", "5"}, []string{"review", "5"}, "/data/work"},
+		{"flag with value", []string{"review", "--profile=work"}, []string{"review"}, "/data/work"},
+		{"config default", []string{"plan", "today"}, []string{"plan", "today"}, "/data/home"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := SelectProfile(tt.args)
+			if err != nil {
+				t.Fatalf("SelectProfile failed: %v", err)
+			}
+			if !slices.Equal(args, tt.wantArgs) {
+				t.Errorf("SelectProfile() args = %v, want %v", args, tt.wantArgs)
+			}
+			if got := os.Getenv("TASKDATA"); got != tt.wantData {
+				t.Errorf("TASKDATA = %q, want %q", got, tt.wantData)
+			}
+		})
+	}
+
+	if _, err := SelectProfile([]string{"--profile", "nope"}); err == nil {
+		t.Error("Expected error for unknown profile")
+	}
+	if _, err := SelectProfile([]string{"review", "--profile"}); err == nil {
+		t.Error("Expected error for missing profile name")
+	}
+	if err := taskwarrior.UseProfile(taskwarrior.Profile{}); err != nil {
+		t.Fatalf("Failed to restore environment: %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/emiller/tasksh/internal/review"
+	"github.com/emiller/tasksh/internal/taskwarrior"
+)
+
+// ParseReviewArgs parses `tasksh review [queue] [limit] [--filter F]
+// [--resume] [--report FILE]` into review options. A queue name picks a
+// queue from the tasksh config; --filter narrows the queue further.
+func ParseReviewArgs(args []string) (review.Options, error) {
+	name := ""
+	extra := ""
+	limit := 0
+	resume := false
+	report := ""
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "--resume":
+			resume = true
+		case arg == "--filter":
+			if i+1 >= len(args) {
+				return review.Options{}, fmt.Errorf("--filter needs a filter")
+			}
+			i++
+			extra = args[i]
+		case strings.HasPrefix(arg, "--filter="):
+			extra = strings.TrimPrefix(arg, "--filter=")
+		case arg == "--report":
+			if i+1 >= len(args) {
+				return review.Options{}, fmt.Errorf("--report needs a file")
+			}
+			i++
+			report = args[i]
+		case strings.HasPrefix(arg, "--report="):
+			report = strings.TrimPrefix(arg, "--report=")
+		default:
+			if n, err := strconv.Atoi(arg); err == nil {
+				limit = n
+			} else if name == "" {
+				name = arg
+			} else {
+				return review.Options{}, fmt.Errorf("unexpected argument %q", arg)
+			}
+		}
+	}
+
+	// The config can change the default queue too
+	if name == "" {
+		name = taskwarrior.DefaultQueueName
+	}
+	queue, err := taskwarrior.FindReviewQueue(name)
+	if err != nil {
+		return review.Options{}, err
+	}
+
+	if extra = strings.TrimSpace(extra); extra != "" {
+		if queue.Filter == "" {
+			queue.Filter = extra
+		} else {
+			// Both sides are grouped so an "or" in either can't escape
+			queue.Filter = "( " + queue.Filter + " ) ( " + extra + " )"
+		}
+	}
+	if _, err := queue.Expr(); err != nil {
+		return review.Options{}, err
+	}
+	return review.Options{Queue: queue, Limit: limit, Resume: resume, Report: report}, nil
+}
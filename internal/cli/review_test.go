@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/emiller/tasksh/internal/taskwarrior"
+	"github.com/emiller/tasksh/internal/taskwarrior/filter"
+)
+
+func TestParseReviewArgs(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "config")
+	content := "queue.bugs.filter=project:work +bug\nqueue.bugs.sort=urgency-\nqueue.bugs.interval=2days\nqueue.default.interval=2w\n"
+	if err := os.WriteFile(config, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	t.Setenv("TASKSH_CONFIG", config)
+
+	tests := []struct {
+		name      string
+		args      []string
+		wantQueue taskwarrior.ReviewQueue
+		wantLimit int
+	}{
+		{"default", nil, taskwarrior.ReviewQueue{Name: "default", Sort: "reviewed+,modified+", Interval: "2w"}, 0},
+		{"limit", []string{"5"}, taskwarrior.ReviewQueue{Name: "default", Sort: "reviewed+,modified+", Interval: "2w"}, 5},
+		{"filter", []string{"--filter", "project:home"}, taskwarrior.ReviewQueue{Name: "default", Filter: "project:home", Sort: "reviewed+,modified+", Interval: "2w"}, 0},
+		{"queue", []string{"bugs", "10"}, taskwarrior.ReviewQueue{Name: "bugs", Filter: "project:work +bug", Sort: "urgency-", Interval: "2days"}, 10},
+		{"queue and filter", []string{"bugs", "--filter=priority:H"}, taskwarrior.ReviewQueue{Name: "bugs", Filter: "( project:work +bug ) ( priority:H )", Sort: "urgency-", Interval: "2days"}, 0},
+		{"resume", []string{"bugs", "--resume"}, taskwarrior.ReviewQueue{Name: "bugs", Filter: "project:work +bug", Sort: "urgency-", Interval: "2days"}, 0},
+		{"report", []string{"--report", "out.md", "3"}, taskwarrior.ReviewQueue{Name: "default", Sort: "reviewed+,modified+", Interval: "2w"}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := ParseReviewArgs(tt.args)
+			if err != nil {
+				t.Fatalf("ParseReviewArgs failed: %v", err)
+			}
+			if !reflect.DeepEqual(opts.Queue, tt.wantQueue) || opts.Limit != tt.wantLimit || opts.Resume != (tt.name == "resume") || (opts.Report == "out.md") != (tt.name == "report") {
+				t.Errorf("ParseReviewArgs() = %+v, want %+v, %d", opts, tt.wantQueue, tt.wantLimit)
+			}
+		})
+	}
+
+	// An "or" in --filter stays inside the queue's filter
+	opts, err := ParseReviewArgs([]string{"bugs", "--filter", "priority:H or priority:M"})
+	if err != nil {
+		t.Fatalf("ParseReviewArgs failed: %v", err)
+	}
+	expr, err := opts.Queue.Expr()
+	if err != nil {
+		t.Fatalf("Invalid queue filter %q: %v", opts.Queue.Filter, err)
+	}
+	for _, tt := range []struct {
+		task *taskwarrior.TaskData
+		want bool
+	}{
+		{&taskwarrior.TaskData{Project: "work", Tags: []string{"bug"}, Priority: "M", Status: "pending"}, true},
+		{&taskwarrior.TaskData{Project: "home", Priority: "M", Status: "pending"}, false},
+	} {
+		if matched, _ := filter.Match(expr, tt.task, time.Now()); matched != tt.want {
+			t.Errorf("Filter %q matching project %s: got %v, want %v", opts.Queue.Filter, tt.task.Project, matched, tt.want)
+		}
+	}
+
+	for _, args := range [][]string{{"nope"}, {"--filter"}, {"--filter", "( project:work"}, {"--report"}} {
+		if _, err := ParseReviewArgs(args); err == nil {
+			t.Errorf("ParseReviewArgs(%q) should fail", args)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/emiller/tasksh/internal/taskwarrior"
+	"github.com/emiller/tasksh/internal/taskwarrior/filter"
+	"github.com/emiller/tasksh/internal/timedb"
+	"github.com/emiller/tasksh/internal/timewarrior"
+)
+
+// defaultDurationUDA is the UDA read for the time a completed task took
+const defaultDurationUDA = "duration"
+
+// maxWorkSpan bounds the time taken derived from a task's start and end.
+// A longer span more likely means a start left running than work.
+const maxWorkSpan = 24 * time.Hour
+
+// Taskwarrior's default journal.time.start.annotation and
+// journal.time.stop.annotation
+const (
+	defaultStartAnnotation = "Started task"
+	defaultStopAnnotation  = "Stopped task"
+)
+
+// ImportOptions configures `tasksh timedb import`
+type ImportOptions struct {
+	DryRun bool   // Report what would be added without writing
+	UDA    string // Duration UDA holding the time taken
+
+	// Annotations journal.time adds when a task starts and stops
+	StartAnnotation string
+	StopAnnotation  string
+}
+
+// timeDBUsage lists the `tasksh timedb` subcommands
+const timeDBUsage = "usage: tasksh timedb import [--dry-run] [--uda NAME] | tasksh timedb backfill [--dry-run]"
+
+// RunTimeDB runs `tasksh timedb import [--dry-run] [--uda NAME]` or
+// `tasksh timedb backfill [--dry-run]`
+func RunTimeDB(ctx context.Context, backend taskwarrior.TaskBackend, args []string, w io.Writer) error {
+	if len(args) > 0 && args[0] == "backfill" {
+		dryRun := false
+		for _, arg := range args[1:] {
+			if arg != "--dry-run" && arg != "-n" {
+				return fmt.Errorf("unexpected argument %q", arg)
+			}
+			dryRun = true
+		}
+		return BackfillTimeDB(ctx, backend, dryRun, w)
+	}
+	if len(args) == 0 || args[0] != "import" {
+		return errors.New(timeDBUsage)
+	}
+	opts := ImportOptions{UDA: defaultDurationUDA, StartAnnotation: defaultStartAnnotation, StopAnnotation: defaultStopAnnotation}
+	if rc, err := taskwarrior.LoadTaskrc(); err == nil {
+		if start, ok := rc.Get("journal.time.start.annotation"); ok {
+			opts.StartAnnotation = start
+		}
+		if stop, ok := rc.Get("journal.time.stop.annotation"); ok {
+			opts.StopAnnotation = stop
+		}
+	}
+	for i := 1; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "--dry-run" || arg == "-n":
+			opts.DryRun = true
+		case arg == "--uda":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--uda needs a UDA name")
+			}
+			i++
+			opts.UDA = args[i]
+		case strings.HasPrefix(arg, "--uda="):
+			opts.UDA = strings.TrimPrefix(arg, "--uda=")
+		default:
+			return fmt.Errorf("unexpected argument %q", arg)
+		}
+	}
+	return ImportCompletions(ctx, backend, opts, w)
+}
+
+// ImportCompletions bootstraps timedb from Taskwarrior's completion history.
+// Each completed task's time taken comes from the duration UDA, its start to
+// its end, or the start and stop annotations journal.time adds, and is
+// recorded with the task's real dates. Tasks already in timedb or without
+// any of these are skipped.
+func ImportCompletions(ctx context.Context, backend taskwarrior.TaskBackend, opts ImportOptions, w io.Writer) error {
+	tasks, err := completedTasks(ctx, backend)
+	if err != nil {
+		return err
+	}
+
+	db, err := timedb.New()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var added, recorded, untimed int
+	for _, td := range tasks {
+		taken, ok := timeTaken(td, opts)
+		if !ok {
+			untimed++
+			continue
+		}
+		entry, err := db.GetTimeEntry(td.UUID)
+		if err != nil {
+			return err
+		}
+		if entry != nil {
+			recorded++
+			continue
+		}
+
+		if opts.DryRun {
+			fmt.Fprintf(w, "  %-6s %s (completed %s)\n", timedb.FormatDuration(taken), td.Description, td.End.Local().Format("2006-01-02"))
+			added++
+			continue
+		}
+		// No estimate was made back then; 0 keeps the record out of the
+		// estimation accuracy statistics
+		if err := db.RecordCompletion(td.ToTask(), 0, taken.Hours()); err != nil {
+			return fmt.Errorf("failed to record %q: %w", td.Description, err)
+		}
+		added++
+	}
+
+	verb := "Added"
+	if opts.DryRun {
+		verb = "Would add"
+	}
+	fmt.Fprintf(w, "%s %d records; skipped %d already in timedb and %d without a %s UDA, start date or journal.time annotations.\n",
+		verb, added, recorded, untimed, opts.UDA)
+	return nil
+}
+
+// BackfillTimeDB records the time tracked in Timewarrior for each completed
+// task as its actual hours in timedb, or only counts it on a dry run
+func BackfillTimeDB(ctx context.Context, backend taskwarrior.TaskBackend, dryRun bool, w io.Writer) error {
+	data, err := completedTasks(ctx, backend)
+	if err != nil {
+		return err
+	}
+	tasks := make([]*taskwarrior.Task, len(data))
+	for i, td := range data {
+		tasks[i] = td.ToTask()
+	}
+	intervals, err := timewarrior.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load Timewarrior intervals: %w", err)
+	}
+
+	db, err := timedb.New()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	backfill, verb := timewarrior.Backfill, "Recorded"
+	if dryRun {
+		backfill, verb = timewarrior.PreviewBackfill, "Would record"
+	}
+	result, err := backfill(db, tasks, intervals)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "%s %d new and %d existing completions; skipped %d without tracked time.\n",
+		verb, result.Recorded, result.Updated, result.Skipped)
+	return nil
+}
+
+// completedTasks loads every completed task
+func completedTasks(ctx context.Context, backend taskwarrior.TaskBackend) ([]*taskwarrior.TaskData, error) {
+	uuids, err := backend.FilterUUIDs(ctx, filter.Attr("status").Eq("completed").Args())
+	if err != nil {
+		return nil, fmt.Errorf("failed to find completed tasks: %w", err)
+	}
+	tasks, err := backend.GetTasksWithDataProgress(ctx, uuids, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load completed tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+// timeTaken derives the time a completed task took from the duration UDA,
+// from its start date to its end date, or from its journal.time
+// annotations. `task done` removes the start date of an active task, so
+// tasks completed while started usually only have the annotations.
+func timeTaken(td *taskwarrior.TaskData, opts ImportOptions) (time.Duration, bool) {
+	if value := td.UDA[opts.UDA]; value != "" {
+		if d, err := timedb.ParseDuration(value); err == nil {
+			return d, true
+		}
+	}
+	if td.End.IsZero() {
+		return 0, false
+	}
+	if !td.Start.IsZero() {
+		span := td.End.Sub(td.Start.Time)
+		return span, span > 0 && span <= maxWorkSpan
+	}
+	taken := journalTime(td, opts.StartAnnotation, opts.StopAnnotation)
+	return taken, taken > 0
+}
+
+// journalTime sums the intervals between each start annotation and the stop
+// annotation after it. Intervals longer than maxWorkSpan are left out as
+// starts left running.
+func journalTime(td *taskwarrior.TaskData, start, stop string) time.Duration {
+	annotations := slices.Clone(td.Annotations)
+	slices.SortStableFunc(annotations, func(a, b taskwarrior.Annotation) int {
+		return a.Entry.Compare(b.Entry.Time)
+	})
+
+	var total time.Duration
+	var since time.Time
+	for _, ann := range annotations {
+		switch {
+		case ann.Description == start && since.IsZero():
+			since = ann.Entry.Time
+		case ann.Description == stop && !since.IsZero():
+			if span := ann.Entry.Sub(since); span > 0 && span <= maxWorkSpan {
+				total += span
+			}
+			since = time.Time{}
+		}
+	}
+	return total
+}
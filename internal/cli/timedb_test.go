@@ -0,0 +1,208 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emiller/tasksh/internal/taskwarrior"
+	"github.com/emiller/tasksh/internal/taskwarrior/memory"
+	"github.com/emiller/tasksh/internal/timedb"
+)
+
+func TestTimeDBImport(t *testing.T) {
+	t.Setenv("TASKSH_TIMEDB", filepath.Join(t.TempDir(), "timedb.sqlite3"))
+	backend := memory.New()
+	end := time.Date(2024, 3, 8, 17, 0, 0, 0, time.UTC)
+	completed := func(uuid, description string, start time.Time, uda map[string]string) {
+		td := &taskwarrior.TaskData{
+			UUID:        uuid,
+			Description: description,
+			Project:     "work",
+			Status:      "completed",
+			Entry:       taskwarrior.NewDate(end.AddDate(0, 0, -7)),
+			Start:       taskwarrior.NewDate(start),
+			End:         taskwarrior.NewDate(end),
+			UDA:         uda,
+		}
+		if err := backend.RestoreTask(t.Context(), td); err != nil {
+			t.Fatalf("Failed to add %s: %v", description, err)
+		}
+	}
+	completed("a0000000-0000-4000-8000-000000000001", "Write report", end.Add(-90*time.Minute), nil)
+	completed("a0000000-0000-4000-8000-000000000002", "Plan sprint", time.Time{}, map[string]string{"duration": "PT45M"})
+	completed("a0000000-0000-4000-8000-000000000003", "Left running", end.AddDate(0, 0, -3), nil)
+	completed("a0000000-0000-4000-8000-000000000004", "Untimed", time.Time{}, nil)
+	if _, err := backend.Add("Still open", "project:work"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := RunTimeDB(t.Context(), backend, []string{"import", "--dry-run"}, &out); err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+	for _, want := range []string{"1h30   Write report (completed 2024-03-08)", "45m    Plan sprint", "Would add 2 records; skipped 0 already in timedb and 2 without"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Dry run output missing %q:\n%s", want, out.String())
+		}
+	}
+	db, err := timedb.New()
+	if err != nil {
+		t.Fatalf("Failed to open timedb: %v", err)
+	}
+	defer db.Close()
+	if entry, _ := db.GetTimeEntry("a0000000-0000-4000-8000-000000000001"); entry != nil {
+		t.Fatalf("Dry run recorded %+v", entry)
+	}
+
+	out.Reset()
+	if err := RunTimeDB(t.Context(), backend, []string{"import"}, &out); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "Added 2 records; skipped 0 already in timedb and 2 without") {
+		t.Errorf("Unexpected import output:\n%s", out.String())
+	}
+	entry, err := db.GetTimeEntry("a0000000-0000-4000-8000-000000000001")
+	if err != nil || entry == nil {
+		t.Fatalf("Expected the report recorded, got %v", err)
+	}
+	if math.Abs(entry.ActualHours-1.5) > 0.001 || !entry.CompletedAt.Equal(end) {
+		t.Errorf("Expected 1.5h completed at %v, got %+v", end, entry)
+	}
+	if estimate, _, _ := db.EstimateTimeForTask(&taskwarrior.Task{Description: "Write report", Project: "work"}); estimate <= 0 {
+		t.Error("Expected imported history to inform estimates")
+	}
+
+	// Importing again adds nothing
+	out.Reset()
+	if err := RunTimeDB(t.Context(), backend, []string{"import", "--uda=duration"}, &out); err != nil {
+		t.Fatalf("Second import failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "Added 0 records; skipped 2 already in timedb") {
+		t.Errorf("Unexpected second import output:\n%s", out.String())
+	}
+
+	for _, args := range [][]string{nil, {"export"}, {"import", "--uda"}, {"import", "extra"}} {
+		if err := RunTimeDB(t.Context(), backend, args, &out); err == nil {
+			t.Errorf("Expected an error for %q", args)
+		}
+	}
+}
+
+func TestTimeTakenFromExport(t *testing.T) {
+	// `task export` of tasks completed while started: done removed start,
+	// leaving journal.time's annotations on the first
+	export := `[
+{"id":0,"description":"Review PR","end":"20240308T170000Z","entry":"20240301T090000Z","modified":"20240308T170000Z","project":"work","status":"completed","uuid":"b0000000-0000-4000-8000-000000000001","annotations":[{"entry":"20240308T153000Z","description":"Started task"},{"entry":"20240308T160000Z","description":"Waiting on CI"},{"entry":"20240308T161500Z","description":"Stopped task"},{"entry":"20240308T163000Z","description":"Started task"},{"entry":"20240308T170000Z","description":"Stopped task"}],"urgency":0},
+{"id":0,"description":"Fix bug","end":"20240308T170000Z","entry":"20240301T090000Z","modified":"20240308T170000Z","project":"work","status":"completed","uuid":"b0000000-0000-4000-8000-000000000002","urgency":0},
+{"id":0,"description":"Left running","end":"20240308T170000Z","entry":"20240301T090000Z","modified":"20240308T170000Z","status":"completed","uuid":"b0000000-0000-4000-8000-000000000003","annotations":[{"entry":"20240301T090000Z","description":"Started task"},{"entry":"20240308T170000Z","description":"Stopped task"}],"urgency":0}
+]`
+	var tasks []*taskwarrior.TaskData
+	if err := json.Unmarshal([]byte(export), &tasks); err != nil {
+		t.Fatalf("Failed to decode export: %v", err)
+	}
+
+	opts := ImportOptions{UDA: defaultDurationUDA, StartAnnotation: defaultStartAnnotation, StopAnnotation: defaultStopAnnotation}
+	want := []struct {
+		taken time.Duration
+		ok    bool
+	}{
+		{75 * time.Minute, true},
+		{0, false},
+		{0, false},
+	}
+	for i, td := range tasks {
+		if !td.Start.IsZero() {
+			t.Fatalf("%s: export should have no start date", td.Description)
+		}
+		taken, ok := timeTaken(td, opts)
+		if taken != want[i].taken || ok != want[i].ok {
+			t.Errorf("timeTaken(%s) = %v, %v, want %v, %v", td.Description, taken, ok, want[i].taken, want[i].ok)
+		}
+	}
+}
+
+func TestTimeDBBackfill(t *testing.T) {
+	t.Setenv("TASKSH_TIMEDB", filepath.Join(t.TempDir(), "timedb.sqlite3"))
+	timewDir := t.TempDir()
+	t.Setenv("TIMEWARRIORDB", timewDir)
+	if err := os.MkdirAll(filepath.Join(timewDir, "data"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	data := `inc 20240305T090000Z - 20240305T103000Z # b0000000-0000-4000-8000-000000000001
+inc 20240306T140000Z - 20240306T150000Z # "Plan sprint" work
+`
+	if err := os.WriteFile(filepath.Join(timewDir, "data", "2024-03.data"), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	backend := memory.New()
+	end := time.Date(2024, 3, 8, 17, 0, 0, 0, time.UTC)
+	for uuid, description := range map[string]string{
+		"b0000000-0000-4000-8000-000000000001": "Write report",
+		"b0000000-0000-4000-8000-000000000002": "Plan sprint",
+		"b0000000-0000-4000-8000-000000000003": "Untracked",
+	} {
+		td := &taskwarrior.TaskData{
+			UUID:        uuid,
+			Description: description,
+			Project:     "work",
+			Status:      "completed",
+			Entry:       taskwarrior.NewDate(end.AddDate(0, 0, -7)),
+			End:         taskwarrior.NewDate(end),
+		}
+		if err := backend.RestoreTask(t.Context(), td); err != nil {
+			t.Fatalf("Failed to add %s: %v", description, err)
+		}
+	}
+
+	db, err := timedb.New()
+	if err != nil {
+		t.Fatalf("Failed to open timedb: %v", err)
+	}
+	defer db.Close()
+	// Planning was completed without saying how long it took
+	if err := db.RecordCompletion(&taskwarrior.Task{UUID: "b0000000-0000-4000-8000-000000000002", Description: "Plan sprint", Project: "work"}, 2.0, 0); err != nil {
+		t.Fatalf("Failed to record completion: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := RunTimeDB(t.Context(), backend, []string{"backfill", "--dry-run"}, &out); err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "Would record 1 new and 1 existing completions; skipped 1 without tracked time.") {
+		t.Errorf("Unexpected dry run output:\n%s", out.String())
+	}
+	if entry, _ := db.GetTimeEntry("b0000000-0000-4000-8000-000000000001"); entry != nil {
+		t.Fatalf("Dry run recorded %+v", entry)
+	}
+
+	out.Reset()
+	if err := RunTimeDB(t.Context(), backend, []string{"backfill"}, &out); err != nil {
+		t.Fatalf("Backfill failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "Recorded 1 new and 1 existing completions; skipped 1 without tracked time.") {
+		t.Errorf("Unexpected backfill output:\n%s", out.String())
+	}
+	for uuid, hours := range map[string]float64{"b0000000-0000-4000-8000-000000000001": 1.5, "b0000000-0000-4000-8000-000000000002": 1} {
+		entry, err := db.GetTimeEntry(uuid)
+		if err != nil || entry == nil {
+			t.Fatalf("Expected a time entry for %s, got %v", uuid, err)
+		}
+		if math.Abs(entry.ActualHours-hours) > 0.001 {
+			t.Errorf("Expected %.2fh for %s, got %.2fh", hours, entry.Description, entry.ActualHours)
+		}
+	}
+	if entry, _ := db.GetTimeEntry("b0000000-0000-4000-8000-000000000002"); entry.EstimatedHours != 2.0 {
+		t.Errorf("Expected the recorded estimate kept, got %.2f", entry.EstimatedHours)
+	}
+
+	if err := RunTimeDB(t.Context(), backend, []string{"backfill", "extra"}, &out); err == nil {
+		t.Error("Expected an error for an unexpected argument")
+	}
+}
@@ -1,6 +1,8 @@
 package planning
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -10,6 +12,8 @@ import (
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/emiller/tasksh/internal/taskwarrior"
+	"github.com/emiller/tasksh/internal/timedb"
 )
 
 // PlanningMode represents the current mode of the planning interface
@@ -19,6 +23,7 @@ const (
 	ModeViewing PlanningMode = iota
 	ModeReordering
 	ModeEditing
+	ModeProfileSelect
 )
 
 // PlanningModel represents the state of the Bubble Tea planning interface
@@ -26,6 +31,13 @@ type PlanningModel struct {
 	// Planning session
 	session *PlanningSession
 
+	// Context for in-flight task calls; cancelled by Esc and Ctrl-C
+	baseCtx   context.Context
+	ctx       context.Context
+	cancel    context.CancelFunc
+	reloading bool
+	switching bool // A profile switch is loading; actions wait for it
+
 	// UI components
 	viewport viewport.Model
 	help     help.Model
@@ -43,7 +55,16 @@ type PlanningModel struct {
 
 	// Time projection settings
 	workStartTime time.Time
+
+	// Profile state
+	profiles        []taskwarrior.Profile
+	selectedProfile int
+	currentProfile  string
 	
+	// Time tracking state
+	tracking *timedb.Timer // Task being timed, if any
+	timerGen int           // Bumped on each start so stale ticks stop
+
 	// Performance optimization
 	contentWidthCache contentWidthCache
 }
@@ -71,6 +92,9 @@ type PlanningKeyMap struct {
 	Defer         key.Binding    // Defer to tomorrow
 	BrowseBacklog key.Binding    // Browse backlog tasks
 	Filter        key.Binding    // Filter tasks
+	Reload        key.Binding    // Reload tasks from the backend
+	Profile       key.Binding    // Switch Taskwarrior profile
+	Timer         key.Binding    // Start/stop timing the selected task
 
 	// General
 	Help key.Binding
@@ -148,6 +172,18 @@ func DefaultPlanningKeyMap() PlanningKeyMap {
 			key.WithKeys("f"),
 			key.WithHelp("f", "filter tasks"),
 		),
+		Reload: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "reload tasks"),
+		),
+		Profile: key.NewBinding(
+			key.WithKeys("P"),
+			key.WithHelp("P", "switch profile"),
+		),
+		Timer: key.NewBinding(
+			key.WithKeys("T"),
+			key.WithHelp("T", "start/stop timer"),
+		),
 		
 		Help: key.NewBinding(
 			key.WithKeys("?"),
@@ -170,14 +206,20 @@ func (k PlanningKeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Up, k.Down, k.JumpSection1, k.JumpSection2, k.JumpSection3},
 		{k.MoveUp, k.MoveDown, k.Remove},
-		{k.PromoteCritical, k.Defer, k.BrowseBacklog, k.Filter},
+		{k.PromoteCritical, k.Defer, k.BrowseBacklog, k.Filter, k.Timer},
 		{k.EditTime, k.Projection, k.ToggleView},
-		{k.Save, k.Help, k.Quit},
+		{k.Save, k.Reload, k.Profile, k.Help, k.Quit},
 	}
 }
 
 // NewPlanningModel creates a new planning model
 func NewPlanningModel(session *PlanningSession) *PlanningModel {
+	return NewPlanningModelWithContext(context.Background(), session)
+}
+
+// NewPlanningModelWithContext creates a new planning model whose task calls
+// are bound to ctx
+func NewPlanningModelWithContext(ctx context.Context, session *PlanningSession) *PlanningModel {
 	// Create viewport with minimal initial size - will be resized when WindowSizeMsg arrives
 	vp := viewport.New(1, 1)
 	vp.Style = lipgloss.NewStyle()
@@ -195,8 +237,12 @@ func NewPlanningModel(session *PlanningSession) *PlanningModel {
 	workStart := time.Now()
 	workStart = time.Date(workStart.Year(), workStart.Month(), workStart.Day(), 9, 0, 0, 0, workStart.Location())
 
+	opCtx, cancel := context.WithCancel(ctx)
 	model := &PlanningModel{
 		session:       session,
+		baseCtx:       ctx,
+		ctx:           opCtx,
+		cancel:        cancel,
 		viewport:      vp,
 		help:          h,
 		keys:          DefaultPlanningKeyMap(),
@@ -204,6 +250,7 @@ func NewPlanningModel(session *PlanningSession) *PlanningModel {
 		selectedTask:  0,
 		showProjection: true,
 		workStartTime: workStart,
+		currentProfile: taskwarrior.ActiveProfile(),
 		width:         80,  // Default width
 		height:        24,  // Default height
 	}
@@ -252,12 +299,91 @@ func (m *PlanningModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Update viewport content now that we have proper dimensions
 		m.updateViewport()
 
+	case tasksReloadedMsg:
+		m.reloading = false
+		if msg.err != nil {
+			if errors.Is(msg.err, context.Canceled) {
+				m.message = "Reload cancelled."
+			} else {
+				m.message = taskwarrior.FormatError(msg.err)
+			}
+			break
+		}
+		m.session = msg.session
+		if m.selectedTask >= len(m.session.Tasks) {
+			m.selectedTask = max(len(m.session.Tasks)-1, 0)
+		}
+		m.message = fmt.Sprintf("Reloaded %d tasks", len(m.session.Tasks))
+		m.updateViewport()
+
+	case profilesLoadedMsg:
+		if msg.err != nil {
+			m.message = taskwarrior.FormatError(msg.err)
+			break
+		}
+		m.profiles = msg.profiles
+		m.selectedProfile = 0
+		m.mode = ModeProfileSelect
+		m.message = "Select profile (↑↓: navigate, Enter: select, ESC: cancel)"
+
+	case profileChangedMsg:
+		m.reloading = false
+		m.switching = false
+		// The timer belongs to the old profile, so it ends with the switch
+		if msg.stopped != nil || msg.err == nil {
+			m.tracking = nil
+			m.timerGen++
+		}
+		if msg.err != nil {
+			if errors.Is(msg.err, context.Canceled) {
+				m.message = "Profile switch cancelled."
+			} else {
+				m.message = taskwarrior.FormatError(msg.err)
+			}
+			break
+		}
+		// The new session has its own time database
+		m.session.Close()
+		m.session = msg.session
+		m.currentProfile = msg.profile
+		m.selectedTask = 0
+		m.message = fmt.Sprintf("Profile switched to: %s (%d tasks)", msg.profile, len(m.session.Tasks))
+		if msg.stopped != nil {
+			m.message += fmt.Sprintf(". Stopped timing %q", msg.stopped.Description)
+		}
+		m.updateViewport()
+
+	case timerChangedMsg:
+		cmds = append(cmds, m.timerChanged(msg))
+		m.updateViewport()
+
+	case timerTickMsg:
+		// Each tick redraws the elapsed time in the capacity bar
+		if m.tracking != nil && msg.gen == m.timerGen {
+			return m, m.tickTimer()
+		}
+		return m, nil
+
 	case tea.KeyMsg:
+		if m.mode == ModeProfileSelect {
+			return m.updateProfileSelect(msg)
+		}
+		// Actions wait for a profile switch, which esc cancels
+		if m.switching && !key.Matches(msg, m.keys.Quit) && msg.String() != "esc" {
+			return m, nil
+		}
+
 		switch {
 		case key.Matches(msg, m.keys.Quit):
+			m.cancel()
 			m.quitting = true
 			return m, tea.Quit
 
+		case msg.String() == "esc":
+			if m.reloading {
+				m.cancelInFlight()
+			}
+
 		case key.Matches(msg, m.keys.Help):
 			m.help.ShowAll = !m.help.ShowAll
 
@@ -359,6 +485,21 @@ func (m *PlanningModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case key.Matches(msg, m.keys.Filter):
 			m.message = "Filter functionality coming soon! (f)"
 
+		case key.Matches(msg, m.keys.Timer):
+			cmds = append(cmds, m.toggleTimer())
+
+		case key.Matches(msg, m.keys.Reload):
+			if !m.reloading {
+				m.reloading = true
+				m.message = "Reloading tasks... (esc to cancel)"
+				return m, m.reloadTasks()
+			}
+
+		case key.Matches(msg, m.keys.Profile):
+			if !m.reloading {
+				return m, m.initProfileSelect()
+			}
+
 		case key.Matches(msg, m.keys.Save):
 			m.message = "Plan saved! (Note: Implementation needed to persist to taskwarrior)"
 			// TODO: Implement saving plan to taskwarrior (add 'planned' UDA)
@@ -393,8 +534,12 @@ func (m *PlanningModel) View() string {
 	capacityBar := m.renderCapacityBar()
 	sections = append(sections, capacityBar)
 
-	// Task list
-	sections = append(sections, m.viewport.View())
+	// Task list, or the profile picker in its place
+	if m.mode == ModeProfileSelect {
+		sections = append(sections, m.renderProfileSelect())
+	} else {
+		sections = append(sections, m.viewport.View())
+	}
 
 	// Message area
 	if m.message != "" {
@@ -428,6 +573,9 @@ func (m *PlanningModel) renderHeader() string {
 	case HorizonQuick:
 		title = "Quick Planning Mode"
 	}
+	if m.currentProfile != "" {
+		title += " [" + m.currentProfile + "]"
+	}
 
 	contentWidth := m.getContentWidth()
 	headerStyle := lipgloss.NewStyle().
@@ -472,7 +620,7 @@ func (m *PlanningModel) renderCapacityBar() string {
 	}
 
 	// Create capacity bar with icon - use consistent width
-	capacityText := icon + status
+	capacityText := icon + status + m.timerStatus(time.Now())
 	contentWidth := m.getContentWidth()
 	capacityBar := style.Width(contentWidth).Align(lipgloss.Center).Padding(0, 1).Render(capacityText)
 	
@@ -873,17 +1021,166 @@ func removeTaskFromSlice(tasks []PlannedTask, uuid string) []PlannedTask {
 	return tasks
 }
 
+// tasksReloadedMsg carries a freshly loaded session, or the load error
+type tasksReloadedMsg struct {
+	session *PlanningSession
+	err     error
+}
+
+// reloadTasks loads the plan again into a copy of the session, so the
+// current plan stays intact if the load fails or is cancelled
+func (m *PlanningModel) reloadTasks() tea.Cmd {
+	ctx := m.ctx
+	fresh := *m.session
+	return func() tea.Msg {
+		if err := fresh.LoadTasks(ctx); err != nil {
+			return tasksReloadedMsg{err: err}
+		}
+		return tasksReloadedMsg{session: &fresh}
+	}
+}
+
+// profilesLoadedMsg carries the profiles from the tasksh config
+type profilesLoadedMsg struct {
+	profiles []taskwarrior.Profile
+	err      error
+}
+
+// profileChangedMsg carries a session loaded from the new profile
+type profileChangedMsg struct {
+	profile string
+	session *PlanningSession
+	stopped *timedb.Timer // timer the switch stopped in the old profile
+	err     error
+}
+
+// initProfileSelect loads the profiles for the profile picker
+func (m *PlanningModel) initProfileSelect() tea.Cmd {
+	return func() tea.Msg {
+		profiles, _, err := taskwarrior.LoadProfiles()
+		return profilesLoadedMsg{profiles: profiles, err: err}
+	}
+}
+
+// updateProfileSelect handles profile picker input
+func (m *PlanningModel) updateProfileSelect(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case msg.String() == "esc":
+		m.mode = ModeViewing
+		m.message = ""
+
+	case msg.String() == "enter":
+		m.mode = ModeViewing
+		if m.selectedProfile < len(m.profiles) {
+			m.reloading = true
+			m.message = "Switching profile... (esc to cancel)"
+			return m, m.switchProfile(m.profiles[m.selectedProfile])
+		}
+
+	case key.Matches(msg, m.keys.Down):
+		if len(m.profiles) > 0 {
+			m.selectedProfile = (m.selectedProfile + 1) % len(m.profiles)
+		}
+
+	case key.Matches(msg, m.keys.Up):
+		if len(m.profiles) > 0 {
+			m.selectedProfile = (m.selectedProfile - 1 + len(m.profiles)) % len(m.profiles)
+		}
+
+	case key.Matches(msg, m.keys.Quit):
+		m.cancel()
+		m.quitting = true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// renderProfileSelect renders the profile picker
+func (m *PlanningModel) renderProfileSelect() string {
+	profileStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("6")). // ANSI cyan
+		Padding(1, 2).
+		Margin(1, 2)
+
+	var content strings.Builder
+	content.WriteString("Available Profiles:\n\n")
+	for i, profile := range m.profiles {
+		prefix := "  "
+		if i == m.selectedProfile {
+			prefix = "▶ "
+		}
+		line := prefix + profile.Name
+		if profile.Data != "" {
+			line += " - " + profile.Data
+		}
+		if profile.Name == m.currentProfile {
+			line += " (current)"
+		}
+		content.WriteString(line + "\n")
+	}
+	content.WriteString("\n↑↓: navigate  Enter: select  ESC: cancel")
+
+	return profileStyle.Render(content.String())
+}
+
+// switchProfile plans the same horizon from the profile's database through
+// a backend of its own. Task calls still running for the current profile are
+// cancelled, and actions wait until the switch is done. A running timer is
+// stopped and recorded in the current profile first.
+func (m *PlanningModel) switchProfile(profile taskwarrior.Profile) tea.Cmd {
+	m.cancelInFlight()
+	m.switching = true
+	ctx := m.ctx
+	previous, tracking := m.session, m.tracking
+	backend := taskwarrior.ForProfile(previous.backend, profile)
+	return func() tea.Msg {
+		if tracking != nil {
+			if _, err := timedb.StopTimer(ctx, previous.backend, previous.timeDB, tracking.UUID, tracking.Since, time.Now()); err != nil {
+				return profileChangedMsg{err: err}
+			}
+		}
+
+		timeDBPath, err := timedb.ResolvePath(profile.Getenv("TASKSH_TIMEDB"))
+		if err != nil {
+			return profileChangedMsg{err: err, stopped: tracking}
+		}
+		session, err := newPlanningSession(previous.Horizon, backend, timeDBPath)
+		if err != nil {
+			return profileChangedMsg{err: err, stopped: tracking}
+		}
+		if err := session.LoadTasks(ctx); err != nil {
+			session.Close()
+			return profileChangedMsg{err: err, stopped: tracking}
+		}
+		return profileChangedMsg{profile: profile.Name, session: session, stopped: tracking}
+	}
+}
+
+// cancelInFlight cancels running task calls and starts a fresh context for
+// the calls that follow
+func (m *PlanningModel) cancelInFlight() {
+	m.cancel()
+	m.ctx, m.cancel = context.WithCancel(m.baseCtx)
+}
+
 // Run starts the planning interface
 func Run(horizon PlanningHorizon) error {
+	return RunWithBackend(context.Background(), horizon, taskwarrior.NewExecBackend())
+}
+
+// RunWithBackend starts the planning interface using the given task backend.
+// Cancelling ctx stops any task call in progress.
+func RunWithBackend(ctx context.Context, horizon PlanningHorizon, backend taskwarrior.TaskBackend) error {
 	// Create planning session
-	session, err := NewPlanningSession(horizon)
+	session, err := NewPlanningSessionWithBackend(horizon, backend)
 	if err != nil {
 		return fmt.Errorf("failed to create planning session: %w", err)
 	}
-	defer session.Close()
 
 	// Load tasks
-	if err := session.LoadTasks(); err != nil {
+	if err := session.LoadTasks(ctx); err != nil {
+		session.Close()
 		return fmt.Errorf("failed to load tasks: %w", err)
 	}
 
@@ -899,15 +1196,19 @@ func Run(horizon PlanningHorizon) error {
 		case HorizonQuick:
 			horizonName = "quick planning"
 		}
+		session.Close()
 		fmt.Printf("\nNo tasks found for %s.\n\n", horizonName)
 		return nil
 	}
 
 	// Create and run Bubble Tea program with proper initialization options
-	model := NewPlanningModel(session)
+	model := NewPlanningModelWithContext(ctx, session)
 	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
 
-	if _, err := p.Run(); err != nil {
+	_, err = p.Run()
+	// A profile switch replaces the session, so close the one in use at the end
+	model.session.Close()
+	if err != nil {
 		return fmt.Errorf("failed to run planning interface: %w", err)
 	}
 
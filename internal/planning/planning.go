@@ -1,13 +1,15 @@
 package planning
 
 import (
+	"context"
 	"fmt"
-	"os/exec"
+	"slices"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/emiller/tasksh/internal/taskwarrior"
+	"github.com/emiller/tasksh/internal/taskwarrior/filter"
 	"github.com/emiller/tasksh/internal/timedb"
 )
 
@@ -51,6 +53,7 @@ type PlannedTask struct {
 	Category         TaskCategory // Critical/Important/Flexible categorization
 	EnergyLevel      EnergyLevel  // Cognitive energy required
 	OptimalTimeSlot  string       // Suggested time of day (e.g., "morning", "afternoon")
+	BlockedBy        []string     // UUIDs of unfinished tasks this task depends on
 }
 
 // PlanningSession represents a planning session
@@ -76,6 +79,8 @@ type PlanningSession struct {
 	BufferTime     float64 // Buffer percentage for interruptions
 	
 	timeDB         *timedb.TimeDB
+	backend        taskwarrior.TaskBackend
+	graph          *taskwarrior.DependencyGraph
 }
 
 // WarningLevel represents capacity warning levels
@@ -89,7 +94,18 @@ const (
 
 // NewPlanningSession creates a new planning session
 func NewPlanningSession(horizon PlanningHorizon) (*PlanningSession, error) {
-	timeDB, err := timedb.New()
+	return NewPlanningSessionWithBackend(horizon, taskwarrior.NewExecBackend())
+}
+
+// NewPlanningSessionWithBackend creates a new planning session using the given task backend
+func NewPlanningSessionWithBackend(horizon PlanningHorizon, backend taskwarrior.TaskBackend) (*PlanningSession, error) {
+	return newPlanningSession(horizon, backend, "")
+}
+
+// newPlanningSession creates a planning session with the time database at
+// timeDBPath, or the one tasksh started with when it is empty
+func newPlanningSession(horizon PlanningHorizon, backend taskwarrior.TaskBackend, timeDBPath string) (*PlanningSession, error) {
+	timeDB, err := timedb.Open(timeDBPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open time database: %w", err)
 	}
@@ -102,6 +118,7 @@ func NewPlanningSession(horizon PlanningHorizon) (*PlanningSession, error) {
 		MaxFocusHours: 6.0, // Maximum focused work hours
 		BufferTime:    0.25, // 25% buffer for interruptions
 		timeDB:        timeDB,
+		backend:       backend,
 	}
 
 	switch horizon {
@@ -129,19 +146,19 @@ func (ps *PlanningSession) Close() error {
 }
 
 // LoadTasks loads and processes tasks for the planning horizon
-func (ps *PlanningSession) LoadTasks() error {
+func (ps *PlanningSession) LoadTasks(ctx context.Context) error {
 	var uuids []string
 	var err error
 
 	switch ps.Horizon {
 	case HorizonToday:
-		uuids, err = ps.getTasksForToday()
+		uuids, err = ps.getTasksForToday(ctx)
 	case HorizonTomorrow:
-		uuids, err = ps.getTasksForTomorrow()
+		uuids, err = ps.getTasksForTomorrow(ctx)
 	case HorizonWeek:
-		uuids, err = ps.getTasksForWeek()
+		uuids, err = ps.getTasksForWeek(ctx)
 	case HorizonQuick:
-		uuids, err = ps.getTasksForQuick()
+		uuids, err = ps.getTasksForQuick(ctx)
 	}
 
 	if err != nil {
@@ -149,10 +166,16 @@ func (ps *PlanningSession) LoadTasks() error {
 	}
 
 	// Batch load all tasks for better performance
-	taskMap, err := taskwarrior.BatchLoadTasks(uuids)
+	taskData, err := ps.backend.GetTasksWithDataProgress(ctx, uuids, nil)
 	if err != nil {
 		return fmt.Errorf("failed to batch load tasks: %w", err)
 	}
+	ps.loadDependencies(ctx, taskData)
+
+	taskMap := make(map[string]*taskwarrior.Task, len(taskData))
+	for _, td := range taskData {
+		taskMap[td.UUID] = td.ToTask()
+	}
 
 	// Convert to PlannedTasks with metadata
 	allTasks := make([]PlannedTask, 0, len(uuids))
@@ -167,12 +190,12 @@ func (ps *PlanningSession) LoadTasks() error {
 			PlannedDate: ps.Date,
 		}
 
-		// Calculate urgency
-		plannedTask.Urgency = ps.calculateUrgency(task)
+		plannedTask.Urgency = task.Urgency
+		plannedTask.BlockedBy = ps.graph.OpenBlockers(uuid)
 
 		// Determine if scheduled or due
-		plannedTask.IsScheduled = task.Due != "" // Simplified - in real taskwarrior, there's a separate scheduled field
-		plannedTask.IsDue = task.Due != ""
+		plannedTask.IsScheduled = !task.Scheduled.IsZero()
+		plannedTask.IsDue = !task.Due.IsZero()
 
 		// Get time estimation
 		plannedTask.EstimatedHours, plannedTask.EstimationReason = ps.estimateTaskTime(task)
@@ -199,59 +222,64 @@ func (ps *PlanningSession) LoadTasks() error {
 	return nil
 }
 
+// activeTasks limits a planning query to tasks that are still open
+var activeTasks = filter.Or(filter.HasTag("PENDING"), filter.HasTag("WAITING"))
+
 // getTasksForToday gets tasks relevant for today's planning
-func (ps *PlanningSession) getTasksForToday() ([]string, error) {
+func (ps *PlanningSession) getTasksForToday(ctx context.Context) ([]string, error) {
 	todayStr := time.Now().Format("2006-01-02")
+	due := filter.Attr("due")
 
 	// Get tasks due today, overdue, or with high urgency
-	filters := []string{
-		fmt.Sprintf("(due:%s or due.before:%s or urgency.over:15.0)", todayStr, todayStr),
-		"and", "(+PENDING", "or", "+WAITING)",
-	}
+	query := filter.And(
+		filter.Or(due.Eq(todayStr), due.Before(todayStr), filter.Attr("urgency").Over("15.0")),
+		activeTasks,
+	)
 
-	return ps.executeTaskFilter(filters)
+	return ps.executeTaskFilter(ctx, query)
 }
 
 // getTasksForTomorrow gets tasks relevant for tomorrow's planning
-func (ps *PlanningSession) getTasksForTomorrow() ([]string, error) {
+func (ps *PlanningSession) getTasksForTomorrow(ctx context.Context) ([]string, error) {
 	tomorrow := time.Now().AddDate(0, 0, 1)
 	tomorrowStr := tomorrow.Format("2006-01-02")
 
 	// Get tasks due tomorrow, scheduled tomorrow, or with high urgency
-	filters := []string{
-		fmt.Sprintf("(due:%s or urgency.over:15.0)", tomorrowStr),
-		"and", "(+PENDING", "or", "+WAITING)",
-	}
+	query := filter.And(
+		filter.Or(filter.Attr("due").Eq(tomorrowStr), filter.Attr("urgency").Over("15.0")),
+		activeTasks,
+	)
 
-	return ps.executeTaskFilter(filters)
+	return ps.executeTaskFilter(ctx, query)
 }
 
 // getTasksForWeek gets tasks relevant for weekly planning
-func (ps *PlanningSession) getTasksForWeek() ([]string, error) {
+func (ps *PlanningSession) getTasksForWeek(ctx context.Context) ([]string, error) {
 	endOfWeek := time.Now().AddDate(0, 0, 7)
 	eowStr := endOfWeek.Format("2006-01-02")
 
 	// Get tasks due this week or with moderate urgency
-	filters := []string{
-		fmt.Sprintf("(due.before:%s or urgency.over:10.0)", eowStr),
-		"and", "(+PENDING", "or", "+WAITING)",
-	}
+	query := filter.And(
+		filter.Or(filter.Attr("due").Before(eowStr), filter.Attr("urgency").Over("10.0")),
+		activeTasks,
+	)
 
-	return ps.executeTaskFilter(filters)
+	return ps.executeTaskFilter(ctx, query)
 }
 
 // getTasksForQuick gets tasks for quick planning mode (only most critical)
-func (ps *PlanningSession) getTasksForQuick() ([]string, error) {
+func (ps *PlanningSession) getTasksForQuick(ctx context.Context) ([]string, error) {
 	tomorrow := time.Now().AddDate(0, 0, 1)
 	tomorrowStr := tomorrow.Format("2006-01-02")
+	due := filter.Attr("due")
 
 	// Get only the most critical tasks: due today/tomorrow or very high urgency
-	filters := []string{
-		fmt.Sprintf("(due:%s or due:today or urgency.over:25.0)", tomorrowStr),
-		"and", "(+PENDING", "or", "+WAITING)",
-	}
+	query := filter.And(
+		filter.Or(due.Eq(tomorrowStr), due.Eq("today"), filter.Attr("urgency").Over("25.0")),
+		activeTasks,
+	)
 
-	return ps.executeTaskFilter(filters)
+	return ps.executeTaskFilter(ctx, query)
 }
 
 // categorizeTask determines the category (Critical/Important/Flexible) for a task
@@ -270,7 +298,8 @@ func (ps *PlanningSession) categorizeTask(task PlannedTask) TaskCategory {
 	return CategoryFlexible
 }
 
-// sortTasksByPriority sorts tasks by urgency and due date
+// sortTasksByPriority sorts tasks by urgency and due date, then moves each
+// task after its unfinished blockers
 func (ps *PlanningSession) sortTasksByPriority(tasks []PlannedTask) {
 	sort.Slice(tasks, func(i, j int) bool {
 		taskA, taskB := tasks[i], tasks[j]
@@ -291,6 +320,54 @@ func (ps *PlanningSession) sortTasksByPriority(tasks []PlannedTask) {
 		// Finally by description for stable sort
 		return taskA.Description < taskB.Description
 	})
+
+	if ps.graph == nil {
+		return
+	}
+	byUUID := make(map[string]PlannedTask, len(tasks))
+	uuids := make([]string, len(tasks))
+	for i, task := range tasks {
+		byUUID[task.UUID] = task
+		uuids[i] = task.UUID
+	}
+	for i, uuid := range ps.graph.Order(uuids) {
+		tasks[i] = byUUID[uuid]
+	}
+
+	// A blocker is needed as soon as the task it blocks, so it takes the
+	// more pressing category; blockers come first, so walk back from the end
+	index := make(map[string]int, len(tasks))
+	for i, task := range tasks {
+		index[task.UUID] = i
+	}
+	for i := len(tasks) - 1; i >= 0; i-- {
+		for _, blocker := range tasks[i].BlockedBy {
+			if j, ok := index[blocker]; ok && tasks[i].Category < tasks[j].Category {
+				tasks[j].Category = tasks[i].Category
+			}
+		}
+	}
+}
+
+// loadDependencies loads the tasks' blockers so that no task is planned
+// ahead of them. Without them tasks are planned as if nothing blocks them.
+func (ps *PlanningSession) loadDependencies(ctx context.Context, tasks []*taskwarrior.TaskData) {
+	graph, err := taskwarrior.LoadDependencyGraph(ctx, ps.backend, tasks)
+	if err != nil {
+		graph = taskwarrior.NewDependencyGraph(tasks)
+	}
+	ps.graph = graph
+}
+
+// unblocked reports whether all of the task's unfinished blockers are
+// already in the plan
+func unblocked(task PlannedTask, planned map[string]bool) bool {
+	for _, blocker := range task.BlockedBy {
+		if !planned[blocker] {
+			return false
+		}
+	}
+	return true
 }
 
 // organizeTasks applies smart limits and organizes tasks into categories
@@ -304,11 +381,15 @@ func (ps *PlanningSession) organizeTasks(allTasks []PlannedTask) {
 	var totalHours float64
 	var taskCount int
 
+	// Tasks whose blockers didn't make the plan go to the backlog too
+	planned := make(map[string]bool)
+
 	// First pass: Add critical tasks (always include, but limit hours)
 	for _, task := range allTasks {
 		if task.Category == CategoryCritical {
-			if totalHours + task.EstimatedHours <= ps.MaxFocusHours || len(ps.CriticalTasks) < 3 {
+			if unblocked(task, planned) && (totalHours + task.EstimatedHours <= ps.MaxFocusHours || len(ps.CriticalTasks) < 3) {
 				ps.CriticalTasks = append(ps.CriticalTasks, task)
+				planned[task.UUID] = true
 				totalHours += task.EstimatedHours
 				taskCount++
 			} else {
@@ -320,8 +401,9 @@ func (ps *PlanningSession) organizeTasks(allTasks []PlannedTask) {
 	// Second pass: Add important tasks until we hit limits
 	for _, task := range allTasks {
 		if task.Category == CategoryImportant {
-			if taskCount < ps.MaxTasks && totalHours + task.EstimatedHours <= ps.MaxFocusHours {
+			if unblocked(task, planned) && taskCount < ps.MaxTasks && totalHours + task.EstimatedHours <= ps.MaxFocusHours {
 				ps.ImportantTasks = append(ps.ImportantTasks, task)
+				planned[task.UUID] = true
 				totalHours += task.EstimatedHours
 				taskCount++
 			} else {
@@ -333,8 +415,9 @@ func (ps *PlanningSession) organizeTasks(allTasks []PlannedTask) {
 	// Third pass: Add flexible tasks if there's still capacity
 	for _, task := range allTasks {
 		if task.Category == CategoryFlexible {
-			if taskCount < ps.MaxTasks && totalHours + task.EstimatedHours <= ps.MaxFocusHours {
+			if unblocked(task, planned) && taskCount < ps.MaxTasks && totalHours + task.EstimatedHours <= ps.MaxFocusHours {
 				ps.FlexibleTasks = append(ps.FlexibleTasks, task)
+				planned[task.UUID] = true
 				totalHours += task.EstimatedHours
 				taskCount++
 			} else {
@@ -409,75 +492,12 @@ func (ps *PlanningSession) getOptimalTimeSlot(task PlannedTask) string {
 }
 
 // executeTaskFilter executes a taskwarrior filter and returns UUIDs
-func (ps *PlanningSession) executeTaskFilter(filters []string) ([]string, error) {
-	// Use the existing taskwarrior package approach
-	args := append([]string{
-		"rc.color=off",
-		"rc.detection=off",
-		"rc._forcecolor=off",
-		"rc.verbose=nothing",
-	}, filters...)
-	args = append(args, "uuids")
-
-	output, err := executeTask(args...)
+func (ps *PlanningSession) executeTaskFilter(ctx context.Context, query filter.Expr) ([]string, error) {
+	uuids, err := ps.backend.FilterUUIDs(ctx, query.Args())
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute task filter: %w", err)
 	}
-
-	if output == "" {
-		return []string{}, nil
-	}
-
-	return strings.Fields(output), nil
-}
-
-// executeTask is a helper to run taskwarrior commands (copied from taskwarrior package pattern)
-func executeTask(args ...string) (string, error) {
-	cmd := exec.Command("task", args...)
-	output, err := cmd.Output()
-	if err != nil {
-		return "", fmt.Errorf("task command failed: %w", err)
-	}
-	return strings.TrimSpace(string(output)), nil
-}
-
-// calculateUrgency calculates urgency score for a task
-func (ps *PlanningSession) calculateUrgency(task *taskwarrior.Task) float64 {
-	// Simplified urgency calculation
-	// In real implementation, this would use taskwarrior's urgency calculation
-	urgency := 0.0
-
-	// Priority contribution
-	switch task.Priority {
-	case "H":
-		urgency += 6.0
-	case "M":
-		urgency += 1.8
-	case "L":
-		urgency += 0.0
-	}
-
-	// Due date contribution
-	if task.Due != "" {
-		// Parse due date and calculate urgency based on proximity
-		if dueTime, err := time.Parse("2006-01-02T15:04:05Z", task.Due); err == nil {
-			daysUntilDue := time.Until(dueTime).Hours() / 24
-			if daysUntilDue <= 1 {
-				urgency += 12.0
-			} else if daysUntilDue <= 7 {
-				urgency += 6.0
-			} else if daysUntilDue <= 30 {
-				urgency += 2.0
-			}
-		}
-	}
-
-	// Project contribution (projects are important)
-	if task.Project != "" {
-		urgency += 1.0
-	}
-
-	return urgency
+	return uuids, nil
 }
 
 // estimateTaskTime estimates time for a task using historical data
@@ -486,24 +506,7 @@ func (ps *PlanningSession) estimateTaskTime(task *taskwarrior.Task) (float64, st
 		return 2.0, "Default estimate (no historical data)"
 	}
 
-	estimate, reason, err := ps.timeDB.EstimateTimeForTask(task)
-	if err != nil || estimate <= 0 {
-		// Fallback estimates based on priority/complexity
-		switch task.Priority {
-		case "H":
-			return 3.0, "High priority task estimate"
-		case "M":
-			return 2.0, "Medium priority task estimate"
-		case "L":
-			return 1.0, "Low priority task estimate"
-		default:
-			return 2.0, "Default task estimate"
-		}
-	}
-
-	// Add 15% buffer to estimates
-	bufferedEstimate := estimate * 1.15
-	return bufferedEstimate, reason + " (with 15% buffer)"
+	return ps.timeDB.PlanningEstimate(task)
 }
 
 // sortTasks sorts tasks by planning priority
@@ -606,6 +609,17 @@ func (ps *PlanningSession) MoveTask(fromIndex, toIndex int) error {
 	if toIndex >= len(newTasks) {
 		newTasks = append(newTasks, task)
 	}
+
+	// Keep every task after its unfinished blockers
+	seen := make(map[string]bool, len(newTasks))
+	for _, t := range newTasks {
+		for _, blocker := range t.BlockedBy {
+			if !seen[blocker] && slices.ContainsFunc(newTasks, func(other PlannedTask) bool { return other.UUID == blocker }) {
+				return fmt.Errorf("%q must come after the task blocking it", t.GetShortDescription(40))
+			}
+		}
+		seen[t.UUID] = true
+	}
 	
 	ps.Tasks = newTasks
 	return nil
@@ -1,11 +1,15 @@
 package planning
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/emiller/tasksh/internal/taskwarrior"
+	"github.com/emiller/tasksh/internal/taskwarrior/memory"
 	_ "modernc.org/sqlite"
 )
 
@@ -31,57 +35,6 @@ func TestNewPlanningSession(t *testing.T) {
 	}
 }
 
-func TestCalculateUrgency(t *testing.T) {
-	session, err := NewPlanningSession(HorizonTomorrow)
-	if err != nil {
-		t.Fatalf("Failed to create planning session: %v", err)
-	}
-	defer session.Close()
-
-	tests := []struct {
-		name     string
-		task     *taskwarrior.Task
-		expected float64
-	}{
-		{
-			name: "high priority task",
-			task: &taskwarrior.Task{
-				Priority: "H",
-				Project:  "test",
-			},
-			expected: 7.0, // 6.0 + 1.0 for project
-		},
-		{
-			name: "medium priority task",
-			task: &taskwarrior.Task{
-				Priority: "M",
-			},
-			expected: 1.8,
-		},
-		{
-			name: "no priority task with project",
-			task: &taskwarrior.Task{
-				Project: "test",
-			},
-			expected: 1.0,
-		},
-		{
-			name: "basic task",
-			task: &taskwarrior.Task{},
-			expected: 0.0,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			urgency := session.calculateUrgency(tt.task)
-			if urgency != tt.expected {
-				t.Errorf("Expected urgency %v, got %v", tt.expected, urgency)
-			}
-		})
-	}
-}
-
 func TestMoveTask(t *testing.T) {
 	session, err := NewPlanningSession(HorizonTomorrow)
 	if err != nil {
@@ -303,4 +256,141 @@ func TestGetProjectedCompletionTimes(t *testing.T) {
 			t.Errorf("Expected completion time %d to be %v, got %v", i, expected, completionTimes[i])
 		}
 	}
-}
\ No newline at end of file
+}
+func TestSwitchProfile(t *testing.T) {
+	dir := t.TempDir()
+	timeDBPath := filepath.Join(dir, "work.sqlite3")
+	config := filepath.Join(dir, "config")
+	if err := os.WriteFile(config, []byte("profile.work.timedb="+timeDBPath+"\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	t.Setenv("TASKSH_CONFIG", config)
+	t.Setenv("HOME", dir)
+	for _, env := range []string{"TASKRC", "TASKDATA", "TASKSH_TIMEDB"} {
+		t.Setenv(env, "")
+	}
+
+	session, err := NewPlanningSessionWithBackend(HorizonToday, memory.New())
+	if err != nil {
+		t.Fatalf("Failed to create planning session: %v", err)
+	}
+	model := NewPlanningModel(session)
+	defer func() { model.session.Close() }()
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("P")})
+	model.Update(cmd())
+	if model.mode != ModeProfileSelect || !strings.Contains(model.renderProfileSelect(), "work") {
+		t.Fatalf("Expected profile picker listing work, got mode %v", model.mode)
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	_, cmd = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model.Update(cmd())
+	if model.currentProfile != "work" || model.session == session {
+		t.Fatalf("Expected a new session for the work profile, got %q: %s", model.currentProfile, model.message)
+	}
+	if _, err := os.Stat(timeDBPath); err != nil {
+		t.Errorf("Expected the work profile's time database to be opened: %v", err)
+	}
+	if os.Getenv("TASKSH_TIMEDB") != "" || taskwarrior.ActiveProfile() != "" {
+		t.Errorf("Expected the process environment untouched, got profile %q", taskwarrior.ActiveProfile())
+	}
+	if !strings.Contains(model.renderHeader(), "[work]") {
+		t.Errorf("Header should show the profile: %q", model.renderHeader())
+	}
+}
+
+func TestPlanDependencies(t *testing.T) {
+	backend := memory.New()
+	add := func(description string, mods ...string) string {
+		uuid, err := backend.Add(description, mods...)
+		if err != nil {
+			t.Fatalf("Failed to add %s: %v", description, err)
+		}
+		return uuid
+	}
+	ci := add("Fix CI", "due:today")
+	add("Ship release", "due:today", "priority:H", "depends:"+ci)
+	later := add("Later blocker")
+	add("Blocked elsewhere", "due:today", "depends:"+later)
+
+	session, err := NewPlanningSessionWithBackend(HorizonToday, backend)
+	if err != nil {
+		t.Fatalf("Failed to create planning session: %v", err)
+	}
+	defer session.Close()
+	if err := session.LoadTasks(t.Context()); err != nil {
+		t.Fatalf("Failed to load tasks: %v", err)
+	}
+
+	var planned []string
+	for _, task := range session.Tasks {
+		planned = append(planned, task.Description)
+	}
+	if strings.Join(planned, ", ") != "Fix CI, Ship release" {
+		t.Fatalf("Expected the blocker planned first, got %v", planned)
+	}
+	if len(session.BacklogTasks) != 1 || session.BacklogTasks[0].Description != "Blocked elsewhere" {
+		t.Errorf("Expected the task with an unplanned blocker in the backlog, got %v", session.BacklogTasks)
+	}
+
+	if err := session.MoveTask(1, 0); err == nil {
+		t.Error("Expected moving a task ahead of its blocker to fail")
+	}
+	if session.Tasks[0].Description != "Fix CI" {
+		t.Errorf("Refused move changed the plan: %s first", session.Tasks[0].Description)
+	}
+}
+
+func TestTimer(t *testing.T) {
+	t.Setenv("TASKSH_TIMEDB", filepath.Join(t.TempDir(), "timedb.sqlite3"))
+	// Tasks start half an hour before the real clock, so the interval is long
+	// enough to record
+	backend := memory.New()
+	backend.SetClock(func() time.Time { return time.Now().Add(-30 * time.Minute) })
+	uuid, err := backend.Add("Write report", "due:today")
+	if err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+
+	session, err := NewPlanningSessionWithBackend(HorizonToday, backend)
+	if err != nil {
+		t.Fatalf("Failed to create planning session: %v", err)
+	}
+	defer session.Close()
+	if err := session.LoadTasks(t.Context()); err != nil {
+		t.Fatalf("Failed to load tasks: %v", err)
+	}
+	model := NewPlanningModel(session)
+	model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	press := func() {
+		_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("T")})
+		model.Update(cmd())
+	}
+
+	press()
+	if model.tracking == nil || model.tracking.UUID != uuid {
+		t.Fatalf("Expected the task timed, got %+v: %s", model.tracking, model.message)
+	}
+	if session.Tasks[0].Start.IsZero() {
+		t.Error("Expected the planned task started")
+	}
+	if bar := model.renderCapacityBar(); !strings.Contains(bar, "⏱ 30:0") {
+		t.Errorf("Expected the elapsed time in the capacity bar: %q", bar)
+	}
+
+	press()
+	if model.tracking != nil {
+		t.Fatalf("Expected the timer stopped, got %+v", model.tracking)
+	}
+	if task, _ := backend.GetTaskInfo(t.Context(), uuid); !task.Start.IsZero() {
+		t.Error("Expected the task stopped")
+	}
+	sessions, err := session.timeDB.WorkSessions(uuid)
+	if err != nil || len(sessions) != 1 {
+		t.Fatalf("Expected one work session, got %v, %v", sessions, err)
+	}
+	if d := sessions[0].Duration(); d < 29*time.Minute || d > 31*time.Minute {
+		t.Errorf("Expected about 30 minutes worked, got %v", d)
+	}
+}
@@ -0,0 +1,79 @@
+package planning
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/emiller/tasksh/internal/taskwarrior"
+	"github.com/emiller/tasksh/internal/timedb"
+)
+
+type timerChangedMsg struct {
+	timedb.TimerChange
+	err error
+}
+
+type timerTickMsg struct {
+	gen int
+}
+
+// toggleTimer starts or stops the selected task, recording stopped
+// intervals in the session's time database
+func (m *PlanningModel) toggleTimer() tea.Cmd {
+	if m.selectedTask >= len(m.session.Tasks) {
+		return nil
+	}
+	task := m.session.Tasks[m.selectedTask].Task
+	ctx := m.ctx
+	session := m.session
+	previous := m.tracking
+	return func() tea.Msg {
+		change, err := timedb.ToggleTimer(ctx, session.backend, session.timeDB, task, previous)
+		return timerChangedMsg{TimerChange: change, err: err}
+	}
+}
+
+// timerChanged follows a started or stopped timer, keeping the planned
+// tasks' start dates in step
+func (m *PlanningModel) timerChanged(msg timerChangedMsg) tea.Cmd {
+	if msg.err != nil {
+		m.message = taskwarrior.FormatError(msg.err)
+		return nil
+	}
+	m.message = msg.Message
+	if m.tracking != nil && m.tracking.UUID == msg.Stopped {
+		m.tracking = nil
+	}
+	for _, task := range m.session.Tasks {
+		switch {
+		case task.UUID == msg.Stopped:
+			task.Start = taskwarrior.Date{}
+		case msg.Started != nil && task.UUID == msg.Started.UUID:
+			task.Start = taskwarrior.NewDate(msg.Started.Since)
+		}
+	}
+	if msg.Started == nil {
+		return nil
+	}
+	m.tracking = msg.Started
+	m.timerGen++
+	return m.tickTimer()
+}
+
+// tickTimer schedules the next redraw of the capacity bar timer
+func (m *PlanningModel) tickTimer() tea.Cmd {
+	gen := m.timerGen
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return timerTickMsg{gen: gen}
+	})
+}
+
+// timerStatus renders the running timer for the capacity bar
+func (m *PlanningModel) timerStatus(now time.Time) string {
+	if m.tracking == nil {
+		return ""
+	}
+	return fmt.Sprintf(" · ⏱ %s %s", timedb.FormatElapsed(now.Sub(m.tracking.Since)), m.tracking.Description)
+}
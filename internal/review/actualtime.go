@@ -0,0 +1,133 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/emiller/tasksh/internal/timedb"
+)
+
+type durationPrefillMsg struct {
+	uuid  string
+	spent time.Duration
+}
+
+// startCompletion asks how long the current task took before completing it.
+// The answer is prefilled with the work intervals tracked for the task plus
+// the running one, from its start date to its end or now.
+func (m *ReviewModel) startCompletion() tea.Cmd {
+	m.mode = ModeInputDuration
+	m.textInput.Placeholder = "e.g. 45m or 1h30 (optional)"
+	m.textInput.SetValue("")
+	m.textInput.Focus()
+	m.message = "How long did this take? (Enter: complete, empty to skip; ESC: cancel)"
+
+	task := m.currentTask
+	if task == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		var spent time.Duration
+		if db, err := m.openTimeDB(); err == nil {
+			spent, _ = db.TrackedTime(task.UUID)
+			db.Close()
+		}
+		if !task.Start.IsZero() {
+			end := time.Now()
+			if !task.End.IsZero() {
+				end = task.End.Time
+			}
+			spent += end.Sub(task.Start.Time)
+		}
+		return durationPrefillMsg{uuid: task.UUID, spent: spent}
+	}
+}
+
+// prefillDuration fills in the time spent unless something was typed already
+func (m *ReviewModel) prefillDuration(msg durationPrefillMsg) {
+	if m.mode != ModeInputDuration || m.currentTask == nil || m.currentTask.UUID != msg.uuid {
+		return
+	}
+	if m.textInput.Value() == "" && msg.spent >= time.Minute {
+		m.textInput.SetValue(timedb.FormatDuration(msg.spent))
+		m.textInput.CursorEnd()
+	}
+}
+
+// updateDurationInput handles the time spent prompt shown on completion
+func (m *ReviewModel) updateDurationInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg.Type {
+	case tea.KeyEnter:
+		value := strings.TrimSpace(m.textInput.Value())
+		if value == "" {
+			m.mode = ModeViewing
+			m.message = ""
+			return m, m.completeCurrentTask()
+		}
+		actual, err := timedb.ParseDuration(value)
+		if err != nil {
+			m.message = fmt.Sprintf("%v. How long did this take?", err)
+			return m, nil
+		}
+		m.mode = ModeViewing
+		m.message = ""
+		return m, m.completeCurrentTaskTook(actual)
+
+	case tea.KeyEscape:
+		m.mode = ModeViewing
+		m.message = ""
+		return m, nil
+	}
+
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
+
+// completeCurrentTaskTook completes the current task and records the time
+// it took in timedb. A failure to record doesn't undo the completion.
+func (m *ReviewModel) completeCurrentTaskTook(actual time.Duration) tea.Cmd {
+	ctx := m.ctx
+	uuid := m.tasks[m.current]
+	complete := m.completeCurrentTask()
+	return func() tea.Msg {
+		msg := complete()
+		done, ok := msg.(actionCompletedMsg)
+		if !ok {
+			return msg
+		}
+		// The task is done, so record it even if the review was cancelled
+		if err := m.recordActualTime(context.WithoutCancel(ctx), uuid, actual); err != nil {
+			done.message = fmt.Sprintf("Task completed, but its time was not recorded: %v", err)
+		} else {
+			done.message = fmt.Sprintf("Task completed in %s.", timedb.FormatDuration(actual))
+		}
+		return done
+	}
+}
+
+// recordActualTime stores the time a completed task took next to the
+// estimate planning gives it, so later estimates learn from it
+func (m *ReviewModel) recordActualTime(ctx context.Context, uuid string, actual time.Duration) error {
+	task, err := m.backend.GetTaskInfo(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	db, err := m.openTimeDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// Estimate from the history before this completion is part of it
+	estimate, _ := db.PlanningEstimate(task)
+	if err := db.RecordCompletion(task, estimate, actual.Hours()); err != nil {
+		return fmt.Errorf("failed to record completion time: %w", err)
+	}
+	return nil
+}
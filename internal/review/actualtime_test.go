@@ -0,0 +1,88 @@
+package review
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/emiller/tasksh/internal/taskwarrior/memory"
+	"github.com/emiller/tasksh/internal/timedb"
+)
+
+func TestCompleteRecordsActualTime(t *testing.T) {
+	t.Setenv("TASKSH_TIMEDB", filepath.Join(t.TempDir(), "timedb.sqlite3"))
+	started := time.Now().Add(-95 * time.Minute)
+	backend := memory.New()
+	uuid, err := backend.Add("Fix bike", "project:Home", "priority:H", "start:"+started.UTC().Format("20060102T150405Z"))
+	if err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	other, err := backend.Add("Call mum")
+	if err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+
+	model := NewReviewModelWithBackend(backend)
+	model.SetTasks([]string{uuid, other}, 2)
+	model.Update(tea.WindowSizeMsg{Width: 100, Height: 40})
+	model.Update(model.loadCurrentTask()())
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	model.Update(cmd())
+	if model.mode != ModeInputDuration {
+		t.Fatalf("Expected the duration prompt, got mode %v", model.mode)
+	}
+	if got := model.textInput.Value(); got != "1h35" {
+		t.Errorf("Expected the prompt prefilled from the start time, got %q", got)
+	}
+
+	// A bad answer keeps the prompt open
+	model.textInput.SetValue("a while")
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if model.mode != ModeInputDuration {
+		t.Fatalf("Expected to stay at the prompt, got mode %v", model.mode)
+	}
+
+	model.textInput.SetValue("1h30")
+	_, cmd = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model.Update(cmd())
+	if task, _ := backend.GetTaskInfo(t.Context(), uuid); task.Status != "completed" {
+		t.Fatalf("Expected the task completed, got %s", task.Status)
+	}
+	if model.message != "Task completed in 1h30." {
+		t.Errorf("Unexpected message: %q", model.message)
+	}
+
+	db, err := timedb.New()
+	if err != nil {
+		t.Fatalf("Failed to open timedb: %v", err)
+	}
+	defer db.Close()
+	task, _ := backend.GetTaskInfo(t.Context(), uuid)
+	entries, err := db.GetSimilarTasks(task, 5)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("Expected one time entry, got %v, %v", entries, err)
+	}
+	// With no history, planning guesses 3h for a high priority task
+	if entries[0].ActualHours != 1.5 || entries[0].EstimatedHours != 3.0 {
+		t.Errorf("Expected 1.5h actual against a 3h estimate, got %+v", entries[0])
+	}
+
+	// Skipping the answer completes without recording
+	model.Update(model.loadCurrentTask()())
+	_, cmd = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	model.Update(cmd())
+	if model.textInput.Value() != "" {
+		t.Errorf("Expected no prefill for a task never started, got %q", model.textInput.Value())
+	}
+	_, cmd = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model.Update(cmd())
+	if task, _ := backend.GetTaskInfo(t.Context(), other); task.Status != "completed" {
+		t.Errorf("Expected the second task completed, got %s", task.Status)
+	}
+	if stats, _ := db.GetEstimationAccuracy(); stats["total_tasks"] != 1 {
+		t.Errorf("Expected no new time entry, got %v", stats["total_tasks"])
+	}
+}
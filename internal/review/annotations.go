@@ -0,0 +1,237 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// annotationLinkPattern finds a URL or a file path in an annotation
+var annotationLinkPattern = regexp.MustCompile(`(?:https?|file)://\S+|(?:^|\s)(~?/\S+|\./\S+)`)
+
+// annotationLink returns the first URL or file path in an annotation, or ""
+func annotationLink(text string) string {
+	m := annotationLinkPattern.FindStringSubmatch(text)
+	switch {
+	case m == nil:
+		return ""
+	case m[1] != "":
+		return m[1]
+	default:
+		return strings.TrimSpace(m[0])
+	}
+}
+
+// openCommand returns the command that opens a URL or file: TASKSH_OPEN if
+// set, otherwise the platform's opener
+func openCommand(target string) *exec.Cmd {
+	if rest, ok := strings.CutPrefix(target, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			target = filepath.Join(home, rest)
+		}
+	}
+	if opener := strings.Fields(os.Getenv("TASKSH_OPEN")); len(opener) > 0 {
+		return exec.Command(opener[0], append(opener[1:], target)...)
+	}
+	if runtime.GOOS == "darwin" {
+		return exec.Command("open", target)
+	}
+	return exec.Command("xdg-open", target)
+}
+
+type annotationsChangedMsg struct {
+	message string
+}
+
+type annotationOpenedMsg struct {
+	target string
+}
+
+// enterAnnotations opens the annotation panel for the current task
+func (m *ReviewModel) enterAnnotations() {
+	if m.currentTask == nil {
+		return
+	}
+	m.mode = ModeAnnotations
+	m.selectedAnnotation = 0
+	m.message = ""
+}
+
+// updateAnnotations handles input in the annotation panel
+func (m *ReviewModel) updateAnnotations(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	annotations := m.currentTask.Annotations
+	switch {
+	case key.Matches(msg, m.keys.NextTask):
+		if m.selectedAnnotation < len(annotations)-1 {
+			m.selectedAnnotation++
+		}
+
+	case key.Matches(msg, m.keys.PrevTask):
+		if m.selectedAnnotation > 0 {
+			m.selectedAnnotation--
+		}
+
+	case key.Matches(msg, m.keys.AddAnnotation):
+		m.startAnnotationInput(-1, "")
+
+	case key.Matches(msg, m.keys.EditAnnotation):
+		if len(annotations) > 0 {
+			m.startAnnotationInput(m.selectedAnnotation, annotations[m.selectedAnnotation].Description)
+		}
+
+	case key.Matches(msg, m.keys.DeleteAnnotation):
+		if len(annotations) > 0 {
+			text := annotations[m.selectedAnnotation].Description
+			return m, m.changeAnnotations("Remove annotation", "Annotation removed.", func(ctx context.Context, uuid string) error {
+				return m.backend.DenotateTask(ctx, uuid, text)
+			})
+		}
+
+	case key.Matches(msg, m.keys.OpenAnnotation):
+		if len(annotations) > 0 {
+			return m, m.openAnnotation(annotations[m.selectedAnnotation].Description)
+		}
+
+	case key.Matches(msg, m.keys.Annotations) || key.Matches(msg, m.keys.Cancel):
+		m.mode = ModeViewing
+		m.message = ""
+	}
+	return m, nil
+}
+
+// startAnnotationInput prompts for a new annotation, or a replacement for
+// the annotation at index
+func (m *ReviewModel) startAnnotationInput(index int, text string) {
+	m.editingAnnotation = index
+	m.mode = ModeInputAnnotation
+	m.textInput.Placeholder = "Annotation text"
+	m.textInput.SetValue(text)
+	m.textInput.CursorEnd()
+	m.textInput.Focus()
+	if index < 0 {
+		m.message = "Add annotation:"
+	} else {
+		m.message = "Edit annotation:"
+	}
+}
+
+// updateAnnotationInput handles the add and edit annotation input
+func (m *ReviewModel) updateAnnotationInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg.Type {
+	case tea.KeyEnter:
+		text := strings.TrimSpace(m.textInput.Value())
+		m.mode = ModeAnnotations
+		m.message = ""
+		if text == "" {
+			return m, nil
+		}
+		if m.editingAnnotation < 0 {
+			return m, m.changeAnnotations("Annotate", "Annotation added.", func(ctx context.Context, uuid string) error {
+				return m.backend.AnnotateTask(ctx, uuid, text)
+			})
+		}
+		old := m.currentTask.Annotations[m.editingAnnotation].Description
+		if text == old {
+			return m, nil
+		}
+		// Taskwarrior can't edit an annotation in place
+		return m, m.changeAnnotations("Edit annotation", "Annotation updated.", func(ctx context.Context, uuid string) error {
+			if err := m.backend.DenotateTask(ctx, uuid, old); err != nil {
+				return err
+			}
+			return m.backend.AnnotateTask(ctx, uuid, text)
+		})
+
+	case tea.KeyEscape:
+		m.mode = ModeAnnotations
+		m.message = ""
+		return m, nil
+	}
+
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
+
+// changeAnnotations runs an annotation change on the current task,
+// journaling it for undo, and reloads the task. Unlike other actions it
+// stays on the task and doesn't count as reviewing it.
+func (m *ReviewModel) changeAnnotations(verb, message string, action func(ctx context.Context, uuid string) error) tea.Cmd {
+	ctx := m.ctx
+	uuid := m.currentTask.UUID
+	return func() tea.Msg {
+		if err := m.journal.record(ctx, m.backend, verb, false, []string{uuid}, func() error {
+			return action(ctx, uuid)
+		}); err != nil {
+			return errorMsg{err}
+		}
+		return annotationsChangedMsg{message: message}
+	}
+}
+
+// reloadCurrentTask fetches the current task again, replacing the cached copy
+func (m *ReviewModel) reloadCurrentTask() tea.Cmd {
+	if m.taskCache != nil && m.current < len(m.tasks) {
+		delete(m.taskCache, m.tasks[m.current])
+	}
+	return m.loadCurrentTask()
+}
+
+// openAnnotation opens the URL or file path in an annotation, handing the
+// terminal over as editing does
+func (m *ReviewModel) openAnnotation(text string) tea.Cmd {
+	target := annotationLink(text)
+	if target == "" {
+		m.message = "This annotation has no link or file path to open."
+		return nil
+	}
+	return tea.ExecProcess(openCommand(target), func(err error) tea.Msg {
+		if err != nil {
+			return errorMsg{fmt.Errorf("failed to open %s: %w", target, err)}
+		}
+		return annotationOpenedMsg{target: target}
+	})
+}
+
+// renderAnnotations renders the annotation panel
+func (m *ReviewModel) renderAnnotations() string {
+	panelStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("4")). // ANSI blue
+		Padding(1, 2).
+		Margin(2, 4)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("6")).Bold(true)
+
+	var content strings.Builder
+	content.WriteString(lipgloss.NewStyle().Bold(true).Render("Annotations"))
+	content.WriteString(dimStyle.Render(" " + m.currentTask.GetShortDescription(40)))
+	content.WriteString("\n\n")
+
+	annotations := m.currentTask.Annotations
+	if len(annotations) == 0 {
+		content.WriteString("No annotations yet.\n")
+	}
+	for i, ann := range annotations {
+		line := fmt.Sprintf("%s  %s", ann.Entry.LocalString(), ann.Description)
+		if i == m.selectedAnnotation {
+			content.WriteString(selectedStyle.Render("> " + line))
+		} else {
+			content.WriteString("  " + line)
+		}
+		content.WriteString("\n")
+	}
+
+	content.WriteString("\na: add  e: edit  d: delete  o: open link  j/k: select  A/ESC: close")
+	return panelStyle.Render(content.String())
+}
@@ -0,0 +1,118 @@
+package review
+
+import (
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/emiller/tasksh/internal/taskwarrior/memory"
+)
+
+func TestAnnotationLink(t *testing.T) {
+	tests := map[string]string{
+		"See https://example.com/spec?id=4 for details": "https://example.com/spec?id=4",
+		"Draft in ~/notes/plan.md":                      "~/notes/plan.md",
+		"/tmp/report.pdf":                               "/tmp/report.pdf",
+		"Logs at ./build/out.log":                       "./build/out.log",
+		"file:///srv/share/minutes.txt":                 "file:///srv/share/minutes.txt",
+		"Call back and/or email":                        "",
+		"Wait reason: vendor":                           "",
+	}
+	for text, want := range tests {
+		if got := annotationLink(text); got != want {
+			t.Errorf("annotationLink(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestOpenCommand(t *testing.T) {
+	t.Setenv("TASKSH_OPEN", "firefox --new-tab")
+	cmd := openCommand("https://example.com")
+	if want := []string{"firefox", "--new-tab", "https://example.com"}; !slices.Equal(cmd.Args, want) {
+		t.Errorf("openCommand args = %v, want %v", cmd.Args, want)
+	}
+}
+
+func TestAnnotationMode(t *testing.T) {
+	backend := memory.New()
+	uuid, _ := backend.Add("Read paper")
+	if err := backend.AnnotateTask(t.Context(), uuid, "Skim section 2"); err != nil {
+		t.Fatalf("Failed to annotate: %v", err)
+	}
+
+	model := NewReviewModelWithBackend(backend)
+	model.SetTasks([]string{uuid}, 1)
+	model.Update(model.loadCurrentTask()())
+
+	// Run a key through the model, following the commands it returns
+	var run func(cmd tea.Cmd)
+	run = func(cmd tea.Cmd) {
+		if cmd == nil {
+			return
+		}
+		msg := cmd()
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			for _, cmd := range batch {
+				run(cmd)
+			}
+			return
+		}
+		_, next := model.Update(msg)
+		run(next)
+	}
+	press := func(keys string) {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(keys)}
+		if keys == "enter" {
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		}
+		_, cmd := model.Update(msg)
+		run(cmd)
+	}
+	annotations := func() []string {
+		var texts []string
+		for _, ann := range model.currentTask.Annotations {
+			texts = append(texts, ann.Description)
+		}
+		return texts
+	}
+
+	press("A")
+	if model.mode != ModeAnnotations {
+		t.Fatalf("Expected annotation mode, got %v", model.mode)
+	}
+
+	press("a")
+	model.textInput.SetValue("Notes in ~/papers/notes.md")
+	press("enter")
+	if got := annotations(); !slices.Equal(got, []string{"Skim section 2", "Notes in ~/papers/notes.md"}) {
+		t.Fatalf("After adding: %v", got)
+	}
+	if model.mode != ModeAnnotations || model.current != 0 || model.reviewed != 0 {
+		t.Errorf("Annotating should stay on the task, mode %v at %d with %d reviewed", model.mode, model.current, model.reviewed)
+	}
+
+	press("e")
+	model.textInput.SetValue("Read section 2")
+	press("enter")
+	if got := annotations(); !slices.Equal(got, []string{"Notes in ~/papers/notes.md", "Read section 2"}) {
+		t.Fatalf("After editing: %v", got)
+	}
+
+	press("j")
+	press("d")
+	if got := annotations(); !slices.Equal(got, []string{"Notes in ~/papers/notes.md"}) {
+		t.Fatalf("After deleting: %v", got)
+	}
+	if model.selectedAnnotation != 0 {
+		t.Errorf("Selection should move back onto the remaining annotation, got %d", model.selectedAnnotation)
+	}
+
+	// Annotation changes undo like any other action
+	msg := model.undoLastAction()()
+	model.Update(msg)
+	model.Update(model.loadCurrentTask()())
+	if got := annotations(); len(got) != 2 {
+		t.Errorf("After undo: %v", got)
+	}
+}
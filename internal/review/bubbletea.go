@@ -1,9 +1,13 @@
 package review
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -20,7 +24,9 @@ import (
 
 	"github.com/emiller/tasksh/internal/ai"
 	"github.com/emiller/tasksh/internal/taskwarrior"
+	"github.com/emiller/tasksh/internal/taskwarrior/filter"
 	"github.com/emiller/tasksh/internal/timedb"
+	"github.com/emiller/tasksh/internal/timewarrior"
 )
 
 // ReviewMode represents the current mode of the review interface
@@ -43,15 +49,34 @@ const (
 	ModeAILoading
 	ModePromptAgent
 	ModePromptPreview
+	ModeHistory
+	ModeProfileSelect
+	ModeRecurScope
+	ModeAnnotations
+	ModeInputAnnotation
+	ModeInputWaitingOn
+	ModeMarking
+	ModeBulkConfirm
+	ModeInputDuration
 )
 
 // ReviewModel represents the state of the Bubble Tea review interface
 type ReviewModel struct {
+	// Task backend used for all task operations
+	backend taskwarrior.TaskBackend
+
+	// Context for in-flight task calls; cancelled by Esc and Ctrl-C
+	baseCtx context.Context
+	ctx     context.Context
+	cancel  context.CancelFunc
+
 	// Task review state
 	tasks       []string // UUIDs of tasks to review
 	current     int      // Current task index
 	currentTask *taskwarrior.Task    // Current task details
 	taskCache   map[string]*taskwarrior.Task // Pre-loaded task data
+	deps        *taskDependencies            // Current task's blockers and blocked tasks
+	recur       *taskRecurrence              // Current task's recurring series
 	total       int      // Total tasks to review
 	reviewed    int      // Number reviewed
 	
@@ -81,20 +106,36 @@ type ReviewModel struct {
 
 	// For confirmations and input
 	pendingAction string
+	scoped        *scopedAction // Action waiting for this instance / all future
 	message       string
 	waitDate      string
 	waitReason    string
 	
 	// Completion state
 	selectedSuggestion int
+
+	// Annotation state
+	selectedAnnotation int
+	editingAnnotation  int // index being edited, -1 when adding
 	
 	// Celebration state
 	celebrationStart time.Time
+	celebrationDone  bool // The confetti has settled; the summary stays until quit
 	
 	// Context state
 	contexts          []string
 	selectedContext   int
 	currentContext    string
+
+	// Profile state
+	profiles        []taskwarrior.Profile
+	selectedProfile int
+	currentProfile  string
+	switching       bool   // A profile switch is loading; actions wait for it
+	timeDBPath      string // Time database of the profile switched to; empty for the one tasksh started with
+
+	// Review queue the tasks came from
+	queue taskwarrior.ReviewQueue
 	
 	// AI analysis state
 	aiAnalyzer      *ai.Analyzer
@@ -106,6 +147,29 @@ type ReviewModel struct {
 	promptSpinner    spinner.Model
 	generatedCommands []string
 	commandPreview   string
+
+	// Bulk action state
+	marked     map[string]bool         // UUIDs marked for a bulk action
+	markList   []*taskwarrior.TaskData // Tasks listed while marking
+	markCursor int
+	bulk       *bulkAction // Action on the marked tasks being prompted for or confirmed
+
+	// Undo journal for this session
+	journal journal
+
+	// Saved session state, for resuming an unfinished review
+	skipped     []string               // Tasks skipped and not reviewed since
+	pastActions []timedb.SessionAction // Actions taken before the review was resumed
+	elapsed     time.Duration          // Time spent before the review was resumed
+	started     time.Time
+	resumed     tea.Cmd // Run on start to show the progress a resumed review restored
+
+	// Where the session report is written when the review ends, if anywhere
+	reportPath string
+
+	// Time tracking state
+	tracking *timedb.Timer // Task being timed, if any
+	timerGen int           // Bumped on each start so stale ticks stop
 }
 
 // KeyMap defines the key bindings for the review interface
@@ -113,9 +177,11 @@ type KeyMap struct {
 	// Navigation
 	NextTask key.Binding
 	PrevTask key.Binding
+	JumpDependency key.Binding
 	
 	// Actions
 	Review   key.Binding
+	ReviewSeries key.Binding
 	Edit     key.Binding
 	Modify   key.Binding
 	Complete key.Binding
@@ -124,9 +190,16 @@ type KeyMap struct {
 	Due      key.Binding
 	Skip     key.Binding
 	Context  key.Binding
+	Profile  key.Binding
 	AIAnalysis key.Binding
 	PromptAgent key.Binding
 	Undo     key.Binding
+	History  key.Binding
+	Annotations key.Binding
+	Mark        key.Binding
+	ToggleMark  key.Binding
+	Report      key.Binding
+	Timer       key.Binding
 	
 	// General
 	Help key.Binding
@@ -138,6 +211,16 @@ type KeyMap struct {
 	// Confirmations
 	Confirm key.Binding
 	Cancel  key.Binding
+
+	// Recurring task scope
+	ThisInstance key.Binding
+	AllFuture    key.Binding
+
+	// Annotation panel
+	AddAnnotation    key.Binding
+	EditAnnotation   key.Binding
+	DeleteAnnotation key.Binding
+	OpenAnnotation   key.Binding
 }
 
 // DefaultKeyMap returns the default key bindings
@@ -152,12 +235,20 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("k", "up"),
 			key.WithHelp("k/↑", "previous task"),
 		),
+		JumpDependency: key.NewBinding(
+			key.WithKeys("1", "2", "3", "4", "5", "6", "7", "8", "9"),
+			key.WithHelp("1-9", "jump to dependency"),
+		),
 		
 		// Actions
 		Review: key.NewBinding(
 			key.WithKeys("r"),
 			key.WithHelp("r", "mark reviewed"),
 		),
+		ReviewSeries: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "review recurring series"),
+		),
 		Edit: key.NewBinding(
 			key.WithKeys("e"),
 			key.WithHelp("e", "edit task"),
@@ -190,6 +281,10 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("x"),
 			key.WithHelp("x", "switch context"),
 		),
+		Profile: key.NewBinding(
+			key.WithKeys("P"),
+			key.WithHelp("P", "switch profile"),
+		),
 		AIAnalysis: key.NewBinding(
 			key.WithKeys("a"),
 			key.WithHelp("a", "AI analysis"),
@@ -202,6 +297,30 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("z"),
 			key.WithHelp("z", "undo last action"),
 		),
+		History: key.NewBinding(
+			key.WithKeys("h"),
+			key.WithHelp("h", "undo history"),
+		),
+		Annotations: key.NewBinding(
+			key.WithKeys("A"),
+			key.WithHelp("A", "annotations"),
+		),
+		Mark: key.NewBinding(
+			key.WithKeys("V"),
+			key.WithHelp("V", "mark tasks for bulk action"),
+		),
+		ToggleMark: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp("space", "mark/unmark task"),
+		),
+		Timer: key.NewBinding(
+			key.WithKeys("T"),
+			key.WithHelp("T", "start/stop timer"),
+		),
+		Report: key.NewBinding(
+			key.WithKeys("E"),
+			key.WithHelp("E", "export session report (when done)"),
+		),
 		
 		// General
 		Help: key.NewBinding(
@@ -228,6 +347,34 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("n", "esc"),
 			key.WithHelp("n/esc", "cancel"),
 		),
+
+		// Recurring task scope
+		ThisInstance: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "this instance"),
+		),
+		AllFuture: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "all future"),
+		),
+
+		// Annotation panel
+		AddAnnotation: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "add annotation"),
+		),
+		EditAnnotation: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "edit annotation"),
+		),
+		DeleteAnnotation: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "delete annotation"),
+		),
+		OpenAnnotation: key.NewBinding(
+			key.WithKeys("o"),
+			key.WithHelp("o", "open link"),
+		),
 	}
 }
 
@@ -239,10 +386,10 @@ func (k KeyMap) ShortHelp() []key.Binding {
 // FullHelp returns the full help text
 func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{k.NextTask, k.PrevTask},
-		{k.Review, k.Edit, k.Modify},
-		{k.Complete, k.Delete, k.Wait, k.Due, k.Skip},
-		{k.Context, k.AIAnalysis, k.PromptAgent, k.Undo, k.Help, k.Quit},
+		{k.NextTask, k.PrevTask, k.JumpDependency},
+		{k.Review, k.ReviewSeries, k.Edit, k.Modify, k.Annotations, k.Mark},
+		{k.Complete, k.Delete, k.Wait, k.Due, k.Skip, k.Timer},
+		{k.Context, k.Profile, k.AIAnalysis, k.PromptAgent, k.Undo, k.History, k.Report, k.Help, k.Quit},
 	}
 }
 
@@ -264,22 +411,33 @@ func (c ConditionalKeyMap) ShortHelp() []key.Binding {
 
 // FullHelp returns the full help text with conditional AI features
 func (c ConditionalKeyMap) FullHelp() [][]key.Binding {
-	lastRow := []key.Binding{c.keyMap.Context}
+	lastRow := []key.Binding{c.keyMap.Context, c.keyMap.Profile}
 	if c.aiAvailable {
 		lastRow = append(lastRow, c.keyMap.AIAnalysis, c.keyMap.PromptAgent)
 	}
-	lastRow = append(lastRow, c.keyMap.Undo, c.keyMap.Help, c.keyMap.Quit)
+	lastRow = append(lastRow, c.keyMap.Undo, c.keyMap.History, c.keyMap.Report, c.keyMap.Help, c.keyMap.Quit)
 	
 	return [][]key.Binding{
-		{c.keyMap.NextTask, c.keyMap.PrevTask},
-		{c.keyMap.Review, c.keyMap.Edit, c.keyMap.Modify},
-		{c.keyMap.Complete, c.keyMap.Delete, c.keyMap.Wait, c.keyMap.Due, c.keyMap.Skip},
+		{c.keyMap.NextTask, c.keyMap.PrevTask, c.keyMap.JumpDependency},
+		{c.keyMap.Review, c.keyMap.ReviewSeries, c.keyMap.Edit, c.keyMap.Modify, c.keyMap.Annotations, c.keyMap.Mark},
+		{c.keyMap.Complete, c.keyMap.Delete, c.keyMap.Wait, c.keyMap.Due, c.keyMap.Skip, c.keyMap.Timer},
 		lastRow,
 	}
 }
 
 // NewReviewModel creates a new review model
 func NewReviewModel() *ReviewModel {
+	return NewReviewModelWithBackend(taskwarrior.NewExecBackend())
+}
+
+// NewReviewModelWithBackend creates a new review model using the given task backend
+func NewReviewModelWithBackend(backend taskwarrior.TaskBackend) *ReviewModel {
+	return NewReviewModelWithContext(context.Background(), backend)
+}
+
+// NewReviewModelWithContext creates a new review model whose task calls are
+// bound to ctx
+func NewReviewModelWithContext(ctx context.Context, backend taskwarrior.TaskBackend) *ReviewModel {
 	// Create viewport with minimal initial size - will be resized when WindowSizeMsg arrives
 	vp := viewport.New(1, 1)
 	vp.Style = lipgloss.NewStyle().
@@ -309,7 +467,7 @@ func NewReviewModel() *ReviewModel {
 
 	// Create completion model
 	completion := NewCompletionModel()
-	completion.LoadDynamicData() // Load projects and tags
+	completion.LoadDynamicData(ctx, backend) // Load projects and tags
 
 	// Create confetti model
 	confettiModel := confetti.InitialModel()
@@ -317,10 +475,10 @@ func NewReviewModel() *ReviewModel {
 	// Create AI analyzer (only if API is available)
 	var aiAnalyzer *ai.Analyzer
 	var aiAvailable bool
-	if timeDB, err := timedb.New(); err == nil {
-		// Check if AI/OpenAI is available before creating analyzer
-		if ai.CheckOpenAIAvailable() == nil {
-			aiAnalyzer = ai.NewAnalyzer(timeDB)
+	// Check if AI/OpenAI is available before opening the time database for it
+	if ai.CheckOpenAIAvailable() == nil {
+		if timeDB, err := timedb.New(); err == nil {
+			aiAnalyzer = ai.NewAnalyzerWithBackend(timeDB, backend)
 			aiAvailable = true
 		}
 		// Note: TimeDB will be closed when the model is cleaned up
@@ -342,7 +500,12 @@ func NewReviewModel() *ReviewModel {
 		progress.WithSolidFill("6"), // ANSI cyan
 	)
 
+	opCtx, cancel := context.WithCancel(ctx)
 	model := &ReviewModel{
+		backend:       backend,
+		baseCtx:       ctx,
+		ctx:           opCtx,
+		cancel:        cancel,
 		viewport:      vp,
 		help:          h,
 		textInput:     ti,
@@ -356,10 +519,12 @@ func NewReviewModel() *ReviewModel {
 		aiAvailable:   aiAvailable,
 		aiSpinner:     aiSpinner,
 		promptSpinner: promptSpinner,
+		currentProfile: taskwarrior.ActiveProfile(),
+		started:       time.Now(),
 	}
 
 	// Initialize current context
-	if currentContext, err := taskwarrior.GetCurrentContext(); err == nil {
+	if currentContext, err := backend.GetCurrentContext(ctx); err == nil {
 		model.currentContext = currentContext
 	} else {
 		model.currentContext = "none"
@@ -375,8 +540,10 @@ func (m *ReviewModel) Init() tea.Cmd {
 	// hardcoded viewport size before the actual terminal dimensions are known.
 	return tea.Batch(
 		tea.WindowSize(),
-		// Initialize any other components that need startup commands
-		func() tea.Msg { return nil }, // Placeholder for future init commands
+		// Load the dependency lists and recurring series for the first task
+		m.loadDependencies(),
+		m.loadRecurrence(),
+		m.resumed,
 	)
 }
 
@@ -408,6 +575,20 @@ func (m *ReviewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, cmd)
 
 	case tea.KeyMsg:
+		// Esc and Ctrl-C abandon in-flight task calls in every mode
+		switch msg.String() {
+		case "ctrl+c":
+			m.cancel()
+			m.quitting = true
+			return m, tea.Quit
+		case "esc":
+			m.cancelInFlight()
+		}
+		// Actions wait for a profile switch, which esc cancels
+		if m.switching && !key.Matches(msg, m.keys.Quit) {
+			return m, nil
+		}
+
 		// Handle special input modes
 		switch m.mode {
 		case ModeConfirmDelete:
@@ -418,6 +599,8 @@ func (m *ReviewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateWaitDateInput(msg)
 		case ModeInputWaitReason:
 			return m.updateWaitReasonInput(msg)
+		case ModeInputWaitingOn:
+			return m.updateWaitingOnInput(msg)
 		case ModeWaitCalendar:
 			return m.updateWaitCalendar(msg)
 		case ModeDueCalendar:
@@ -434,10 +617,32 @@ func (m *ReviewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updatePromptAgent(msg)
 		case ModePromptPreview:
 			return m.updatePromptPreview(msg)
+		case ModeHistory:
+			return m.updateHistory(msg)
+		case ModeProfileSelect:
+			return m.updateProfileSelect(msg)
+		case ModeRecurScope:
+			return m.updateRecurScope(msg)
+		case ModeAnnotations:
+			return m.updateAnnotations(msg)
+		case ModeInputAnnotation:
+			return m.updateAnnotationInput(msg)
+		case ModeMarking:
+			return m.updateMarking(msg)
+		case ModeBulkConfirm:
+			return m.updateBulkConfirm(msg)
+		case ModeCelebrating:
+			return m.updateCelebrating(msg)
+		case ModeInputDuration:
+			return m.updateDurationInput(msg)
 		}
+
+		// A bulk action abandoned at one of its prompts ends here
+		m.bulk = nil
 		
 		switch {
 		case key.Matches(msg, m.keys.Quit):
+			m.cancel()
 			m.quitting = true
 			return m, tea.Quit
 
@@ -456,45 +661,46 @@ func (m *ReviewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, m.loadCurrentTask()
 			}
 
+		case key.Matches(msg, m.keys.JumpDependency):
+			n, _ := strconv.Atoi(msg.String())
+			return m, m.jumpToDependency(n)
+
 		case key.Matches(msg, m.keys.Review):
 			return m, m.reviewCurrentTask()
 
+		case key.Matches(msg, m.keys.ReviewSeries):
+			return m, m.reviewSeries()
+
 		case key.Matches(msg, m.keys.Edit):
 			return m, m.editCurrentTask()
 
 		case key.Matches(msg, m.keys.Complete):
-			return m, m.completeCurrentTask()
+			return m, m.startCompletion()
 
 		case key.Matches(msg, m.keys.Delete):
 			m.mode = ModeConfirmDelete
 			m.message = "Delete this task? This action cannot be undone."
 
 		case key.Matches(msg, m.keys.Modify):
-			m.mode = ModeInputModification
-			m.textInput.Placeholder = "Enter modification (e.g., +tag, project:new, priority:H)"
-			m.textInput.SetValue("")
-			m.textInput.Focus()
-			m.message = "Enter modification:"
-			m.modeJustChanged = true
-			m.selectedSuggestion = 0
-			// Initialize completion suggestions
-			m.completion.UpdateSuggestions("", 0)
+			m.startModification()
 
 		case key.Matches(msg, m.keys.Wait):
-			m.mode = ModeWaitCalendar
-			m.calendar.SetFocused(true)
-			m.message = "Select wait date (Tab: text input, x: remove wait, ESC: cancel):"
+			m.startWaitCalendar()
 
 		case key.Matches(msg, m.keys.Due):
-			m.mode = ModeDueCalendar
-			m.calendar.SetFocused(true)
-			m.message = "Select due date (Tab: text input, x: remove due, ESC: cancel):"
+			m.startDueCalendar()
 
 		case key.Matches(msg, m.keys.Skip):
 			return m, m.skipCurrentTask()
 
+		case key.Matches(msg, m.keys.Timer):
+			return m, m.toggleTimer()
+
 		case key.Matches(msg, m.keys.Context):
 			return m, m.initContextSelect()
+
+		case key.Matches(msg, m.keys.Profile):
+			return m, m.initProfileSelect()
 			
 		case key.Matches(msg, m.keys.AIAnalysis):
 			if m.aiAvailable && m.aiAnalyzer != nil {
@@ -523,15 +729,54 @@ func (m *ReviewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			
 		case key.Matches(msg, m.keys.Undo):
 			return m, m.undoLastAction()
+
+		case key.Matches(msg, m.keys.History):
+			m.mode = ModeHistory
+			m.message = ""
+
+		case key.Matches(msg, m.keys.Annotations):
+			m.enterAnnotations()
+
+		case key.Matches(msg, m.keys.Mark):
+			return m, m.enterMarking()
 		}
 
 	case taskLoadedMsg:
 		m.currentTask = msg.task
+		m.selectedAnnotation = max(0, min(m.selectedAnnotation, len(msg.task.Annotations)-1))
 		m.updateViewport()
+		return m, tea.Batch(m.loadDependencies(), m.loadRecurrence())
+
+	case dependenciesLoadedMsg:
+		if msg.deps != nil && m.currentTask != nil && msg.deps.uuid == m.currentTask.UUID {
+			m.deps = msg.deps
+			m.updateViewport()
+		}
+
+	case annotationsChangedMsg:
+		m.message = msg.message
+		return m, m.reloadCurrentTask()
+
+	case annotationOpenedMsg:
+		m.message = fmt.Sprintf("Opened %s.", msg.target)
+
+	case editSnapshotMsg:
+		return m, m.runEditor(msg)
+
+	case recurrenceLoadedMsg:
+		if msg.recur != nil && m.currentTask != nil && msg.recur.uuid == m.currentTask.UUID {
+			m.recur = msg.recur
+			m.updateViewport()
+		}
 
 	case actionCompletedMsg:
 		m.message = msg.message
+		if m.tracking != nil && m.tracking.UUID == msg.stopped {
+			m.tracking = nil
+		}
 		m.reviewed++
+		m.skipped = slices.DeleteFunc(m.skipped, func(uuid string) bool { return uuid == m.tasks[m.current] })
+		m.journal.settle(msg.entry, 1, m.dropUpcoming(msg.covered))
 		
 		// Update progress bar based on tasks reviewed (not current position)
 		progress := float64(m.reviewed) / float64(len(m.tasks))
@@ -545,7 +790,7 @@ func (m *ReviewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Review complete - start celebration!
 			m.mode = ModeCelebrating
 			m.celebrationStart = time.Now()
-			m.message = fmt.Sprintf("🎉 Review complete! %d of %d tasks reviewed. 🎉", m.reviewed, len(m.tasks))
+			m.message = fmt.Sprintf("🎉 Review complete! %d of %d tasks reviewed. 🎉 Press E to export a report or q to quit.", m.reviewed, len(m.tasks))
 			// Initialize confetti with window size and start celebration timer
 			confettiCmd := m.confetti.Init()
 			sizeCmd := func() tea.Msg {
@@ -560,6 +805,9 @@ func (m *ReviewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case taskSkippedMsg:
 		m.message = msg.message
+		if !slices.Contains(m.skipped, m.tasks[m.current]) {
+			m.skipped = append(m.skipped, m.tasks[m.current])
+		}
 		// Don't increment reviewed count for skipped tasks
 		// Progress bar stays the same since no progress was made
 		
@@ -571,7 +819,7 @@ func (m *ReviewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Review complete - start celebration!
 			m.mode = ModeCelebrating
 			m.celebrationStart = time.Now()
-			m.message = fmt.Sprintf("🎉 Review complete! %d of %d tasks reviewed. 🎉", m.reviewed, len(m.tasks))
+			m.message = fmt.Sprintf("🎉 Review complete! %d of %d tasks reviewed. 🎉 Press E to export a report or q to quit.", m.reviewed, len(m.tasks))
 			// Initialize confetti with window size and start celebration timer
 			confettiCmd := m.confetti.Init()
 			sizeCmd := func() tea.Msg {
@@ -585,13 +833,18 @@ func (m *ReviewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	case celebrationCompleteMsg:
-		// Celebration finished, now quit gracefully
-		m.quitting = true
-		return m, tea.Quit
+		// Stop the confetti but keep the summary up, so the report can
+		// still be exported, until the user quits
+		m.celebrationDone = true
+		return m, nil
 
 	case errorMsg:
 		m.err = msg.error
-		m.message = fmt.Sprintf("Error: %v", msg.error)
+		if errors.Is(msg.error, context.Canceled) {
+			m.message = "Cancelled."
+		} else {
+			m.message = taskwarrior.FormatError(msg.error)
+		}
 
 	case contextsLoadedMsg:
 		m.contexts = msg.contexts
@@ -603,6 +856,57 @@ func (m *ReviewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.mode = ModeViewing
 		m.currentContext = msg.context
 		m.message = fmt.Sprintf("Context switched to: %s", msg.context)
+
+	case profilesLoadedMsg:
+		m.profiles = msg.profiles
+		m.mode = ModeProfileSelect
+		m.selectedProfile = 0
+		m.message = "Select profile (↑↓: navigate, Enter: select, ESC: cancel):"
+
+	case profileChangedMsg:
+		m.switching = false
+		// The timer belongs to the old profile, so it ends with the switch
+		if msg.stopped != nil || msg.err == nil {
+			m.tracking = nil
+			m.timerGen++
+		}
+		if msg.err != nil {
+			if errors.Is(msg.err, context.Canceled) {
+				m.message = "Profile switch cancelled."
+			} else {
+				m.message = taskwarrior.FormatError(msg.err)
+			}
+			break
+		}
+		// A different database: start the review over with its tasks
+		m.mode = ModeViewing
+		m.backend = msg.backend
+		m.timeDBPath = msg.timeDBPath
+		m.currentProfile = msg.profile
+		m.currentContext = msg.context
+		m.completion = msg.completion
+		// The old profile's time database is done with
+		if m.aiAnalyzer != nil {
+			m.aiAnalyzer.Close()
+		}
+		m.aiAnalyzer = msg.aiAnalyzer
+		m.aiAvailable = msg.aiAnalyzer != nil
+		// The saved session and report are per profile, so its review starts afresh
+		m.journal.reset()
+		m.skipped = nil
+		m.pastActions = nil
+		m.elapsed = 0
+		m.started = time.Now()
+		m.SetTasks(msg.uuids, len(msg.uuids))
+		m.taskCache = msg.taskCache
+		m.lazyLoadEnabled = len(msg.taskCache) < len(msg.uuids)
+		m.loadedTasks = len(msg.taskCache)
+		m.totalTasks = len(msg.uuids)
+		m.message = fmt.Sprintf("Profile switched to: %s (%d tasks to review)", msg.profile, len(msg.uuids))
+		if msg.stopped != nil {
+			m.message += fmt.Sprintf(". Stopped timing %q.", msg.stopped.Description)
+		}
+		return m, m.loadCurrentTask()
 		
 	case aiAnalysisCompleteMsg:
 		m.currentAnalysis = msg.analysis
@@ -619,14 +923,57 @@ func (m *ReviewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.mode = ModeViewing
 		m.message = fmt.Sprintf("Commands executed: %s", msg.result)
 		
+	case bulkListLoadedMsg:
+		m.markList = msg.tasks
+
+	case bulkCompletedMsg:
+		return m.finishBulk(msg)
+
+	case durationPrefillMsg:
+		m.prefillDuration(msg)
+		return m, nil
+
+	case timerChangedMsg:
+		return m.timerChanged(msg)
+
+	case timerTickMsg:
+		// Each tick redraws the elapsed time in the status bar
+		if m.tracking != nil && msg.gen == m.timerGen {
+			return m, m.tickTimer()
+		}
+		return m, nil
+
+	case reportWrittenMsg:
+		// Keep the report up to date when the review ends
+		m.reportPath = msg.path
+		m.message = fmt.Sprintf("Report written to %s", msg.path)
+
 	case undoCompletedMsg:
 		m.message = msg.message
-		// Reload current task to show updated information
-		return m, m.loadCurrentTask()
+		m.reviewed = max(0, m.reviewed-msg.entry.reviewed)
+		m.restoreUpcoming(msg.entry.dropped)
+
+		// Go back to the restored task, replacing any stale cached copy
+		for _, change := range msg.entry.changes {
+			if m.taskCache != nil {
+				m.taskCache[change.before.UUID] = change.before.ToTask()
+			}
+		}
+		// A bulk action on later tasks only leaves the review where it is
+		bulkAhead := msg.entry.reviewed == 0 && len(msg.entry.dropped) > 0
+		if i := slices.Index(m.tasks, msg.entry.changes[0].before.UUID); i >= 0 && !bulkAhead {
+			m.current = i
+		}
+
+		var progressCmd tea.Cmd
+		if len(m.tasks) > 0 {
+			progressCmd = m.progressBar.SetPercent(float64(m.reviewed) / float64(len(m.tasks)))
+		}
+		return m, tea.Batch(m.loadCurrentTask(), progressCmd)
 	}
 
 	// Update components based on mode
-	if m.mode == ModeInputModification || m.mode == ModeInputWaitDate || m.mode == ModeInputWaitReason || m.mode == ModeInputDueDate || m.mode == ModePromptAgent {
+	if m.mode == ModeInputModification || m.mode == ModeInputWaitDate || m.mode == ModeInputWaitReason || m.mode == ModeInputWaitingOn || m.mode == ModeInputDueDate || m.mode == ModeInputAnnotation || m.mode == ModeInputDuration || m.mode == ModePromptAgent {
 		// Don't process the triggering key if mode just changed
 		if !m.modeJustChanged {
 			m.textInput, cmd = m.textInput.Update(msg)
@@ -636,7 +983,7 @@ func (m *ReviewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	} else if m.mode == ModeWaitCalendar || m.mode == ModeDueCalendar {
 		m.calendar, cmd = m.calendar.Update(msg)
 		cmds = append(cmds, cmd)
-	} else if m.mode == ModeCelebrating {
+	} else if m.mode == ModeCelebrating && !m.celebrationDone {
 		m.confetti, cmd = m.confetti.Update(msg)
 		cmds = append(cmds, cmd)
 	} else if m.mode == ModeAILoading {
@@ -657,6 +1004,50 @@ func (m *ReviewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// startModification opens the modification prompt
+func (m *ReviewModel) startModification() {
+	m.mode = ModeInputModification
+	m.textInput.Placeholder = "Enter modification (e.g., +tag, project:new, priority:H)"
+	m.textInput.SetValue("")
+	m.textInput.Focus()
+	m.message = "Enter modification:"
+	m.modeJustChanged = true
+	m.selectedSuggestion = 0
+	// Initialize completion suggestions
+	m.completion.UpdateSuggestions("", 0)
+}
+
+// startWaitCalendar opens the calendar to pick a wait date
+func (m *ReviewModel) startWaitCalendar() {
+	m.mode = ModeWaitCalendar
+	m.calendar.SetFocused(true)
+	m.message = "Select wait date (Tab: text input, x: remove wait, ESC: cancel):"
+}
+
+// startDueCalendar opens the calendar to pick a due date
+func (m *ReviewModel) startDueCalendar() {
+	m.mode = ModeDueCalendar
+	m.calendar.SetFocused(true)
+	m.message = "Select due date (Tab: text input, x: remove due, ESC: cancel):"
+}
+
+// updateCelebrating handles input on the celebration screen
+func (m *ReviewModel) updateCelebrating(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Quit):
+		m.quitting = true
+		return m, tea.Quit
+
+	case key.Matches(msg, m.keys.Report):
+		path := m.reportPath
+		if path == "" {
+			path = defaultReportPath(time.Now())
+		}
+		return m, m.exportReport(path)
+	}
+	return m, nil
+}
+
 // updateConfirmDelete handles delete confirmation
 func (m *ReviewModel) updateConfirmDelete(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch {
@@ -788,10 +1179,12 @@ func (m *ReviewModel) updateWaitReasonInput(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 	
 	switch msg.Type {
 	case tea.KeyEnter:
-		waitReason := strings.TrimSpace(m.textInput.Value())
-		m.mode = ModeViewing
-		m.message = ""
-		return m, m.waitCurrentTask(m.waitDate, waitReason)
+		m.waitReason = strings.TrimSpace(m.textInput.Value())
+		m.mode = ModeInputWaitingOn
+		m.textInput.Placeholder = "Person or team (optional)"
+		m.textInput.SetValue("")
+		m.message = "Waiting on (optional):"
+		return m, nil
 		
 	case tea.KeyEscape:
 		m.mode = ModeViewing
@@ -804,6 +1197,27 @@ func (m *ReviewModel) updateWaitReasonInput(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 	return m, cmd
 }
 
+// updateWaitingOnInput handles the optional person a waited task waits on
+func (m *ReviewModel) updateWaitingOnInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg.Type {
+	case tea.KeyEnter:
+		waitingOn := strings.TrimSpace(m.textInput.Value())
+		m.mode = ModeViewing
+		m.message = ""
+		return m, m.waitCurrentTask(m.waitDate, m.waitReason, waitingOn)
+
+	case tea.KeyEscape:
+		m.mode = ModeViewing
+		m.message = ""
+		return m, nil
+	}
+
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
+
 // updateWaitCalendar handles calendar input for wait date selection
 func (m *ReviewModel) updateWaitCalendar(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch {
@@ -824,7 +1238,7 @@ func (m *ReviewModel) updateWaitCalendar(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		
 	case key.Matches(msg, m.keys.Confirm):
 		// Select the date from calendar
-		waitDate := m.calendar.GetSelectedDateString()
+		waitDate := m.calendar.GetSelectedTaskDate().String()
 		m.waitDate = waitDate
 		m.mode = ModeInputWaitReason
 		m.calendar.SetFocused(false)
@@ -867,7 +1281,7 @@ func (m *ReviewModel) updateDueCalendar(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		
 	case key.Matches(msg, m.keys.Confirm):
 		// Select the date from calendar
-		dueDate := m.calendar.GetSelectedDateString()
+		dueDate := m.calendar.GetSelectedTaskDate().String()
 		m.mode = ModeViewing
 		m.calendar.SetFocused(false)
 		m.message = ""
@@ -947,7 +1361,7 @@ func (m *ReviewModel) View() string {
 	// Main content area
 	if m.mode == ModeConfirmDelete {
 		sections = append(sections, m.renderConfirmation())
-	} else if m.mode == ModeInputModification || m.mode == ModeInputWaitDate || m.mode == ModeInputWaitReason || m.mode == ModeInputDueDate {
+	} else if m.mode == ModeInputModification || m.mode == ModeInputWaitDate || m.mode == ModeInputWaitReason || m.mode == ModeInputWaitingOn || m.mode == ModeInputDueDate || m.mode == ModeInputAnnotation || m.mode == ModeInputDuration {
 		sections = append(sections, m.renderInput())
 	} else if m.mode == ModeWaitCalendar || m.mode == ModeDueCalendar {
 		sections = append(sections, m.renderCalendar())
@@ -961,6 +1375,18 @@ func (m *ReviewModel) View() string {
 		sections = append(sections, m.renderPromptAgent())
 	} else if m.mode == ModePromptPreview {
 		sections = append(sections, m.renderPromptPreview())
+	} else if m.mode == ModeHistory {
+		sections = append(sections, m.renderHistory())
+	} else if m.mode == ModeProfileSelect {
+		sections = append(sections, m.renderProfileSelect())
+	} else if m.mode == ModeRecurScope {
+		sections = append(sections, m.renderRecurScope())
+	} else if m.mode == ModeAnnotations {
+		sections = append(sections, m.renderAnnotations())
+	} else if m.mode == ModeMarking {
+		sections = append(sections, m.renderMarking())
+	} else if m.mode == ModeBulkConfirm {
+		sections = append(sections, m.renderBulkConfirm())
 	} else if m.mode == ModeCelebrating {
 		sections = append(sections, m.confetti.View())
 	} else {
@@ -1006,8 +1432,14 @@ func (m *ReviewModel) renderStatusBar() string {
 		Padding(0, 1).
 		Width(m.width)
 
-	left := progress + " " + taskTitle
-	return statusStyle.Render(left)
+	left := progress + " " + m.timerStatus(time.Now())
+	if m.currentProfile != "" {
+		left += "[" + m.currentProfile + "] "
+	}
+	if m.queue.Name != "" {
+		left += "(queue: " + m.queue.Label() + ") "
+	}
+	return statusStyle.Render(left + taskTitle)
 }
 
 // renderProgressBar renders the progress bar section
@@ -1194,12 +1626,67 @@ func (m *ReviewModel) updateViewport() {
 		content.WriteString(m.currentTask.Status)
 		content.WriteString("\n")
 	}
-	if m.currentTask.Due != "" {
+	now := time.Now()
+	if !m.currentTask.Due.IsZero() {
 		content.WriteString(labelStyle.Render("Due: "))
-		content.WriteString(m.currentTask.Due)
+		content.WriteString(fmt.Sprintf("%s (%s)", m.currentTask.Due.LocalString(), m.currentTask.Due.DueRelative(now)))
+		content.WriteString("\n")
+	}
+	if !m.currentTask.Scheduled.IsZero() {
+		content.WriteString(labelStyle.Render("Scheduled: "))
+		content.WriteString(fmt.Sprintf("%s (%s)", m.currentTask.Scheduled.LocalString(), m.currentTask.Scheduled.Relative(now)))
+		content.WriteString("\n")
+	}
+	if !m.currentTask.Until.IsZero() {
+		content.WriteString(labelStyle.Render("Until: "))
+		content.WriteString(fmt.Sprintf("%s (%s)", m.currentTask.Until.LocalString(), m.currentTask.Until.Relative(now)))
+		content.WriteString("\n")
+	}
+	m.renderRecurrence(&content, labelStyle.Render)
+	if len(m.currentTask.Tags) > 0 {
+		content.WriteString(labelStyle.Render("Tags: "))
+		content.WriteString(m.currentTask.GetDisplayTags())
+		content.WriteString("\n")
+	}
+	m.renderDependencies(&content, labelStyle.Render)
+	if !m.currentTask.Start.IsZero() {
+		content.WriteString(labelStyle.Render("Started: "))
+		content.WriteString(fmt.Sprintf("%s (%s)", m.currentTask.Start.LocalString(), m.currentTask.Start.Relative(now)))
+		content.WriteString("\n")
+	}
+	// When the task comes back once reviewed now, under the interval rules
+	interval, rule := m.queue.IntervalFor(m.currentTask.Project, m.currentTask.Tags, m.currentTask.Priority)
+	if next, err := filter.AddRecurrence(now, interval, 1); err == nil {
+		schedule := "every " + interval
+		if rule != nil {
+			schedule += " for " + rule.String()
+		}
+		content.WriteString(labelStyle.Render("Next review: "))
+		content.WriteString(fmt.Sprintf("%s (%s)", taskwarrior.NewDate(next).LocalString(), schedule))
+		content.WriteString("\n")
+	}
+
+	// User defined attributes, in a stable order
+	udaNames := make([]string, 0, len(m.currentTask.UDA))
+	for name := range m.currentTask.UDA {
+		udaNames = append(udaNames, name)
+	}
+	sort.Strings(udaNames)
+	for _, name := range udaNames {
+		content.WriteString(labelStyle.Render(name + ": "))
+		content.WriteString(m.currentTask.UDA[name])
 		content.WriteString("\n")
 	}
 
+	if len(m.currentTask.Annotations) > 0 {
+		content.WriteString("\n")
+		content.WriteString(labelStyle.Render("Annotations:"))
+		content.WriteString("\n")
+		for _, ann := range m.currentTask.Annotations {
+			content.WriteString(fmt.Sprintf("  %s  %s\n", ann.Entry.LocalString(), ann.Description))
+		}
+	}
+
 	content.WriteString("\n")
 	content.WriteString(labelStyle.Render("UUID: "))
 	content.WriteString(m.currentTask.UUID)
@@ -1214,6 +1701,9 @@ type taskLoadedMsg struct {
 
 type actionCompletedMsg struct {
 	message string
+	covered []string // later tasks in the review the action also handled
+	stopped string   // UUID of an active task the action ended, e.g. by completing it
+	entry   int      // journal entry for the action, when it covered later tasks
 }
 
 type taskSkippedMsg struct {
@@ -1222,6 +1712,12 @@ type taskSkippedMsg struct {
 
 type celebrationCompleteMsg struct{}
 
+// editSnapshotMsg carries the task as it was before editing, for undo
+type editSnapshotMsg struct {
+	uuid   string
+	before map[string]*taskwarrior.TaskData
+}
+
 type errorMsg struct {
 	error error
 }
@@ -1234,6 +1730,24 @@ type contextChangedMsg struct {
 	context string
 }
 
+type profilesLoadedMsg struct {
+	profiles []taskwarrior.Profile
+}
+
+// profileChangedMsg carries the review state loaded from the new profile
+type profileChangedMsg struct {
+	profile    string
+	backend    taskwarrior.TaskBackend
+	timeDBPath string
+	context    string
+	uuids      []string
+	taskCache  map[string]*taskwarrior.Task
+	completion *CompletionModel
+	aiAnalyzer *ai.Analyzer
+	stopped    *timedb.Timer // timer the switch stopped in the old profile
+	err        error
+}
+
 type aiAnalysisCompleteMsg struct {
 	analysis *ai.TaskAnalysis
 }
@@ -1249,10 +1763,24 @@ type promptCommandsExecutedMsg struct {
 
 type undoCompletedMsg struct {
 	message string
+	entry   journalEntry
+}
+
+// openTimeDB opens the time database of the profile in use
+func (m *ReviewModel) openTimeDB() (*timedb.TimeDB, error) {
+	return timedb.Open(m.timeDBPath)
+}
+
+// cancelInFlight cancels running task calls and starts a fresh context for
+// the calls that follow
+func (m *ReviewModel) cancelInFlight() {
+	m.cancel()
+	m.ctx, m.cancel = context.WithCancel(m.baseCtx)
 }
 
 // Commands for async operations
 func (m *ReviewModel) loadCurrentTask() tea.Cmd {
+	ctx := m.ctx
 	return func() tea.Msg {
 		if m.current >= len(m.tasks) {
 			return errorMsg{fmt.Errorf("task index out of range")}
@@ -1263,7 +1791,7 @@ func (m *ReviewModel) loadCurrentTask() tea.Cmd {
 		   m.current >= m.loadedTasks-10 && m.loadedTasks < m.totalTasks {
 			// Trigger background loading of next batch
 			m.loadingMore = true
-			go m.loadNextBatch()
+			go m.loadNextBatch(ctx, m.backend)
 		}
 		
 		// Use cached task data if available
@@ -1274,7 +1802,7 @@ func (m *ReviewModel) loadCurrentTask() tea.Cmd {
 		}
 		
 		// Fall back to individual task loading if cache miss
-		task, err := taskwarrior.GetTaskInfo(m.tasks[m.current])
+		task, err := m.backend.GetTaskInfo(ctx, m.tasks[m.current])
 		if err != nil {
 			return errorMsg{err}
 		}
@@ -1284,23 +1812,48 @@ func (m *ReviewModel) loadCurrentTask() tea.Cmd {
 }
 
 func (m *ReviewModel) reviewCurrentTask() tea.Cmd {
+	return m.runAction("Review", "Marked as reviewed.", func(ctx context.Context, uuid string) error {
+		return m.backend.MarkTaskReviewed(ctx, uuid)
+	})
+}
+
+// editCurrentTask snapshots the current task for undo, then opens it in
+// the editor once the snapshot arrives
+func (m *ReviewModel) editCurrentTask() tea.Cmd {
+	uuid := m.tasks[m.current]
+	ctx := m.ctx
 	return func() tea.Msg {
-		if err := taskwarrior.MarkTaskReviewed(m.tasks[m.current]); err != nil {
+		before, err := m.journal.snapshot(ctx, m.backend, []string{uuid})
+		if err != nil {
 			return errorMsg{err}
 		}
-		return actionCompletedMsg{message: "Marked as reviewed."}
+		return editSnapshotMsg{uuid: uuid, before: before}
 	}
 }
 
-func (m *ReviewModel) editCurrentTask() tea.Cmd {
-	return tea.ExecProcess(taskwarrior.CreateEditCommand(m.tasks[m.current]), func(err error) tea.Msg {
+// runEditor hands the terminal to the editor for a snapshotted task
+func (m *ReviewModel) runEditor(msg editSnapshotMsg) tea.Cmd {
+	uuid, before := msg.uuid, msg.before
+	cmd := m.backend.CreateEditCommand(uuid)
+	if cmd == nil {
+		return func() tea.Msg {
+			return errorMsg{fmt.Errorf("editing is not supported by this task backend")}
+		}
+	}
+
+	ctx := m.ctx
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
 		if err != nil {
 			return errorMsg{err}
 		}
 		
 		// Mark task as reviewed after successful edit
-		if err := taskwarrior.MarkTaskReviewed(m.tasks[m.current]); err != nil {
-			return errorMsg{fmt.Errorf("edit succeeded but failed to mark as reviewed: %w", err)}
+		markErr := m.backend.MarkTaskReviewed(ctx, uuid)
+		if err := m.journal.commit(ctx, m.backend, "Edit", true, []string{uuid}, before); err != nil && markErr == nil {
+			return errorMsg{err}
+		}
+		if markErr != nil {
+			return errorMsg{fmt.Errorf("edit succeeded but failed to mark as reviewed: %w", markErr)}
 		}
 		
 		return actionCompletedMsg{message: "Task updated."}
@@ -1308,21 +1861,33 @@ func (m *ReviewModel) editCurrentTask() tea.Cmd {
 }
 
 func (m *ReviewModel) completeCurrentTask() tea.Cmd {
+	complete := m.runAction("Complete", "Task completed.", func(ctx context.Context, uuid string) error {
+		return m.backend.CompleteTask(ctx, uuid)
+	})
+	task := m.currentTask
+	if task == nil || task.Start.IsZero() || task.UUID != m.tasks[m.current] {
+		return complete
+	}
+
+	// Completing an active task ends its work interval
 	return func() tea.Msg {
-		if err := taskwarrior.CompleteTask(m.tasks[m.current]); err != nil {
-			return errorMsg{err}
+		msg := complete()
+		done, ok := msg.(actionCompletedMsg)
+		if !ok {
+			return msg
 		}
-		return actionCompletedMsg{message: "Task completed."}
+		done.stopped = task.UUID
+		if err := recordWork(m.timeDBPath, task.UUID, task.Start.Time, time.Now()); err != nil {
+			done.message = fmt.Sprintf("Task completed, but its work time was not recorded: %v", err)
+		}
+		return done
 	}
 }
 
 func (m *ReviewModel) deleteCurrentTask() tea.Cmd {
-	return func() tea.Msg {
-		if err := taskwarrior.DeleteTask(m.tasks[m.current]); err != nil {
-			return errorMsg{err}
-		}
-		return actionCompletedMsg{message: "Task deleted."}
-	}
+	return m.runScopedAction("Delete", "Task deleted.", func(ctx context.Context, uuid string) error {
+		return m.backend.DeleteTask(ctx, uuid)
+	})
 }
 
 func (m *ReviewModel) skipCurrentTask() tea.Cmd {
@@ -1333,64 +1898,146 @@ func (m *ReviewModel) skipCurrentTask() tea.Cmd {
 }
 
 func (m *ReviewModel) modifyCurrentTask(modification string) tea.Cmd {
-	return func() tea.Msg {
-		if err := taskwarrior.ModifyTask(m.tasks[m.current], modification); err != nil {
-			return errorMsg{err}
-		}
-		return actionCompletedMsg{message: "Task modified."}
+	if m.bulk != nil {
+		return m.confirmBulk("Modify", modification, func(ctx context.Context, uuids []string) error {
+			return m.backend.ModifyTasks(ctx, uuids, strings.Fields(modification))
+		})
 	}
+	return m.runScopedAction("Modify", "Task modified.", func(ctx context.Context, uuid string) error {
+		return m.backend.ModifyTask(ctx, uuid, modification)
+	})
 }
 
-func (m *ReviewModel) waitCurrentTask(waitDate, reason string) tea.Cmd {
-	return func() tea.Msg {
-		if err := taskwarrior.WaitTask(m.tasks[m.current], waitDate, reason); err != nil {
-			return errorMsg{err}
-		}
-		message := fmt.Sprintf("Task set to wait until %s.", waitDate)
-		return actionCompletedMsg{message: message}
+func (m *ReviewModel) waitCurrentTask(waitDate, reason, waitingOn string) tea.Cmd {
+	if m.bulk != nil {
+		return m.confirmBulk("Wait until", waitDate, func(ctx context.Context, uuids []string) error {
+			return m.backend.ModifyTasks(ctx, uuids, taskwarrior.WaitModifications(waitDate, reason, waitingOn, time.Now()))
+		})
 	}
+	message := fmt.Sprintf("Task set to wait until %s.", waitDate)
+	return m.runScopedAction("Wait", message, func(ctx context.Context, uuid string) error {
+		return m.backend.WaitTask(ctx, uuid, waitDate, reason, waitingOn)
+	})
 }
 
 func (m *ReviewModel) dueCurrentTask(dueDate string) tea.Cmd {
-	return func() tea.Msg {
-		if err := taskwarrior.SetDueDate(m.tasks[m.current], dueDate); err != nil {
-			return errorMsg{err}
-		}
-		message := fmt.Sprintf("Task due date set to %s.", dueDate)
-		return actionCompletedMsg{message: message}
-	}
+	if m.bulk != nil {
+		return m.confirmBulk("Set due", dueDate, func(ctx context.Context, uuids []string) error {
+			return m.backend.ModifyTasks(ctx, uuids, []string{"due:" + dueDate})
+		})
+	}
+	message := fmt.Sprintf("Task due date set to %s.", dueDate)
+	return m.runScopedAction("Set due", message, func(ctx context.Context, uuid string) error {
+		return m.backend.SetDueDate(ctx, uuid, dueDate)
+	})
 }
 
 func (m *ReviewModel) removeDueCurrentTask() tea.Cmd {
-	return func() tea.Msg {
-		if err := taskwarrior.RemoveDueDate(m.tasks[m.current]); err != nil {
-			return errorMsg{err}
-		}
-		message := "Task due date removed."
-		return actionCompletedMsg{message: message}
+	if m.bulk != nil {
+		return m.confirmBulk("Remove due", "", func(ctx context.Context, uuids []string) error {
+			return m.backend.ModifyTasks(ctx, uuids, []string{"due:"})
+		})
 	}
+	return m.runAction("Remove due", "Task due date removed.", func(ctx context.Context, uuid string) error {
+		return m.backend.RemoveDueDate(ctx, uuid)
+	})
 }
 
 func (m *ReviewModel) removeWaitCurrentTask() tea.Cmd {
+	if m.bulk != nil {
+		return m.confirmBulk("Remove wait", "", func(ctx context.Context, uuids []string) error {
+			return m.backend.ModifyTasks(ctx, uuids, taskwarrior.UnwaitModifications)
+		})
+	}
+	return m.runAction("Remove wait", "Task wait date removed.", func(ctx context.Context, uuid string) error {
+		return m.backend.RemoveWaitDate(ctx, uuid)
+	})
+}
+
+// runAction runs a review action on the current task, journaling its
+// changes for undo, and moves on to the next task when it succeeds
+func (m *ReviewModel) runAction(verb, message string, action func(ctx context.Context, uuid string) error) tea.Cmd {
+	ctx := m.ctx
+	uuid := m.tasks[m.current]
 	return func() tea.Msg {
-		if err := taskwarrior.RemoveWaitDate(m.tasks[m.current]); err != nil {
+		if err := m.journal.record(ctx, m.backend, verb, true, []string{uuid}, func() error {
+			return action(ctx, uuid)
+		}); err != nil {
 			return errorMsg{err}
 		}
-		message := "Task wait date removed."
 		return actionCompletedMsg{message: message}
 	}
 }
 
+// undoLastAction reverts the most recent journaled action, which may span
+// several task changes
 func (m *ReviewModel) undoLastAction() tea.Cmd {
+	ctx := m.ctx
 	return func() tea.Msg {
-		if err := taskwarrior.UndoLastAction(); err != nil {
+		entry, err := m.journal.undo(ctx, m.backend)
+		if err != nil {
 			return errorMsg{err}
 		}
-		message := "Last action undone."
-		return undoCompletedMsg{message: message}
+		message := fmt.Sprintf("Undid %s.", entry.describe())
+		return undoCompletedMsg{message: message, entry: entry}
 	}
 }
 
+// updateHistory handles input in the undo history panel
+func (m *ReviewModel) updateHistory(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Undo):
+		return m, m.undoLastAction()
+
+	case key.Matches(msg, m.keys.History) || key.Matches(msg, m.keys.Cancel):
+		m.mode = ModeViewing
+		m.message = ""
+
+	case key.Matches(msg, m.keys.Quit):
+		m.cancel()
+		m.quitting = true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// renderHistory renders the undo history panel, listing what each press of
+// undo will revert
+func (m *ReviewModel) renderHistory() string {
+	historyStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("3")). // ANSI yellow
+		Padding(1, 2).
+		Margin(2, 4)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+
+	var content strings.Builder
+	content.WriteString(lipgloss.NewStyle().Bold(true).Render("Undo History"))
+	content.WriteString(dimStyle.Render(" (most recent first)"))
+	content.WriteString("\n\n")
+
+	entries := m.journal.history()
+	if len(entries) == 0 {
+		content.WriteString("Nothing to undo in this session.\n")
+	}
+	for i, entry := range entries {
+		content.WriteString(fmt.Sprintf("%d. %s", i+1, entry.describe()))
+		content.WriteString(dimStyle.Render(" " + entry.at.Format("15:04")))
+		content.WriteString("\n")
+		for _, change := range entry.changes {
+			if len(entry.changes) > 1 {
+				content.WriteString(fmt.Sprintf("   %s\n", change.after.Description))
+			}
+			for _, diff := range diffTasks(change.before, change.after) {
+				content.WriteString(dimStyle.Render("     "+diff) + "\n")
+			}
+		}
+	}
+
+	content.WriteString("\nz: undo most recent  h/ESC: close")
+	return historyStyle.Render(content.String())
+}
+
 // SetTasks initializes the review session with tasks
 func (m *ReviewModel) SetTasks(tasks []string, total int) {
 	m.tasks = tasks
@@ -1476,8 +2123,9 @@ func (m *ReviewModel) renderContextSelect() string {
 
 // initContextSelect initializes context selection mode
 func (m *ReviewModel) initContextSelect() tea.Cmd {
+	ctx := m.ctx
 	return func() tea.Msg {
-		contexts, err := taskwarrior.GetContexts()
+		contexts, err := m.backend.GetContexts(ctx)
 		if err != nil {
 			return errorMsg{err}
 		}
@@ -1488,22 +2136,175 @@ func (m *ReviewModel) initContextSelect() tea.Cmd {
 
 // switchContext switches to the selected context
 func (m *ReviewModel) switchContext(contextName string) tea.Cmd {
+	ctx := m.ctx
 	return func() tea.Msg {
-		if err := taskwarrior.SetContext(contextName); err != nil {
+		if err := m.backend.SetContext(ctx, contextName); err != nil {
 			return errorMsg{err}
 		}
 		return contextChangedMsg{context: contextName}
 	}
 }
 
+// initProfileSelect initializes profile selection mode
+func (m *ReviewModel) initProfileSelect() tea.Cmd {
+	return func() tea.Msg {
+		profiles, _, err := taskwarrior.LoadProfiles()
+		if err != nil {
+			return errorMsg{err}
+		}
+		return profilesLoadedMsg{profiles: profiles}
+	}
+}
+
+// updateProfileSelect handles profile selection input
+func (m *ReviewModel) updateProfileSelect(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Cancel):
+		m.mode = ModeViewing
+		m.message = ""
+		return m, nil
+
+	case key.Matches(msg, m.keys.Confirm):
+		m.mode = ModeViewing
+		m.message = ""
+		if len(m.profiles) > 0 && m.selectedProfile < len(m.profiles) {
+			m.message = "Loading profile... (esc to cancel)"
+			return m, m.switchProfile(m.profiles[m.selectedProfile])
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.NextTask) || msg.String() == "down":
+		if len(m.profiles) > 0 {
+			m.selectedProfile = (m.selectedProfile + 1) % len(m.profiles)
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.PrevTask) || msg.String() == "up":
+		if len(m.profiles) > 0 {
+			m.selectedProfile = (m.selectedProfile - 1 + len(m.profiles)) % len(m.profiles)
+		}
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// renderProfileSelect renders the profile selection interface
+func (m *ReviewModel) renderProfileSelect() string {
+	profileStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("6")). // ANSI cyan
+		Padding(1, 2).
+		Margin(2, 4)
+
+	var content strings.Builder
+	content.WriteString("Available Profiles:\n\n")
+
+	for i, profile := range m.profiles {
+		prefix := "  "
+		if i == m.selectedProfile {
+			prefix = "▶ " // Highlight selected profile
+		}
+
+		line := prefix + profile.Name
+		if profile.Data != "" {
+			line += " - " + profile.Data
+		}
+		if profile.Name == m.currentProfile {
+			line += " (current)"
+		}
+
+		content.WriteString(line)
+		content.WriteString("\n")
+	}
+
+	content.WriteString("\n↑↓: navigate  Enter: select  ESC: cancel")
+
+	return profileStyle.Render(content.String())
+}
+
+// switchProfile loads the selected profile's tasks for review through a
+// backend of its own. Task calls still running for the current profile are
+// cancelled, and actions wait until the switch is done. A running timer is
+// stopped and recorded in the current profile first.
+func (m *ReviewModel) switchProfile(profile taskwarrior.Profile) tea.Cmd {
+	m.cancelInFlight()
+	m.switching = true
+	ctx := m.ctx
+	tracking, previous, previousTimeDB := m.tracking, m.backend, m.timeDBPath
+	backend := taskwarrior.ForProfile(m.backend, profile)
+	return func() tea.Msg {
+		if tracking != nil {
+			if err := previous.StopTask(ctx, tracking.UUID); err != nil {
+				return profileChangedMsg{err: err}
+			}
+			if err := recordWork(previousTimeDB, tracking.UUID, tracking.Since, time.Now()); err != nil {
+				return profileChangedMsg{err: err, stopped: tracking}
+			}
+		}
+
+		timeDBPath, err := timedb.ResolvePath(profile.Getenv("TASKSH_TIMEDB"))
+		if err != nil {
+			return profileChangedMsg{err: err, stopped: tracking}
+		}
+		msg, err := m.loadProfile(ctx, backend, timeDBPath, profile.Name)
+		if err != nil {
+			return profileChangedMsg{err: err, stopped: tracking}
+		}
+		msg.stopped = tracking
+		return msg
+	}
+}
+
+// loadProfile loads the review state for a profile from its backend
+func (m *ReviewModel) loadProfile(ctx context.Context, backend taskwarrior.TaskBackend, timeDBPath, name string) (profileChangedMsg, error) {
+	// The TUI owns the terminal, so setup runs quietly
+	if err := backend.EnsureReviewConfig(ctx, io.Discard); err != nil {
+		return profileChangedMsg{}, fmt.Errorf("failed to configure review: %w", err)
+	}
+	uuids, err := backend.GetTasksForQueue(ctx, m.queue)
+	if err != nil {
+		return profileChangedMsg{}, fmt.Errorf("failed to get tasks for review: %w", err)
+	}
+	if len(uuids) == 0 {
+		return profileChangedMsg{}, fmt.Errorf("profile %s has no tasks needing review", name)
+	}
+
+	// Load the first batch now; the rest lazy load as the review goes on
+	tasks, err := backend.GetTasksWithDataProgress(ctx, uuids[:min(len(uuids), lazyLoadThreshold())], nil)
+	if err != nil {
+		return profileChangedMsg{}, err
+	}
+	taskCache := make(map[string]*taskwarrior.Task, len(tasks))
+	for _, td := range tasks {
+		taskCache[td.UUID] = td.ToTask()
+	}
+
+	msg := profileChangedMsg{profile: name, backend: backend, timeDBPath: timeDBPath, context: "none", uuids: uuids, taskCache: taskCache}
+	if currentContext, err := backend.GetCurrentContext(ctx); err == nil {
+		msg.context = currentContext
+	}
+	msg.completion = NewCompletionModel()
+	msg.completion.LoadDynamicData(ctx, backend)
+
+	// The time database is per profile, so AI estimates come from it
+	if ai.CheckOpenAIAvailable() == nil {
+		if timeDB, err := timedb.Open(timeDBPath); err == nil {
+			msg.aiAnalyzer = ai.NewAnalyzerWithBackend(timeDB, backend)
+		}
+	}
+	return msg, nil
+}
+
 // analyzeCurrentTask performs AI analysis on the current task
 func (m *ReviewModel) analyzeCurrentTask() tea.Cmd {
+	ctx := m.ctx
 	return func() tea.Msg {
 		if m.currentTask == nil {
 			return errorMsg{fmt.Errorf("no current task to analyze")}
 		}
 		
-		analysis, err := m.aiAnalyzer.AnalyzeTask(m.currentTask)
+		analysis, err := m.aiAnalyzer.AnalyzeTask(ctx, m.currentTask)
 		if err != nil {
 			return errorMsg{fmt.Errorf("AI analysis failed: %w", err)}
 		}
@@ -1682,12 +2483,13 @@ func (m *ReviewModel) renderAILoading() string {
 
 // generatePromptCommands converts user prompt to taskwarrior commands
 func (m *ReviewModel) generatePromptCommands(prompt string) tea.Cmd {
+	ctx := m.ctx
 	return func() tea.Msg {
 		if m.currentTask == nil {
 			return errorMsg{fmt.Errorf("no current task to work with")}
 		}
 		
-		commands, preview, err := m.aiAnalyzer.GenerateCommands(m.currentTask, prompt)
+		commands, preview, err := m.aiAnalyzer.GenerateCommands(ctx, m.currentTask, prompt)
 		if err != nil {
 			return errorMsg{fmt.Errorf("command generation failed: %w", err)}
 		}
@@ -1701,40 +2503,49 @@ func (m *ReviewModel) generatePromptCommands(prompt string) tea.Cmd {
 
 // executePromptCommands executes the generated commands
 func (m *ReviewModel) executePromptCommands() tea.Cmd {
+	ctx := m.ctx
+	uuid := m.tasks[m.current]
 	return func() tea.Msg {
 		results := []string{}
 		errors := []string{}
 		
-		for _, cmdStr := range m.generatedCommands {
-			// Parse the command to determine if it's taskwarrior or timewarrior
-			parts := strings.Fields(cmdStr)
-			if len(parts) == 0 {
-				continue
-			}
-			
-			var err error
-			switch parts[0] {
-			case "task":
-				// Execute taskwarrior command
-				err = m.executeTaskCommand(parts[1:])
-				if err == nil {
-					results = append(results, fmt.Sprintf("✓ task %s", strings.Join(parts[1:], " ")))
-				} else {
-					errors = append(errors, fmt.Sprintf("✗ task %s: %v", strings.Join(parts[1:], " "), err))
+		// Journal the commands as one action on the current task
+		err := m.journal.record(ctx, m.backend, "Prompt agent", false, []string{uuid}, func() error {
+			for _, cmdStr := range m.generatedCommands {
+				// Parse the command to determine if it's taskwarrior or timewarrior
+				parts := strings.Fields(cmdStr)
+				if len(parts) == 0 {
+					continue
 				}
+			
+				var err error
+				switch parts[0] {
+				case "task":
+					// Execute taskwarrior command
+					err = m.executeTaskCommand(ctx, parts[1:])
+					if err == nil {
+						results = append(results, fmt.Sprintf("✓ task %s", strings.Join(parts[1:], " ")))
+					} else {
+						errors = append(errors, fmt.Sprintf("✗ task %s: %v", strings.Join(parts[1:], " "), err))
+					}
 				
-			case "timew":
-				// Execute timewarrior command  
-				err = m.executeTimewCommand(parts[1:])
-				if err == nil {
-					results = append(results, fmt.Sprintf("✓ timew %s", strings.Join(parts[1:], " ")))
-				} else {
-					errors = append(errors, fmt.Sprintf("✗ timew %s: %v", strings.Join(parts[1:], " "), err))
-				}
+				case "timew":
+					// Execute timewarrior command  
+					err = m.executeTimewCommand(ctx, parts[1:])
+					if err == nil {
+						results = append(results, fmt.Sprintf("✓ timew %s", strings.Join(parts[1:], " ")))
+					} else {
+						errors = append(errors, fmt.Sprintf("✗ timew %s: %v", strings.Join(parts[1:], " "), err))
+					}
 				
-			default:
-				errors = append(errors, fmt.Sprintf("✗ Unknown command: %s", parts[0]))
+				default:
+					errors = append(errors, fmt.Sprintf("✗ Unknown command: %s", parts[0]))
+				}
 			}
+			return nil
+		})
+		if err != nil {
+			return errorMsg{err}
 		}
 		
 		// Combine results and errors
@@ -1746,39 +2557,23 @@ func (m *ReviewModel) executePromptCommands() tea.Cmd {
 }
 
 // executeTaskCommand executes a taskwarrior command safely
-func (m *ReviewModel) executeTaskCommand(args []string) error {
+func (m *ReviewModel) executeTaskCommand(ctx context.Context, args []string) error {
 	if len(args) == 0 {
 		return fmt.Errorf("no task command specified")
 	}
 	
-	// Build the command
-	cmd := exec.Command("task", args...)
-	
-	// Execute the command
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("task command failed: %s", string(output))
+	// Execute the command through the task backend
+	if _, err := m.backend.ExecuteCommand(ctx, args); err != nil {
+		return err
 	}
 	
 	return nil
 }
 
 // executeTimewCommand executes a timewarrior command safely  
-func (m *ReviewModel) executeTimewCommand(args []string) error {
-	if len(args) == 0 {
-		return fmt.Errorf("no timew command specified")
-	}
-	
-	// Build the command
-	cmd := exec.Command("timew", args...)
-	
-	// Execute the command
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("timew command failed: %s", string(output))
-	}
-	
-	return nil
+func (m *ReviewModel) executeTimewCommand(ctx context.Context, args []string) error {
+	_, err := timewarrior.Run(ctx, args...)
+	return err
 }
 
 // updatePromptAgent handles prompt agent input mode
@@ -1874,7 +2669,7 @@ func (m *ReviewModel) renderPromptPreview() string {
 }
 
 // loadNextBatch loads the next batch of tasks in the background
-func (m *ReviewModel) loadNextBatch() {
+func (m *ReviewModel) loadNextBatch(ctx context.Context, backend taskwarrior.TaskBackend) {
 	// Determine batch size
 	batchSize := 100
 	if val := os.Getenv("TASKSH_BATCH_SIZE"); val != "" {
@@ -1892,8 +2687,9 @@ func (m *ReviewModel) loadNextBatch() {
 	
 	// Load the batch
 	batchUUIDs := m.tasks[start:end]
-	taskData, err := taskwarrior.GetTasksWithData(batchUUIDs)
-	if err != nil {
+	taskData, err := backend.GetTasksWithDataProgress(ctx, batchUUIDs, nil)
+	// A profile switch cancels ctx; its tasks are not this profile's
+	if err != nil || ctx.Err() != nil {
 		// Log error but don't crash
 		m.loadingMore = false
 		return
@@ -1901,14 +2697,7 @@ func (m *ReviewModel) loadNextBatch() {
 	
 	// Update cache with new data
 	for _, td := range taskData {
-		m.taskCache[td.UUID] = &taskwarrior.Task{
-			UUID:        td.UUID,
-			Description: td.Description,
-			Project:     td.Project,
-			Priority:    td.Priority,
-			Status:      td.Status,
-			Due:         td.Due,
-		}
+		m.taskCache[td.UUID] = td.ToTask()
 	}
 	
 	m.loadedTasks = end
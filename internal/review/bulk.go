@@ -0,0 +1,291 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/emiller/tasksh/internal/taskwarrior"
+)
+
+// bulkAction is an action on the marked tasks. While its input is prompted
+// for it is empty; confirmBulk fills it in.
+type bulkAction struct {
+	verb   string // e.g. "Modify"
+	detail string // e.g. the modification, or ""
+	run    func(ctx context.Context, uuids []string) error
+}
+
+// label describes the action, e.g. "Modify +next"
+func (a *bulkAction) label() string {
+	if a.detail == "" {
+		return a.verb
+	}
+	return a.verb + " " + a.detail
+}
+
+type bulkListLoadedMsg struct {
+	tasks []*taskwarrior.TaskData
+}
+
+type bulkCompletedMsg struct {
+	message string
+	uuids   []string
+	entry   int // journal entry for the action
+}
+
+// enterMarking lists the rest of the review, from the current task, for
+// marking tasks to act on together
+func (m *ReviewModel) enterMarking() tea.Cmd {
+	if len(m.tasks) == 0 {
+		return nil
+	}
+	m.mode = ModeMarking
+	m.marked = make(map[string]bool)
+	m.markList = nil
+	m.markCursor = 0
+	m.bulk = nil
+	m.message = "Mark tasks with space, then choose an action."
+
+	ctx := m.ctx
+	uuids := slices.Clone(m.tasks[m.current:])
+	return func() tea.Msg {
+		tasks, err := m.backend.GetTasksWithDataProgress(ctx, uuids, nil)
+		if err != nil {
+			return errorMsg{err}
+		}
+		// Keep the review order
+		slices.SortStableFunc(tasks, func(a, b *taskwarrior.TaskData) int {
+			return slices.Index(uuids, a.UUID) - slices.Index(uuids, b.UUID)
+		})
+		return bulkListLoadedMsg{tasks: tasks}
+	}
+}
+
+// exitMarking leaves marking mode, dropping the marks
+func (m *ReviewModel) exitMarking() {
+	m.mode = ModeViewing
+	m.marked = nil
+	m.markList = nil
+	m.bulk = nil
+	m.message = ""
+}
+
+// markedUUIDs returns the marked tasks in review order
+func (m *ReviewModel) markedUUIDs() []string {
+	var uuids []string
+	for _, td := range m.markList {
+		if m.marked[td.UUID] {
+			uuids = append(uuids, td.UUID)
+		}
+	}
+	return uuids
+}
+
+// updateMarking handles input in the marking list
+func (m *ReviewModel) updateMarking(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Quit):
+		m.cancel()
+		m.quitting = true
+		return m, tea.Quit
+
+	case key.Matches(msg, m.keys.Cancel) || key.Matches(msg, m.keys.Mark):
+		m.exitMarking()
+		return m, nil
+
+	case key.Matches(msg, m.keys.NextTask):
+		if m.markCursor < len(m.markList)-1 {
+			m.markCursor++
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.PrevTask):
+		if m.markCursor > 0 {
+			m.markCursor--
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.ToggleMark):
+		if m.markCursor < len(m.markList) {
+			uuid := m.markList[m.markCursor].UUID
+			if m.marked[uuid] {
+				delete(m.marked, uuid)
+			} else {
+				m.marked[uuid] = true
+			}
+			if m.markCursor < len(m.markList)-1 {
+				m.markCursor++
+			}
+		}
+		return m, nil
+	}
+
+	// Everything else is an action on the marked tasks
+	if len(m.marked) == 0 {
+		m.message = "Mark tasks with space first."
+		return m, nil
+	}
+	switch {
+	case key.Matches(msg, m.keys.Review):
+		return m, m.confirmBulk("Mark reviewed", "", func(ctx context.Context, uuids []string) error {
+			return m.backend.ModifyTasks(ctx, uuids, []string{"reviewed:now"})
+		})
+
+	case key.Matches(msg, m.keys.Complete):
+		return m, m.confirmBulk("Complete", "", m.backend.CompleteTasks)
+
+	case key.Matches(msg, m.keys.Delete):
+		return m, m.confirmBulk("Delete", "", m.backend.DeleteTasks)
+
+	// The prompts are the single task ones; with m.bulk set they end in
+	// confirmBulk instead of acting on the current task
+	case key.Matches(msg, m.keys.Modify):
+		m.bulk = &bulkAction{}
+		m.startModification()
+
+	case key.Matches(msg, m.keys.Wait):
+		m.bulk = &bulkAction{}
+		m.startWaitCalendar()
+
+	case key.Matches(msg, m.keys.Due):
+		m.bulk = &bulkAction{}
+		m.startDueCalendar()
+	}
+	return m, nil
+}
+
+// confirmBulk asks to confirm an action on the marked tasks
+func (m *ReviewModel) confirmBulk(verb, detail string, run func(ctx context.Context, uuids []string) error) tea.Cmd {
+	m.bulk = &bulkAction{verb: verb, detail: detail, run: run}
+	m.mode = ModeBulkConfirm
+	m.message = fmt.Sprintf("%s on %d tasks?", m.bulk.label(), len(m.marked))
+	return nil
+}
+
+// updateBulkConfirm handles the bulk action confirmation
+func (m *ReviewModel) updateBulkConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Confirm):
+		m.mode = ModeViewing
+		m.message = ""
+		return m, m.runBulk()
+
+	case key.Matches(msg, m.keys.Cancel):
+		m.bulk = nil
+		m.mode = ModeMarking
+		m.message = "Mark tasks with space, then choose an action."
+	}
+	return m, nil
+}
+
+// runBulk applies the confirmed action to the marked tasks in one backend
+// call, journaled as a single undo step
+func (m *ReviewModel) runBulk() tea.Cmd {
+	ctx := m.ctx
+	bulk := m.bulk
+	uuids := m.markedUUIDs()
+	verb := fmt.Sprintf("%s (%d tasks)", bulk.label(), len(uuids))
+	return func() tea.Msg {
+		entry, err := m.journal.recordID(ctx, m.backend, verb, true, uuids, func() error {
+			return bulk.run(ctx, uuids)
+		})
+		if err != nil {
+			return errorMsg{err}
+		}
+		message := fmt.Sprintf("%s applied to %d tasks.", bulk.label(), len(uuids))
+		return bulkCompletedMsg{message: message, uuids: uuids, entry: entry}
+	}
+}
+
+// finishBulk drops the tasks a bulk action handled from the rest of the
+// review, moving on if it included the current task. Dropped tasks shrink the
+// review rather than counting as reviewed, so progress stays within the total.
+func (m *ReviewModel) finishBulk(msg bulkCompletedMsg) (tea.Model, tea.Cmd) {
+	m.marked = nil
+	m.markList = nil
+	m.bulk = nil
+	if m.current < len(m.tasks) && slices.Contains(msg.uuids, m.tasks[m.current]) {
+		return m.Update(actionCompletedMsg{message: msg.message, covered: msg.uuids, entry: msg.entry})
+	}
+
+	m.mode = ModeViewing
+	m.message = msg.message
+	m.journal.settle(msg.entry, 0, m.dropUpcoming(msg.uuids))
+	return m, m.progressBar.SetPercent(float64(m.reviewed) / float64(len(m.tasks)))
+}
+
+// renderMarking renders the list of tasks to mark
+func (m *ReviewModel) renderMarking() string {
+	listStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("4")). // ANSI blue
+		Padding(1, 2).
+		Margin(1, 2)
+	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("6")).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+
+	var content strings.Builder
+	content.WriteString(lipgloss.NewStyle().Bold(true).Render(fmt.Sprintf("Mark tasks (%d marked)", len(m.marked))))
+	content.WriteString("\n\n")
+
+	if m.markList == nil {
+		content.WriteString("Loading tasks...\n")
+	}
+
+	// Show a window of the list around the cursor
+	rows := max(5, m.height-14)
+	start := max(0, min(m.markCursor-rows/2, len(m.markList)-rows))
+	end := min(len(m.markList), start+rows)
+	for i := start; i < end; i++ {
+		td := m.markList[i]
+		box := "[ ]"
+		if m.marked[td.UUID] {
+			box = "[x]"
+		}
+		line := box + " " + td.Description
+		if td.Project != "" {
+			line += dimStyle.Render("  " + td.Project)
+		}
+		if i == m.markCursor {
+			content.WriteString(selectedStyle.Render("> ") + line)
+		} else {
+			content.WriteString("  " + line)
+		}
+		content.WriteString("\n")
+	}
+
+	content.WriteString("\nspace: mark  r/c/d: reviewed/complete/delete  m/w/u: modify/wait/due  V/ESC: done")
+	return listStyle.Render(content.String())
+}
+
+// renderBulkConfirm renders the summary of a bulk action before it runs
+func (m *ReviewModel) renderBulkConfirm() string {
+	confirmStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("3")). // ANSI yellow
+		Padding(1, 2).
+		Margin(2, 4)
+
+	var content strings.Builder
+	content.WriteString(m.message)
+	content.WriteString("\n\n")
+	const shown = 10
+	uuids := m.markedUUIDs()
+	for _, td := range m.markList {
+		if !m.marked[td.UUID] || slices.Index(uuids, td.UUID) >= shown {
+			continue
+		}
+		content.WriteString("  • " + td.Description + "\n")
+	}
+	if len(uuids) > shown {
+		content.WriteString(fmt.Sprintf("  … and %d more\n", len(uuids)-shown))
+	}
+	content.WriteString("\nPress 'y' to confirm, 'n' to cancel")
+	return confirmStyle.Render(content.String())
+}
@@ -0,0 +1,168 @@
+package review
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/emiller/tasksh/internal/taskwarrior/memory"
+)
+
+func newBulkModel(t *testing.T) (*ReviewModel, *memory.Backend, []string) {
+	t.Helper()
+	backend := memory.New()
+	var uuids []string
+	for _, desc := range []string{"Buy milk", "Call plumber", "Renew passport", "Book dentist"} {
+		uuid, err := backend.Add(desc)
+		if err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+		uuids = append(uuids, uuid)
+	}
+	model := NewReviewModelWithBackend(backend)
+	model.SetTasks(slices.Clone(uuids), len(uuids))
+	model.Update(tea.WindowSizeMsg{Width: 100, Height: 40})
+	model.Update(model.loadCurrentTask()())
+	return model, backend, uuids
+}
+
+func TestBulkComplete(t *testing.T) {
+	model, backend, uuids := newBulkModel(t)
+	press := func(msg tea.KeyMsg) tea.Cmd {
+		_, cmd := model.Update(msg)
+		return cmd
+	}
+	runes := func(keys string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(keys)} }
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+
+	model.Update(press(runes("V"))())
+	if model.mode != ModeMarking || len(model.markList) != 4 {
+		t.Fatalf("Expected the marking list, got mode %v with %d tasks", model.mode, len(model.markList))
+	}
+
+	// Nothing happens until tasks are marked
+	press(runes("c"))
+	if model.mode != ModeMarking {
+		t.Fatalf("Expected to stay in marking mode, got %v", model.mode)
+	}
+
+	press(runes("j"))
+	press(space)
+	press(space)
+	press(runes("c"))
+	if model.mode != ModeBulkConfirm {
+		t.Fatalf("Expected the confirmation, got mode %v", model.mode)
+	}
+	view := model.View()
+	if !strings.Contains(view, "Complete on 2 tasks?") || !strings.Contains(view, "Call plumber") || strings.Contains(view, "• Buy milk") {
+		t.Errorf("Unexpected confirmation:\n%s", view)
+	}
+
+	model.Update(press(runes("y"))())
+	for i, want := range []string{"pending", "completed", "completed", "pending"} {
+		if task, _ := backend.GetTaskInfo(t.Context(), uuids[i]); task.Status != want {
+			t.Errorf("%s is %s, want %s", task.Description, task.Status, want)
+		}
+	}
+	// Later tasks leave the review instead of counting as reviewed
+	if !slices.Equal(model.tasks, []string{uuids[0], uuids[3]}) || model.current != 0 || model.reviewed != 0 {
+		t.Errorf("Expected the batch dropped from the review, got %v at %d with %d reviewed", model.tasks, model.current, model.reviewed)
+	}
+
+	// The batch is one undo step
+	if entries := model.journal.history(); len(entries) != 1 || len(entries[0].changes) != 2 {
+		t.Fatalf("Expected one journal entry for the batch, got %d", len(entries))
+	}
+	model.Update(model.undoLastAction()())
+	for _, uuid := range uuids {
+		if task, _ := backend.GetTaskInfo(t.Context(), uuid); task.Status != "pending" {
+			t.Errorf("Undo left %s %s", task.Description, task.Status)
+		}
+	}
+	if !slices.Equal(model.tasks, uuids) || model.total != 4 || model.current != 0 || model.reviewed != 0 {
+		t.Errorf("Expected the batch back in the review, got %v (total %d) at %d with %d reviewed", model.tasks, model.total, model.current, model.reviewed)
+	}
+}
+
+func TestBulkUndoIncludingCurrent(t *testing.T) {
+	model, _, uuids := newBulkModel(t)
+	press := func(msg tea.KeyMsg) tea.Cmd {
+		_, cmd := model.Update(msg)
+		return cmd
+	}
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+
+	model.Update(press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("V")})())
+	for range 3 {
+		press(space)
+	}
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	model.Update(press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})())
+
+	// The current task counts once; the rest of the batch leaves the review
+	if !slices.Equal(model.tasks, []string{uuids[0], uuids[3]}) || model.total != 2 || model.current != 1 || model.reviewed != 1 {
+		t.Fatalf("Expected to move past the batch, got %v (total %d) at %d with %d reviewed", model.tasks, model.total, model.current, model.reviewed)
+	}
+	if percent := model.progressBar.Percent(); percent > 1 {
+		t.Errorf("Expected progress within the review, got %.2f", percent)
+	}
+
+	model.Update(model.undoLastAction()())
+	if !slices.Equal(model.tasks, uuids) || model.total != 4 || model.current != 0 || model.reviewed != 0 {
+		t.Errorf("Expected the review restored, got %v (total %d) at %d with %d reviewed", model.tasks, model.total, model.current, model.reviewed)
+	}
+}
+
+func TestBulkModifyIncludingCurrent(t *testing.T) {
+	model, backend, uuids := newBulkModel(t)
+	press := func(keys string) tea.Cmd {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(keys)}
+		switch keys {
+		case " ":
+			msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		}
+		_, cmd := model.Update(msg)
+		return cmd
+	}
+
+	model.Update(press("V")())
+	press(" ")
+	press(" ")
+	press("m")
+	if model.mode != ModeInputModification || model.bulk == nil {
+		t.Fatalf("Expected the modification prompt for the batch, got mode %v", model.mode)
+	}
+	model.textInput.SetValue("+errand project:Home")
+	press("enter")
+	if model.mode != ModeBulkConfirm || !strings.Contains(model.message, "Modify +errand project:Home on 2 tasks?") {
+		t.Fatalf("Expected the confirmation, got mode %v: %q", model.mode, model.message)
+	}
+	model.Update(press("y")())
+
+	for i, uuid := range uuids {
+		task, _ := backend.GetTaskInfo(t.Context(), uuid)
+		if modified := task.Project == "Home" && slices.Contains(task.Tags, "errand"); modified != (i < 2) {
+			t.Errorf("%s modified = %v", task.Description, modified)
+		}
+	}
+	// The current task was in the batch, so the review moves on
+	if !slices.Equal(model.tasks, []string{uuids[0], uuids[2], uuids[3]}) || model.current != 1 || model.reviewed != 1 {
+		t.Errorf("Expected to move past the batch, got %v at %d with %d reviewed", model.tasks, model.current, model.reviewed)
+	}
+
+	// A single action afterwards only touches the current task
+	model.Update(model.reviewCurrentTask()())
+	tasks, err := backend.GetTasksWithDataProgress(t.Context(), uuids[2:], nil)
+	if err != nil {
+		t.Fatalf("Failed to load tasks: %v", err)
+	}
+	for _, td := range tasks {
+		if reviewed := !td.Reviewed.IsZero(); reviewed != (td.UUID == uuids[2]) {
+			t.Errorf("%s reviewed = %v", td.Description, reviewed)
+		}
+	}
+}
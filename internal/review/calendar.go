@@ -9,6 +9,8 @@ import (
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+
+	"github.com/emiller/tasksh/internal/taskwarrior"
 )
 
 // CalendarModel represents a calendar picker component
@@ -266,9 +268,10 @@ func (m CalendarModel) GetSelectedDate() time.Time {
 	return m.selectedDate
 }
 
-// GetSelectedDateString returns the selected date as a string suitable for Taskwarrior
-func (m CalendarModel) GetSelectedDateString() string {
-	return m.selectedDate.Format("2006-01-02")
+// GetSelectedTaskDate returns the start of the selected local day as a Taskwarrior date
+func (m CalendarModel) GetSelectedTaskDate() taskwarrior.Date {
+	y, mo, d := m.selectedDate.Date()
+	return taskwarrior.NewDate(time.Date(y, mo, d, 0, 0, 0, 0, time.Local))
 }
 
 // SetFocused sets the focus state of the calendar
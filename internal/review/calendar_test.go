@@ -5,6 +5,8 @@ import (
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/emiller/tasksh/internal/taskwarrior"
 )
 
 // TestCalendarNavigation tests basic calendar navigation functionality
@@ -149,14 +151,17 @@ func TestCalendarFocusState(t *testing.T) {
 func TestCalendarDateString(t *testing.T) {
 	cal := NewCalendarModel()
 	
-	testDate := time.Date(2024, 12, 25, 0, 0, 0, 0, time.Local)
+	testDate := time.Date(2024, 12, 25, 15, 30, 0, 0, time.Local)
 	cal.selectedDate = testDate
 	
 	expected := "2024-12-25"
-	actual := cal.GetSelectedDateString()
+	actual := cal.GetSelectedTaskDate()
 	
-	if actual != expected {
-		t.Errorf("Date string formatting failed. Expected %s, got %s", expected, actual)
+	if actual.LocalString() != expected {
+		t.Errorf("Date string formatting failed. Expected %s, got %s", expected, actual.LocalString())
+	}
+	if !actual.Equal(time.Date(2024, 12, 25, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("Expected start of the selected day, got %s", actual)
 	}
 }
 
@@ -247,8 +252,8 @@ func TestReviewModelCalendarConfirm(t *testing.T) {
 		t.Errorf("Expected mode %v, got %v", ModeInputWaitReason, updatedModel.mode)
 	}
 	
-	// Wait date should be set
-	expected := "2024-12-25"
+	// Wait date should be set to the start of the selected day
+	expected := taskwarrior.NewDate(testDate).String()
 	if updatedModel.waitDate != expected {
 		t.Errorf("Expected waitDate %s, got %s", expected, updatedModel.waitDate)
 	}
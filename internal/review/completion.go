@@ -1,6 +1,8 @@
 package review
 
 import (
+	"context"
+	"slices"
 	"sort"
 	"strings"
 
@@ -34,6 +36,7 @@ type CompletionModel struct {
 	attributes []string
 	priorities []string
 	statuses   []string
+	udas       map[string]taskwarrior.UDADefinition
 	
 	// Current completion state
 	suggestions []CompletionItem
@@ -53,19 +56,20 @@ func NewCompletionModel() *CompletionModel {
 		statuses:   []string{"pending", "completed", "deleted", "waiting"},
 		projects:   []string{}, // Will be loaded dynamically
 		tags:       []string{}, // Will be loaded dynamically
+		udas:       map[string]taskwarrior.UDADefinition{},
 	}
 }
 
-// LoadDynamicData fetches current projects and tags from taskwarrior
-func (c *CompletionModel) LoadDynamicData() error {
+// LoadDynamicData fetches current projects, tags and UDAs from the task backend
+func (c *CompletionModel) LoadDynamicData(ctx context.Context, backend taskwarrior.TaskBackend) error {
 	// Get projects
-	projects, err := taskwarrior.GetProjects()
+	projects, err := backend.GetProjects(ctx)
 	if err == nil {
 		c.projects = projects
 	}
 	
 	// Get tags (these come with + prefix, so we need to clean them)
-	tagsWithPrefix, err := taskwarrior.GetTags()
+	tagsWithPrefix, err := backend.GetTags(ctx)
 	if err == nil {
 		c.tags = make([]string, 0, len(tagsWithPrefix))
 		for _, tag := range tagsWithPrefix {
@@ -76,6 +80,17 @@ func (c *CompletionModel) LoadDynamicData() error {
 			}
 		}
 	}
+
+	// Get UDA definitions so their names and allowed values complete
+	udas, err := backend.GetUDAs(ctx)
+	if err == nil {
+		for _, uda := range udas {
+			c.udas[uda.Name] = uda
+			if !slices.Contains(c.attributes, uda.Name) {
+				c.attributes = append(c.attributes, uda.Name)
+			}
+		}
+	}
 	
 	return nil
 }
@@ -222,23 +237,49 @@ func (c *CompletionModel) addValueSuggestions(attribute, valuePrefix string) {
 			}
 		}
 	case "due", "wait", "scheduled", "until":
-		// Date suggestions
-		dateSuggestions := []CompletionItem{
-			{"today", "Today", CompletionValue},
-			{"tomorrow", "Tomorrow", CompletionValue},
-			{"next week", "Next week", CompletionValue},
-			{"next month", "Next month", CompletionValue},
-			{"eom", "End of month", CompletionValue},
-			{"eoy", "End of year", CompletionValue},
+		c.addDateSuggestions(valuePrefix)
+	default:
+		uda, ok := c.udas[attribute]
+		if !ok {
+			return
+		}
+		if uda.Type == "date" {
+			c.addDateSuggestions(valuePrefix)
+			return
 		}
-		for _, suggestion := range dateSuggestions {
-			if strings.HasPrefix(suggestion.Text, valuePrefix) {
-				c.suggestions = append(c.suggestions, suggestion)
+		for _, value := range uda.Values {
+			if value != "" && strings.HasPrefix(value, valuePrefix) {
+				label := uda.Label
+				if label == "" {
+					label = uda.Name
+				}
+				c.suggestions = append(c.suggestions, CompletionItem{
+					Text:        value,
+					Description: label + ": " + value,
+					Type:        CompletionValue,
+				})
 			}
 		}
 	}
 }
 
+// addDateSuggestions adds suggestions for date attributes
+func (c *CompletionModel) addDateSuggestions(valuePrefix string) {
+	dateSuggestions := []CompletionItem{
+		{"today", "Today", CompletionValue},
+		{"tomorrow", "Tomorrow", CompletionValue},
+		{"next week", "Next week", CompletionValue},
+		{"next month", "Next month", CompletionValue},
+		{"eom", "End of month", CompletionValue},
+		{"eoy", "End of year", CompletionValue},
+	}
+	for _, suggestion := range dateSuggestions {
+		if strings.HasPrefix(suggestion.Text, valuePrefix) {
+			c.suggestions = append(c.suggestions, suggestion)
+		}
+	}
+}
+
 // addOtherSuggestions adds other common modification patterns
 func (c *CompletionModel) addOtherSuggestions(prefix string) {
 	others := []string{
@@ -0,0 +1,149 @@
+package review
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/emiller/tasksh/internal/taskwarrior"
+	"github.com/emiller/tasksh/internal/taskwarrior/filter"
+)
+
+// taskDependencies are the tasks a task is blocked by and the open tasks it
+// is blocking
+type taskDependencies struct {
+	uuid      string
+	blockedBy []*taskwarrior.TaskData
+	blocking  []*taskwarrior.TaskData
+}
+
+// all returns the blockers then the blocked tasks, numbered in this order
+// for jumping
+func (d *taskDependencies) all() []*taskwarrior.TaskData {
+	return slices.Concat(d.blockedBy, d.blocking)
+}
+
+type dependenciesLoadedMsg struct {
+	deps *taskDependencies
+}
+
+// loadDependencies loads the current task's blockers and the open tasks
+// that depend on it. Failures leave the viewport showing the depends count.
+func (m *ReviewModel) loadDependencies() tea.Cmd {
+	task := m.currentTask
+	if task == nil {
+		return nil
+	}
+	ctx := m.ctx
+	return func() tea.Msg {
+		deps := &taskDependencies{uuid: task.UUID}
+		if len(task.Depends) > 0 {
+			blockers, err := m.backend.GetTasksWithDataProgress(ctx, task.Depends, nil)
+			if err != nil {
+				return dependenciesLoadedMsg{}
+			}
+			deps.blockedBy = blockers
+		}
+
+		query := filter.And(
+			filter.Attr("depends").Has(task.UUID),
+			filter.Or(filter.HasTag("PENDING"), filter.HasTag("WAITING")),
+		)
+		uuids, err := m.backend.FilterUUIDs(ctx, query.Args())
+		if err != nil {
+			return dependenciesLoadedMsg{}
+		}
+		if len(uuids) > 0 {
+			blocking, err := m.backend.GetTasksWithDataProgress(ctx, uuids, nil)
+			if err != nil {
+				return dependenciesLoadedMsg{}
+			}
+			deps.blocking = blocking
+		}
+		return dependenciesLoadedMsg{deps: deps}
+	}
+}
+
+// renderDependencies writes the numbered blocked-by and blocking lists
+func (m *ReviewModel) renderDependencies(content *strings.Builder, labelStyle func(...string) string) {
+	deps := m.deps
+	if deps == nil || deps.uuid != m.currentTask.UUID {
+		if len(m.currentTask.Depends) > 0 {
+			content.WriteString(labelStyle("Depends: "))
+			content.WriteString(fmt.Sprintf("%d task(s)", len(m.currentTask.Depends)))
+			content.WriteString("\n")
+		}
+		return
+	}
+
+	n := 1
+	for _, section := range []struct {
+		label string
+		tasks []*taskwarrior.TaskData
+	}{
+		{"Blocked by:", deps.blockedBy},
+		{"Blocking:", deps.blocking},
+	} {
+		if len(section.tasks) == 0 {
+			continue
+		}
+		content.WriteString(labelStyle(section.label))
+		content.WriteString("\n")
+		for _, td := range section.tasks {
+			content.WriteString(fmt.Sprintf("  %d. %s\n", n, describeDependency(td)))
+			n++
+		}
+	}
+}
+
+// describeDependency renders a task in the dependency lists
+func describeDependency(td *taskwarrior.TaskData) string {
+	task := td.ToTask()
+	description := task.GetShortDescription(50)
+	if !taskwarrior.IsOpen(td) {
+		return fmt.Sprintf("%s (%s)", description, td.Status)
+	}
+	if td.ID > 0 {
+		return fmt.Sprintf("%s [%d]", description, td.ID)
+	}
+	return description
+}
+
+// jumpToDependency moves to the nth task in the dependency lists. A task
+// that is not part of this review is added right after the current one.
+func (m *ReviewModel) jumpToDependency(n int) tea.Cmd {
+	if m.deps == nil || m.currentTask == nil || m.deps.uuid != m.currentTask.UUID {
+		return nil
+	}
+	list := m.deps.all()
+	if n < 1 || n > len(list) {
+		return nil
+	}
+	td := list[n-1]
+	if !taskwarrior.IsOpen(td) {
+		m.message = fmt.Sprintf("%q is already %s.", td.Description, td.Status)
+		return nil
+	}
+
+	if i := slices.Index(m.tasks, td.UUID); i >= 0 {
+		m.current = i
+	} else {
+		m.current++
+		m.tasks = slices.Insert(m.tasks, m.current, td.UUID)
+		m.total++
+		if m.lazyLoadEnabled {
+			m.totalTasks++
+			if m.current <= m.loadedTasks {
+				m.loadedTasks++
+			}
+		}
+	}
+	if m.taskCache != nil {
+		m.taskCache[td.UUID] = td.ToTask()
+	}
+
+	m.message = fmt.Sprintf("Jumped to %q.", td.Description)
+	return m.loadCurrentTask()
+}
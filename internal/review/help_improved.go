@@ -31,13 +31,13 @@ func (i ImprovedKeyMap) ShortHelp() []key.Binding {
 // FullHelp returns complete help organized by category
 func (i ImprovedKeyMap) FullHelp() [][]key.Binding {
 	// Organize by action categories for better UX
-	navigation := []key.Binding{i.NextTask, i.PrevTask}
+	navigation := []key.Binding{i.NextTask, i.PrevTask, i.JumpDependency}
 	
-	primary := []key.Binding{i.Review, i.Complete, i.Edit}
+	primary := []key.Binding{i.Review, i.ReviewSeries, i.Complete, i.Edit}
 	
-	taskManagement := []key.Binding{i.Modify, i.Delete, i.Wait, i.Due, i.Skip}
+	taskManagement := []key.Binding{i.Modify, i.Annotations, i.Delete, i.Wait, i.Due, i.Skip, i.Mark, i.Timer}
 	
-	advanced := []key.Binding{i.Context, i.Undo}
+	advanced := []key.Binding{i.Context, i.Profile, i.Undo, i.History, i.Report}
 	if i.aiAvailable {
 		advanced = append(advanced, i.AIAnalysis, i.PromptAgent)
 	}
@@ -62,13 +62,13 @@ func (i ImprovedKeyMap) GetHelpCategories() []struct {
 		Label    string
 		Bindings []key.Binding
 	}{
-		{"Navigation", []key.Binding{i.NextTask, i.PrevTask}},
-		{"Primary Actions", []key.Binding{i.Review, i.Complete, i.Edit}},
-		{"Task Management", []key.Binding{i.Modify, i.Delete, i.Wait, i.Due, i.Skip}},
+		{"Navigation", []key.Binding{i.NextTask, i.PrevTask, i.JumpDependency}},
+		{"Primary Actions", []key.Binding{i.Review, i.ReviewSeries, i.Complete, i.Edit}},
+		{"Task Management", []key.Binding{i.Modify, i.Annotations, i.Delete, i.Wait, i.Due, i.Skip, i.Mark, i.Timer}},
 	}
 	
 	// Advanced features (conditional)
-	advanced := []key.Binding{i.Context, i.Undo}
+	advanced := []key.Binding{i.Context, i.Profile, i.Undo, i.History, i.Report}
 	if i.aiAvailable {
 		advanced = append(advanced, i.AIAnalysis, i.PromptAgent)
 	}
@@ -0,0 +1,304 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/emiller/tasksh/internal/taskwarrior"
+)
+
+// errNothingToUndo is returned when the journal is empty
+var errNothingToUndo = errors.New("nothing to undo in this session")
+
+// taskChange is a task's data before and after an action
+type taskChange struct {
+	before *taskwarrior.TaskData
+	after  *taskwarrior.TaskData
+}
+
+// journalEntry is one user action and the task changes it made
+type journalEntry struct {
+	id       int
+	action   string
+	at       time.Time
+	changes  []taskChange
+	reviewed int           // how much the action added to the reviewed count
+	dropped  []droppedTask // later tasks the action took out of the review
+}
+
+// describe names the action and, when it changed a single task, the task
+func (e journalEntry) describe() string {
+	if len(e.changes) != 1 {
+		return e.action
+	}
+	task := e.changes[0].before.ToTask()
+	return fmt.Sprintf("%s %q", e.action, task.GetShortDescription(40))
+}
+
+// journal keeps before/after snapshots of each action in a review session so
+// compound actions, such as wait (annotate then modify), undo as one step.
+// Actions run in tea.Cmd goroutines, so access is locked.
+type journal struct {
+	mu      sync.Mutex
+	nextID  int
+	entries []journalEntry
+	log     []journalEntry // every action, undos included, for the session report
+}
+
+// snapshot returns copies of the given tasks keyed by UUID
+func (j *journal) snapshot(ctx context.Context, backend taskwarrior.TaskBackend, uuids []string) (map[string]*taskwarrior.TaskData, error) {
+	tasks, err := backend.GetTasksWithDataProgress(ctx, uuids, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot tasks for undo: %w", err)
+	}
+	byUUID := make(map[string]*taskwarrior.TaskData, len(tasks))
+	for _, td := range tasks {
+		byUUID[td.UUID] = td
+	}
+	return byUUID, nil
+}
+
+// record runs an action on the given tasks and journals what it changed. A
+// failed action is journaled too, since it may have made some of its changes.
+func (j *journal) record(ctx context.Context, backend taskwarrior.TaskBackend, action string, reviewed bool, uuids []string, run func() error) error {
+	before, err := j.snapshot(ctx, backend, uuids)
+	if err != nil {
+		return err
+	}
+	runErr := run()
+	if err := j.commit(ctx, backend, action, reviewed, uuids, before); err != nil && runErr == nil {
+		return err
+	}
+	return runErr
+}
+
+// recordID is record, also returning the ID of the entry journaled, or 0 if
+// the action changed nothing
+func (j *journal) recordID(ctx context.Context, backend taskwarrior.TaskBackend, action string, reviewed bool, uuids []string, run func() error) (int, error) {
+	last := j.lastID()
+	err := j.record(ctx, backend, action, reviewed, uuids, run)
+	if id := j.lastID(); id != last {
+		return id, err
+	}
+	return 0, err
+}
+
+// commit journals the changes made to the given tasks since before was taken
+func (j *journal) commit(ctx context.Context, backend taskwarrior.TaskBackend, action string, reviewed bool, uuids []string, before map[string]*taskwarrior.TaskData) error {
+	// The action has already run, so read the result even if it was cancelled
+	after, err := j.snapshot(context.WithoutCancel(ctx), backend, uuids)
+	if err != nil {
+		return err
+	}
+
+	entry := journalEntry{action: action, at: time.Now()}
+	if reviewed {
+		entry.reviewed = 1
+	}
+	for _, uuid := range uuids {
+		if before[uuid] != nil && after[uuid] != nil && len(diffTasks(before[uuid], after[uuid])) > 0 {
+			entry.changes = append(entry.changes, taskChange{before: before[uuid], after: after[uuid]})
+		}
+	}
+	if len(entry.changes) == 0 {
+		return nil
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.nextID++
+	entry.id = j.nextID
+	j.entries = append(j.entries, entry)
+	j.log = append(j.log, entry)
+	return nil
+}
+
+// lastID returns the ID of the most recently journaled action, or 0
+func (j *journal) lastID() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.nextID
+}
+
+// settle records how an action changed the review once Update has applied
+// it: what it added to the reviewed count and the tasks it dropped, so undo
+// can reverse both
+func (j *journal) settle(id, reviewed int, dropped []droppedTask) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if i := slices.IndexFunc(j.entries, func(e journalEntry) bool { return e.id == id }); id != 0 && i >= 0 {
+		j.entries[i].reviewed = reviewed
+		j.entries[i].dropped = dropped
+	}
+}
+
+// undo restores the tasks changed by the most recent action. It refuses if a
+// task was changed since, so edits made outside the session are not lost.
+// The tasks are read and restored without holding the lock, so actions
+// journaled meanwhile are not held up.
+func (j *journal) undo(ctx context.Context, backend taskwarrior.TaskBackend) (journalEntry, error) {
+	j.mu.Lock()
+	if len(j.entries) == 0 {
+		j.mu.Unlock()
+		return journalEntry{}, errNothingToUndo
+	}
+	entry := j.entries[len(j.entries)-1]
+	j.mu.Unlock()
+
+	uuids := make([]string, len(entry.changes))
+	for i, change := range entry.changes {
+		uuids[i] = change.after.UUID
+	}
+	current, err := j.snapshot(ctx, backend, uuids)
+	if err != nil {
+		return journalEntry{}, err
+	}
+	for _, change := range entry.changes {
+		if td := current[change.after.UUID]; td == nil || len(diffTasks(change.after, td)) > 0 {
+			return journalEntry{}, fmt.Errorf("cannot undo %s: task changed since", entry.describe())
+		}
+	}
+
+	for _, change := range slices.Backward(entry.changes) {
+		if err := backend.RestoreTask(ctx, change.before); err != nil {
+			return journalEntry{}, fmt.Errorf("failed to undo %s: %w", entry.describe(), err)
+		}
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	// Another undo or a reset may have taken the entry off meanwhile, and
+	// actions journaled since stay undoable
+	i := slices.IndexFunc(j.entries, func(e journalEntry) bool { return e.id == entry.id })
+	if i < 0 {
+		return entry, nil
+	}
+	j.entries = slices.Delete(j.entries, i, i+1)
+
+	// The log keeps the undo as an action of its own, changing the tasks back
+	undone := journalEntry{action: "Undo " + entry.action, at: time.Now()}
+	for _, change := range slices.Backward(entry.changes) {
+		undone.changes = append(undone.changes, taskChange{before: change.after, after: change.before})
+	}
+	j.log = append(j.log, undone)
+	return entry, nil
+}
+
+// reset forgets the session's actions, undoable and logged, when switching
+// databases: the saved session and report belong to the database in use
+func (j *journal) reset() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.entries = nil
+	j.log = nil
+}
+
+// history returns the journaled actions, most recent first
+func (j *journal) history() []journalEntry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entries := slices.Clone(j.entries)
+	slices.Reverse(entries)
+	return entries
+}
+
+// actions returns every action taken in the session, undos included, oldest
+// first
+func (j *journal) actions() []journalEntry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return slices.Clone(j.log)
+}
+
+// journalAttrs are the attributes compared when describing a change; id,
+// modified and urgency change as a side effect and are left out
+var journalAttrs = []string{
+	"description", "project", "priority", "status", "due", "wait", "scheduled",
+	"until", "start", "end", "reviewed", "recur", "tags", "depends",
+}
+
+// fieldChange is an attribute that differs between two versions of a task,
+// with its values as displayed
+type fieldChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// String describes the change, e.g. "status: pending → completed". For
+// annotations, whose values are counts, it gives the difference instead.
+func (c fieldChange) String() string {
+	if c.Field == "annotations" {
+		before, _ := strconv.Atoi(c.Before)
+		after, _ := strconv.Atoi(c.After)
+		switch {
+		case after > before:
+			return fmt.Sprintf("annotations: +%d", after-before)
+		case after < before:
+			return fmt.Sprintf("annotations: %d", after-before)
+		}
+		return "annotations: edited"
+	}
+	return fmt.Sprintf("%s: %s → %s", c.Field, c.Before, c.After)
+}
+
+// diffTasks describes each attribute that differs, e.g. "status: pending → completed"
+func diffTasks(before, after *taskwarrior.TaskData) []string {
+	var diffs []string
+	for _, change := range fieldChanges(before, after) {
+		diffs = append(diffs, change.String())
+	}
+	return diffs
+}
+
+// fieldChanges returns each attribute that differs between before and after
+func fieldChanges(before, after *taskwarrior.TaskData) []fieldChange {
+	names := slices.Clone(journalAttrs)
+	for _, uda := range []map[string]string{before.UDA, after.UDA} {
+		for name := range uda {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	slices.Sort(names[len(journalAttrs):])
+
+	var changes []fieldChange
+	for _, name := range names {
+		from, to := before.Attr(name), after.Attr(name)
+		if from != to {
+			changes = append(changes, fieldChange{Field: name, Before: displayAttr(name, from), After: displayAttr(name, to)})
+		}
+	}
+	if len(before.Annotations) != len(after.Annotations) || before.Attr("annotations") != after.Attr("annotations") {
+		changes = append(changes, fieldChange{
+			Field:  "annotations",
+			Before: strconv.Itoa(len(before.Annotations)),
+			After:  strconv.Itoa(len(after.Annotations)),
+		})
+	}
+	return changes
+}
+
+// displayAttr renders an attribute value for the history panel
+func displayAttr(name, value string) string {
+	if value == "" {
+		return "none"
+	}
+	switch name {
+	case "due", "wait", "scheduled", "until", "start", "end", "reviewed", taskwarrior.WaitSinceUDA:
+		if d, err := taskwarrior.ParseDate(value); err == nil {
+			return d.LocalString()
+		}
+	case "tags":
+		return "+" + strings.ReplaceAll(value, ",", " +")
+	}
+	return value
+}
@@ -0,0 +1,262 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/emiller/tasksh/internal/taskwarrior"
+	"github.com/emiller/tasksh/internal/taskwarrior/memory"
+)
+
+func TestJournalUndoesCompoundAction(t *testing.T) {
+	ctx := t.Context()
+	backend := memory.New()
+	uuid, err := backend.Add("Renew passport", "project:Admin")
+	if err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+
+	var j journal
+	// Wait sets the wait date, tag and wait UDAs; all of them undo together
+	err = j.record(ctx, backend, "Wait", true, []string{uuid}, func() error {
+		return backend.WaitTask(ctx, uuid, "tomorrow", "photos", "Studio")
+	})
+	if err != nil {
+		t.Fatalf("record failed: %v", err)
+	}
+
+	history := j.history()
+	if len(history) != 1 || len(history[0].changes) != 1 {
+		t.Fatalf("Expected one journaled change, got %+v", history)
+	}
+	diffs := diffTasks(history[0].changes[0].before, history[0].changes[0].after)
+	if !slices.Contains(diffs, "waitreason: none → photos") || !slices.Contains(diffs, "waitingon: none → Studio") || !slices.ContainsFunc(diffs, func(d string) bool { return strings.HasPrefix(d, "wait: none → ") }) {
+		t.Errorf("Unexpected diff: %v", diffs)
+	}
+
+	entry, err := j.undo(ctx, backend)
+	if err != nil {
+		t.Fatalf("undo failed: %v", err)
+	}
+	if entry.action != "Wait" || entry.reviewed != 1 {
+		t.Errorf("Unexpected undone entry: %+v", entry)
+	}
+
+	task, err := backend.GetTaskInfo(ctx, uuid)
+	if err != nil {
+		t.Fatalf("GetTaskInfo failed: %v", err)
+	}
+	if task.Status != "pending" || !task.Wait.IsZero() || len(task.UDA) != 0 {
+		t.Errorf("Task not restored: %+v", task)
+	}
+}
+
+func TestJournalRepeatedUndo(t *testing.T) {
+	ctx := t.Context()
+	backend := memory.New()
+	uuid, _ := backend.Add("Write report")
+
+	var j journal
+	actions := []struct {
+		name string
+		run  func() error
+	}{
+		{"Modify", func() error { return backend.ModifyTask(ctx, uuid, "priority:H") }},
+		{"Complete", func() error { return backend.CompleteTask(ctx, uuid) }},
+	}
+	for _, action := range actions {
+		if err := j.record(ctx, backend, action.name, true, []string{uuid}, action.run); err != nil {
+			t.Fatalf("record %s failed: %v", action.name, err)
+		}
+	}
+
+	for _, want := range []struct{ action, status, priority string }{
+		{"Complete", "pending", "H"},
+		{"Modify", "pending", ""},
+	} {
+		entry, err := j.undo(ctx, backend)
+		if err != nil {
+			t.Fatalf("undo failed: %v", err)
+		}
+		task, _ := backend.GetTaskInfo(ctx, uuid)
+		if entry.action != want.action || task.Status != want.status || task.Priority != want.priority {
+			t.Errorf("After undoing %s: status=%s priority=%q", entry.action, task.Status, task.Priority)
+		}
+	}
+
+	if _, err := j.undo(ctx, backend); !errors.Is(err, errNothingToUndo) {
+		t.Errorf("Expected errNothingToUndo, got %v", err)
+	}
+}
+
+func TestJournalRefusesConflictingUndo(t *testing.T) {
+	ctx := t.Context()
+	backend := memory.New()
+	uuid, _ := backend.Add("Shared task")
+
+	var j journal
+	if err := j.record(ctx, backend, "Modify", true, []string{uuid}, func() error {
+		return backend.ModifyTask(ctx, uuid, "+home")
+	}); err != nil {
+		t.Fatalf("record failed: %v", err)
+	}
+
+	// A change made outside the journal, e.g. by another task client
+	if err := backend.ModifyTask(ctx, uuid, "project:Elsewhere"); err != nil {
+		t.Fatalf("ModifyTask failed: %v", err)
+	}
+
+	if _, err := j.undo(ctx, backend); err == nil || !strings.Contains(err.Error(), "changed since") {
+		t.Fatalf("Expected conflict error, got %v", err)
+	}
+	task, _ := backend.GetTaskInfo(ctx, uuid)
+	if task.Project != "Elsewhere" || !slices.Contains(task.Tags, "home") {
+		t.Errorf("Conflicting undo should leave the task alone: %+v", task)
+	}
+	if len(j.history()) != 1 {
+		t.Error("Refused undo should stay in the journal")
+	}
+}
+
+// restoreHookBackend runs onRestore before each task restore
+type restoreHookBackend struct {
+	*memory.Backend
+	onRestore func()
+}
+
+func (b restoreHookBackend) RestoreTask(ctx context.Context, td *taskwarrior.TaskData) error {
+	b.onRestore()
+	return b.Backend.RestoreTask(ctx, td)
+}
+
+func TestJournalUndoDoesNotHoldLock(t *testing.T) {
+	ctx := t.Context()
+	mem := memory.New()
+	first, _ := mem.Add("First")
+	second, _ := mem.Add("Second")
+
+	var j journal
+	if err := j.record(ctx, mem, "Modify", true, []string{first}, func() error {
+		return mem.ModifyTask(ctx, first, "priority:H")
+	}); err != nil {
+		t.Fatalf("record failed: %v", err)
+	}
+
+	// An action journaled while the undo restores its tasks
+	backend := restoreHookBackend{Backend: mem, onRestore: func() {
+		if err := j.record(ctx, mem, "Complete", true, []string{second}, func() error {
+			return mem.CompleteTask(ctx, second)
+		}); err != nil {
+			t.Errorf("record during undo failed: %v", err)
+		}
+	}}
+	done := make(chan error, 1)
+	go func() {
+		_, err := j.undo(ctx, backend)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("undo failed: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("undo held the journal lock while restoring")
+	}
+
+	history := j.history()
+	if len(history) != 1 || history[0].action != "Complete" {
+		t.Errorf("Expected only the later action left to undo, got %+v", history)
+	}
+	if task, _ := mem.GetTaskInfo(ctx, first); task.Priority != "" {
+		t.Errorf("Expected the modification undone, got priority %q", task.Priority)
+	}
+}
+
+func TestJournalSkipsNoOpActions(t *testing.T) {
+	ctx := t.Context()
+	backend := memory.New()
+	uuid, _ := backend.Add("Unchanged")
+
+	var j journal
+	if err := j.record(ctx, backend, "Nothing", false, []string{uuid}, func() error { return nil }); err != nil {
+		t.Fatalf("record failed: %v", err)
+	}
+	if len(j.history()) != 0 {
+		t.Error("Actions that change nothing should not be journaled")
+	}
+}
+
+// TestReviewModelUndo tests that undo in the review UI returns to the
+// restored task and takes it off the review progress
+func TestReviewModelUndo(t *testing.T) {
+	backend := memory.New()
+	first, _ := backend.Add("First")
+	second, _ := backend.Add("Second")
+
+	model := NewReviewModelWithBackend(backend)
+	model.SetTasks([]string{first, second}, 2)
+	model.Update(model.completeCurrentTask()())
+	if model.current != 1 || model.reviewed != 1 {
+		t.Fatalf("Expected to move on after completing, at %d with %d reviewed", model.current, model.reviewed)
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h")})
+	if model.mode != ModeHistory || !strings.Contains(model.renderHistory(), `Complete "First"`) {
+		t.Errorf("Expected history panel listing the completion, got mode %v:\n%s", model.mode, model.renderHistory())
+	}
+
+	msg := model.undoLastAction()()
+	if _, ok := msg.(undoCompletedMsg); !ok {
+		t.Fatalf("Expected undoCompletedMsg, got %#v", msg)
+	}
+	model.Update(msg)
+	if model.current != 0 || model.reviewed != 0 || model.message != `Undid Complete "First".` {
+		t.Errorf("Unexpected state after undo: current=%d reviewed=%d message=%q", model.current, model.reviewed, model.message)
+	}
+	if task, _ := backend.GetTaskInfo(t.Context(), first); task.Status != "pending" {
+		t.Errorf("Expected task restored to pending, got %s", task.Status)
+	}
+	if !strings.Contains(model.renderHistory(), "Nothing to undo") {
+		t.Error("Expected empty history after undoing the only action")
+	}
+}
+
+func TestDiffTasks(t *testing.T) {
+	before := &taskwarrior.TaskData{UUID: "u", Description: "Old", Status: "pending", Tags: []string{"work"}}
+	after := &taskwarrior.TaskData{UUID: "u", Description: "New", Status: "pending", Tags: []string{"work", "next"}, Urgency: 9}
+
+	want := []string{"description: Old → New", "tags: +work → +work +next"}
+	if got := diffTasks(before, after); !slices.Equal(got, want) {
+		t.Errorf("diffTasks() = %v, want %v", got, want)
+	}
+}
+
+func TestEditSnapshotsOffTheEventLoop(t *testing.T) {
+	backend := memory.New()
+	uuid, _ := backend.Add("Edit me")
+	model := NewReviewModelWithBackend(backend)
+	model.SetTasks([]string{uuid}, 1)
+
+	// Update only returns the command; the snapshot is taken when it runs
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	if cmd == nil {
+		t.Fatal("Expected a command from the edit key")
+	}
+	msg, ok := cmd().(editSnapshotMsg)
+	if !ok || msg.uuid != uuid || msg.before[uuid] == nil {
+		t.Fatalf("Expected the task snapshot, got %#v", msg)
+	}
+
+	// The memory backend has no editor to run
+	_, cmd = model.Update(msg)
+	if _, ok := cmd().(errorMsg); !ok {
+		t.Error("Expected an error without an edit command")
+	}
+}
@@ -0,0 +1,313 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/emiller/tasksh/internal/taskwarrior"
+	"github.com/emiller/tasksh/internal/taskwarrior/filter"
+)
+
+// taskRecurrence is the recurring series the current task belongs to
+type taskRecurrence struct {
+	uuid      string                  // task the series was loaded for
+	template  *taskwarrior.TaskData   // nil if the template is gone
+	instances []*taskwarrior.TaskData // open instances
+}
+
+// members returns the UUIDs of the template and the open instances
+func (r *taskRecurrence) members() []string {
+	var uuids []string
+	if r.template != nil {
+		uuids = append(uuids, r.template.UUID)
+	}
+	for _, td := range r.instances {
+		uuids = append(uuids, td.UUID)
+	}
+	return uuids
+}
+
+type recurrenceLoadedMsg struct {
+	recur *taskRecurrence
+}
+
+// loadSeries loads the template and the open instances of a series
+func loadSeries(ctx context.Context, backend taskwarrior.TaskBackend, series string) (*taskRecurrence, error) {
+	r := &taskRecurrence{}
+	templates, err := backend.GetTasksWithDataProgress(ctx, []string{series}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load recurring template: %w", err)
+	}
+	if len(templates) > 0 {
+		r.template = templates[0]
+	}
+
+	query := filter.And(
+		filter.Attr("parent").Eq(series),
+		filter.Or(filter.HasTag("PENDING"), filter.HasTag("WAITING")),
+	)
+	uuids, err := backend.FilterUUIDs(ctx, query.Args())
+	if err != nil {
+		return nil, fmt.Errorf("failed to find recurring instances: %w", err)
+	}
+	if len(uuids) > 0 {
+		if r.instances, err = backend.GetTasksWithDataProgress(ctx, uuids, nil); err != nil {
+			return nil, fmt.Errorf("failed to load recurring instances: %w", err)
+		}
+	}
+	return r, nil
+}
+
+// loadRecurrence loads the series of the current task if it recurs.
+// Failures leave the viewport without the series line.
+func (m *ReviewModel) loadRecurrence() tea.Cmd {
+	task := m.currentTask
+	if task == nil || task.SeriesUUID() == "" {
+		return nil
+	}
+	ctx := m.ctx
+	return func() tea.Msg {
+		r, err := loadSeries(ctx, m.backend, task.SeriesUUID())
+		if err != nil {
+			return recurrenceLoadedMsg{}
+		}
+		r.uuid = task.UUID
+		return recurrenceLoadedMsg{recur: r}
+	}
+}
+
+// upcomingInSeries returns the tasks later in this review that belong to
+// the current task's series
+func (m *ReviewModel) upcomingInSeries() []string {
+	if m.recur == nil || m.currentTask == nil || m.recur.uuid != m.currentTask.UUID {
+		return nil
+	}
+	members := m.recur.members()
+	var upcoming []string
+	for _, uuid := range m.tasks[m.current+1:] {
+		if slices.Contains(members, uuid) {
+			upcoming = append(upcoming, uuid)
+		}
+	}
+	return upcoming
+}
+
+// renderRecurrence writes the recurrence rule with the next due date, and
+// offers to review the series once when more of it is in this review
+func (m *ReviewModel) renderRecurrence(content *strings.Builder, labelStyle func(...string) string) {
+	task := m.currentTask
+	if task.Recur == "" {
+		return
+	}
+	content.WriteString(labelStyle("Recur: "))
+	content.WriteString(task.Recur)
+	if next, err := task.NextRecurrence(); err == nil {
+		content.WriteString(fmt.Sprintf(", next due %s (%s)", next.LocalString(), next.DueRelative(time.Now())))
+	}
+	content.WriteString("\n")
+
+	var series string
+	switch {
+	case task.IsRecurringTemplate():
+		series = "template of a recurring task"
+	case task.IsRecurringInstance():
+		series = "instance of a recurring task"
+	default:
+		return
+	}
+	if upcoming := m.upcomingInSeries(); len(upcoming) > 0 {
+		series += fmt.Sprintf("; %d more in this review (R: review the series once)", len(upcoming))
+	}
+	content.WriteString(labelStyle("Series: "))
+	content.WriteString(series)
+	content.WriteString("\n")
+}
+
+// reviewSeries marks the template and every open instance of the current
+// task's series reviewed, and drops the rest of the series from this review
+func (m *ReviewModel) reviewSeries() tea.Cmd {
+	if m.currentTask == nil || m.currentTask.SeriesUUID() == "" {
+		m.message = "This task doesn't recur."
+		return nil
+	}
+	ctx := m.ctx
+	series := m.currentTask.SeriesUUID()
+	return func() tea.Msg {
+		r, err := loadSeries(ctx, m.backend, series)
+		if err != nil {
+			return errorMsg{err}
+		}
+		uuids := r.members()
+		entry, err := m.journal.recordID(ctx, m.backend, "Review series", true, uuids, func() error {
+			for _, uuid := range uuids {
+				if err := m.backend.MarkTaskReviewed(ctx, uuid); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return errorMsg{err}
+		}
+		message := fmt.Sprintf("Marked the series reviewed (%d tasks).", len(uuids))
+		return actionCompletedMsg{message: message, covered: uuids, entry: entry}
+	}
+}
+
+// scopedAction is an action on a recurring instance waiting for the user
+// to choose whether it applies to this instance or all future ones
+type scopedAction struct {
+	verb    string
+	message string
+	action  func(ctx context.Context, uuid string) error
+}
+
+// runScopedAction runs an action that can apply to the rest of a recurring
+// series, asking first when the current task is a recurring instance
+func (m *ReviewModel) runScopedAction(verb, message string, action func(ctx context.Context, uuid string) error) tea.Cmd {
+	if m.currentTask == nil || !m.currentTask.IsRecurringInstance() {
+		return m.runAction(verb, message, action)
+	}
+	m.scoped = &scopedAction{verb: verb, message: message, action: action}
+	m.mode = ModeRecurScope
+	m.message = fmt.Sprintf("%s a recurring task: apply to this instance or all future ones?", verb)
+	return nil
+}
+
+// updateRecurScope handles the this instance / all future prompt
+func (m *ReviewModel) updateRecurScope(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	scoped := m.scoped
+	switch {
+	case key.Matches(msg, m.keys.ThisInstance):
+		m.mode = ModeViewing
+		m.message = ""
+		m.scoped = nil
+		return m, m.runAction(scoped.verb, scoped.message, scoped.action)
+
+	case key.Matches(msg, m.keys.AllFuture):
+		m.mode = ModeViewing
+		m.message = ""
+		m.scoped = nil
+		return m, m.runFutureAction(scoped)
+
+	case key.Matches(msg, m.keys.Cancel):
+		m.mode = ModeViewing
+		m.message = ""
+		m.scoped = nil
+	}
+	return m, nil
+}
+
+// runFutureAction runs an action on the current instance, the open
+// instances due after it and the template, so that instances generated
+// later follow it too
+func (m *ReviewModel) runFutureAction(scoped *scopedAction) tea.Cmd {
+	ctx := m.ctx
+	current := m.currentTask
+	return func() tea.Msg {
+		r, err := loadSeries(ctx, m.backend, current.Parent)
+		if err != nil {
+			return errorMsg{err}
+		}
+		uuids := []string{current.UUID}
+		for _, td := range r.instances {
+			if td.UUID != current.UUID && !td.Due.Before(current.Due.Time) {
+				uuids = append(uuids, td.UUID)
+			}
+		}
+		if r.template != nil {
+			uuids = append(uuids, r.template.UUID)
+		}
+
+		verb := scoped.verb + " all future"
+		entry, err := m.journal.recordID(ctx, m.backend, verb, true, uuids, func() error {
+			for _, uuid := range uuids {
+				if err := scoped.action(ctx, uuid); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return errorMsg{err}
+		}
+		message := fmt.Sprintf("%s Applied to %d tasks in the series.", scoped.message, len(uuids))
+		return actionCompletedMsg{message: message, covered: uuids[1:], entry: entry}
+	}
+}
+
+// renderRecurScope renders the this instance / all future prompt
+func (m *ReviewModel) renderRecurScope() string {
+	scopeStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("3")). // ANSI yellow
+		Padding(1, 2).
+		Margin(2, 4)
+
+	content := fmt.Sprintf("%s\n\n%s",
+		m.message,
+		"Press 't' for this instance, 'a' for all future, 'n' to cancel")
+
+	return scopeStyle.Render(content)
+}
+
+// droppedTask is a task dropUpcoming took out of the review, with where it was
+type droppedTask struct {
+	uuid   string
+	index  int
+	loaded bool
+}
+
+// dropUpcoming removes tasks an action already covered from the part of the
+// review still ahead, returning them so undo can put them back
+func (m *ReviewModel) dropUpcoming(uuids []string) []droppedTask {
+	if len(uuids) == 0 {
+		return nil
+	}
+	var dropped []droppedTask
+	kept := slices.Clone(m.tasks[:m.current+1])
+	for i := m.current + 1; i < len(m.tasks); i++ {
+		if !slices.Contains(uuids, m.tasks[i]) {
+			kept = append(kept, m.tasks[i])
+			continue
+		}
+		// Unloaded tasks are at the end
+		dropped = append(dropped, droppedTask{uuid: m.tasks[i], index: i, loaded: i < m.loadedTasks})
+	}
+	if len(dropped) == 0 {
+		return nil
+	}
+
+	if m.lazyLoadEnabled {
+		for _, d := range dropped {
+			if d.loaded {
+				m.loadedTasks--
+			}
+		}
+		m.totalTasks -= len(dropped)
+	}
+	m.tasks = kept
+	m.total -= len(dropped)
+	return dropped
+}
+
+// restoreUpcoming puts tasks dropUpcoming took out back where they were
+func (m *ReviewModel) restoreUpcoming(dropped []droppedTask) {
+	for _, d := range dropped {
+		m.tasks = slices.Insert(m.tasks, min(d.index, len(m.tasks)), d.uuid)
+		m.total++
+		if m.lazyLoadEnabled {
+			m.totalTasks++
+			if d.loaded {
+				m.loadedTasks++
+			}
+		}
+	}
+}
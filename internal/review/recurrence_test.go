@@ -0,0 +1,134 @@
+package review
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/emiller/tasksh/internal/taskwarrior/memory"
+)
+
+// seriesExport is a weekly template with three generated instances and an
+// unrelated task
+const seriesExport = `[
+	{"uuid":"tmpl","description":"Water plants","status":"recurring","recur":"weekly","mask":"+++","due":"20300107T090000Z","entry":"20291201T000000Z"},
+	{"uuid":"week1","description":"Water plants","status":"pending","recur":"weekly","parent":"tmpl","imask":0,"due":"20300107T090000Z","entry":"20291201T000000Z"},
+	{"uuid":"week2","description":"Water plants","status":"pending","recur":"weekly","parent":"tmpl","imask":1,"due":"20300114T090000Z","entry":"20291201T000000Z"},
+	{"uuid":"week3","description":"Water plants","status":"pending","recur":"weekly","parent":"tmpl","imask":2,"due":"20300121T090000Z","entry":"20291201T000000Z"},
+	{"uuid":"other","description":"File taxes","status":"pending","entry":"20291201T000000Z"}
+]`
+
+func newSeriesModel(t *testing.T, order ...string) (*ReviewModel, *memory.Backend) {
+	t.Helper()
+	backend := memory.New()
+	if err := backend.Load(strings.NewReader(seriesExport)); err != nil {
+		t.Fatalf("Failed to load tasks: %v", err)
+	}
+	model := NewReviewModelWithBackend(backend)
+	model.SetTasks(order, len(order))
+	model.Update(model.loadCurrentTask()())
+	model.Update(model.loadRecurrence()())
+	return model, backend
+}
+
+func TestReviewSeriesOnce(t *testing.T) {
+	model, backend := newSeriesModel(t, "week1", "other", "week2", "week3", "tmpl")
+
+	var content strings.Builder
+	model.renderRecurrence(&content, func(s ...string) string { return strings.Join(s, "") })
+	if !strings.Contains(content.String(), "Recur: weekly, next due") ||
+		!strings.Contains(content.String(), "Series: instance of a recurring task; 3 more in this review") {
+		t.Errorf("Unexpected recurrence details:\n%s", content.String())
+	}
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("R")})
+	model.Update(cmd())
+	if strings.Join(model.tasks, ",") != "week1,other" || model.total != 2 {
+		t.Fatalf("Expected the rest of the series dropped, got %v (total %d)", model.tasks, model.total)
+	}
+	if model.current != 1 || model.reviewed != 1 {
+		t.Errorf("Expected to move on to the next task, at %d with %d reviewed", model.current, model.reviewed)
+	}
+	tasks, err := backend.GetTasksWithDataProgress(t.Context(), []string{"tmpl", "week1", "week2", "week3", "other"}, nil)
+	if err != nil {
+		t.Fatalf("Failed to load tasks: %v", err)
+	}
+	for _, td := range tasks {
+		if reviewed := !td.Reviewed.IsZero(); reviewed != (td.UUID != "other") {
+			t.Errorf("%s reviewed = %v", td.UUID, reviewed)
+		}
+	}
+}
+
+func TestRecurringActionScope(t *testing.T) {
+	model, backend := newSeriesModel(t, "week2", "other")
+
+	cmd := model.modifyCurrentTask("priority:H")
+	if cmd != nil || model.mode != ModeRecurScope {
+		t.Fatalf("Expected the scope prompt before modifying an instance, got mode %v", model.mode)
+	}
+	_, cmd = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	model.Update(cmd())
+
+	for uuid, want := range map[string]string{"week1": "", "week2": "H", "week3": "H", "tmpl": "H"} {
+		task, err := backend.GetTaskInfo(t.Context(), uuid)
+		if err != nil {
+			t.Fatalf("Failed to get %s: %v", uuid, err)
+		}
+		if task.Priority != want {
+			t.Errorf("%s priority = %q, want %q", uuid, task.Priority, want)
+		}
+	}
+	if model.current != 1 || !strings.Contains(model.message, "Applied to 3 tasks") {
+		t.Errorf("Expected to move on after the series change, at %d: %q", model.current, model.message)
+	}
+
+	// This instance leaves the rest of the series alone
+	model, backend = newSeriesModel(t, "week3")
+	model.deleteCurrentTask()
+	_, cmd = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	model.Update(cmd())
+	if task, _ := backend.GetTaskInfo(t.Context(), "week3"); task.Status != "deleted" {
+		t.Errorf("Expected the instance deleted, got %s", task.Status)
+	}
+	if task, _ := backend.GetTaskInfo(t.Context(), "tmpl"); task.Status != "recurring" {
+		t.Errorf("Expected the template kept, got %s", task.Status)
+	}
+}
+
+func TestRecurringDateScope(t *testing.T) {
+	// Waiting on this instance leaves the rest of the series alone
+	model, backend := newSeriesModel(t, "week2", "other")
+	if cmd := model.waitCurrentTask("2030-01-10", "", ""); cmd != nil || model.mode != ModeRecurScope {
+		t.Fatalf("Expected the scope prompt before waiting on an instance, got mode %v", model.mode)
+	}
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	model.Update(cmd())
+	for uuid, want := range map[string]bool{"week1": false, "week2": true, "week3": false, "tmpl": false} {
+		task, err := backend.GetTaskInfo(t.Context(), uuid)
+		if err != nil {
+			t.Fatalf("Failed to get %s: %v", uuid, err)
+		}
+		if waiting := !task.Wait.IsZero(); waiting != want {
+			t.Errorf("%s waiting = %v, want %v", uuid, waiting, want)
+		}
+	}
+
+	// A new due date for all future instances moves the template too
+	model, backend = newSeriesModel(t, "week2", "other")
+	if cmd := model.dueCurrentTask("2031-01-01"); cmd != nil || model.mode != ModeRecurScope {
+		t.Fatalf("Expected the scope prompt before setting an instance's due date, got mode %v", model.mode)
+	}
+	_, cmd = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	model.Update(cmd())
+	for uuid, moved := range map[string]bool{"week1": false, "week2": true, "week3": true, "tmpl": true} {
+		task, err := backend.GetTaskInfo(t.Context(), uuid)
+		if err != nil {
+			t.Fatalf("Failed to get %s: %v", uuid, err)
+		}
+		if got := task.Due.Local().Format("2006-01-02") == "2031-01-01"; got != moved {
+			t.Errorf("%s due = %s, want moved = %v", uuid, task.Due.Local(), moved)
+		}
+	}
+}
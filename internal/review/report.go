@@ -0,0 +1,250 @@
+package review
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/emiller/tasksh/internal/taskwarrior"
+	"github.com/emiller/tasksh/internal/timedb"
+)
+
+// Outcomes of a task in a review report, in the order the report lists them
+const (
+	outcomeCompleted = "completed"
+	outcomeDeleted   = "deleted"
+	outcomeDeferred  = "deferred"
+	outcomeModified  = "modified"
+	outcomeReviewed  = "reviewed"
+)
+
+var reportOutcomes = []string{outcomeCompleted, outcomeDeleted, outcomeDeferred, outcomeModified, outcomeReviewed}
+
+// reportCounts counts the tasks a review changed by outcome
+type reportCounts struct {
+	Completed int `json:"completed"`
+	Deleted   int `json:"deleted"`
+	Deferred  int `json:"deferred"`
+	Modified  int `json:"modified"`
+}
+
+// reportTask is a task the review changed and its net change over the session
+type reportTask struct {
+	UUID        string        `json:"uuid"`
+	Description string        `json:"description"`
+	Outcome     string        `json:"outcome"`
+	Actions     []string      `json:"actions"` // oldest first
+	Changes     []fieldChange `json:"changes"`
+}
+
+// sessionReport summarises a review session for `review --report`
+type sessionReport struct {
+	Queue          string       `json:"queue"`
+	Date           time.Time    `json:"date"`
+	ElapsedSeconds int64        `json:"elapsed_seconds"`
+	Reviewed       int          `json:"reviewed"`
+	Skipped        int          `json:"skipped"`
+	Counts         reportCounts `json:"counts"`
+	Tasks          []reportTask `json:"tasks"`
+}
+
+type reportWrittenMsg struct {
+	path string
+}
+
+// report summarises the review so far
+func (m *ReviewModel) report(now time.Time) sessionReport {
+	return buildReport(m.queue.Label(), m.sessionActions(), m.reviewed, len(m.skipped), m.elapsed+time.Since(m.started), now)
+}
+
+// buildReport works out what the actions in a review did to each task, from
+// the task's state before its first action to its state after the last
+func buildReport(queue string, actions []timedb.SessionAction, reviewed, skipped int, elapsed time.Duration, now time.Time) sessionReport {
+	r := sessionReport{
+		Queue:          queue,
+		Date:           now,
+		ElapsedSeconds: int64(elapsed.Round(time.Second) / time.Second),
+		Reviewed:       reviewed,
+		Skipped:        skipped,
+	}
+
+	var order []string
+	first := make(map[string]*taskwarrior.TaskData)
+	last := make(map[string]*taskwarrior.TaskData)
+	taken := make(map[string][]string)
+	for _, action := range actions {
+		for _, change := range action.Changes {
+			uuid := change.Before.UUID
+			if first[uuid] == nil {
+				order = append(order, uuid)
+				first[uuid] = change.Before
+			}
+			last[uuid] = change.After
+			taken[uuid] = append(taken[uuid], action.Action)
+		}
+	}
+
+	for _, uuid := range order {
+		before, after := first[uuid], last[uuid]
+		changes := fieldChanges(before, after)
+		if len(changes) == 0 {
+			continue // Everything done to it was undone
+		}
+		task := reportTask{
+			UUID:        uuid,
+			Description: after.Description,
+			Outcome:     taskOutcome(before, after),
+			Actions:     taken[uuid],
+		}
+		// The review date changes on every reviewed task, so it is left out
+		for _, change := range changes {
+			if change.Field != "reviewed" {
+				task.Changes = append(task.Changes, change)
+			}
+		}
+		r.Tasks = append(r.Tasks, task)
+
+		switch task.Outcome {
+		case outcomeCompleted:
+			r.Counts.Completed++
+		case outcomeDeleted:
+			r.Counts.Deleted++
+		case outcomeDeferred:
+			r.Counts.Deferred++
+		case outcomeModified:
+			r.Counts.Modified++
+		}
+	}
+	return r
+}
+
+// taskOutcome sorts a task's net change into one outcome. A task is deferred
+// when its wait, scheduled or due date moved later.
+func taskOutcome(before, after *taskwarrior.TaskData) string {
+	switch {
+	case after.Status == "completed" && before.Status != "completed":
+		return outcomeCompleted
+	case after.Status == "deleted" && before.Status != "deleted":
+		return outcomeDeleted
+	case movedLater(before.Wait, after.Wait, true) || movedLater(before.Scheduled, after.Scheduled, true) || movedLater(before.Due, after.Due, false):
+		return outcomeDeferred
+	}
+	for _, change := range fieldChanges(before, after) {
+		if change.Field != "reviewed" {
+			return outcomeModified
+		}
+	}
+	return outcomeReviewed
+}
+
+// movedLater reports whether a date was pushed later, or set when fromUnset
+// counts, as it does for wait and scheduled but not due
+func movedLater(before, after taskwarrior.Date, fromUnset bool) bool {
+	if after.IsZero() {
+		return false
+	}
+	if before.IsZero() {
+		return fromUnset
+	}
+	return after.After(before.Time)
+}
+
+// JSON renders the report as indented JSON
+func (r sessionReport) JSON() ([]byte, error) {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode review report: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// Markdown renders the report with a section per outcome
+func (r sessionReport) Markdown() []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Review report: %s\n\n", r.Queue)
+	fmt.Fprintf(&b, "%s · %d reviewed · %d skipped · %s spent\n\n",
+		r.Date.Local().Format("Jan 2, 2006 15:04"), r.Reviewed, r.Skipped, time.Duration(r.ElapsedSeconds)*time.Second)
+	b.WriteString("| Completed | Deleted | Deferred | Modified |\n")
+	b.WriteString("| --- | --- | --- | --- |\n")
+	fmt.Fprintf(&b, "| %d | %d | %d | %d |\n", r.Counts.Completed, r.Counts.Deleted, r.Counts.Deferred, r.Counts.Modified)
+
+	for _, outcome := range reportOutcomes {
+		var tasks []reportTask
+		for _, task := range r.Tasks {
+			if task.Outcome == outcome {
+				tasks = append(tasks, task)
+			}
+		}
+		if len(tasks) == 0 {
+			continue
+		}
+
+		if outcome == outcomeReviewed {
+			b.WriteString("\n## Reviewed without changes\n\n")
+			for _, task := range tasks {
+				fmt.Fprintf(&b, "- %s\n", task.Description)
+			}
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %s%s\n", strings.ToUpper(outcome[:1]), outcome[1:])
+		for _, task := range tasks {
+			fmt.Fprintf(&b, "\n### %s\n\n", task.Description)
+			fmt.Fprintf(&b, "Actions: %s\n\n", strings.Join(task.Actions, ", "))
+			for _, change := range task.Changes {
+				fmt.Fprintf(&b, "- %s\n", change)
+			}
+		}
+	}
+	return []byte(b.String())
+}
+
+// write saves the report to path, as JSON for a .json file and Markdown
+// otherwise
+func (r sessionReport) write(path string) error {
+	data := r.Markdown()
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		var err error
+		if data, err = r.JSON(); err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write review report: %w", err)
+	}
+	return nil
+}
+
+// defaultReportPath names the report exported from the celebration screen,
+// in TASKSH_REPORT_DIR or the current directory
+func defaultReportPath(now time.Time) string {
+	name := "tasksh-review-" + now.Format("2006-01-02-1504") + ".md"
+	return filepath.Join(os.Getenv("TASKSH_REPORT_DIR"), name)
+}
+
+// exportReport writes the review's report to path
+func (m *ReviewModel) exportReport(path string) tea.Cmd {
+	r := m.report(time.Now())
+	return func() tea.Msg {
+		if err := r.write(path); err != nil {
+			return errorMsg{err}
+		}
+		return reportWrittenMsg{path: path}
+	}
+}
+
+// writeReport writes the report asked for with --report once the review ends
+func writeReport(model *ReviewModel) {
+	if model.reportPath == "" {
+		return
+	}
+	if err := model.report(time.Now()).write(model.reportPath); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+		return
+	}
+	fmt.Printf("Review report written to %s\n\n", model.reportPath)
+}
@@ -0,0 +1,138 @@
+package review
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/emiller/tasksh/internal/taskwarrior/memory"
+)
+
+func TestSessionReport(t *testing.T) {
+	now := time.Date(2024, 6, 12, 10, 0, 0, 0, time.UTC)
+	backend := memory.New()
+	backend.SetClock(func() time.Time { return now })
+	var uuids []string
+	for _, desc := range []string{"Pay rent", "Fix bike", "Plan trip", "Order printer ink", "Call mum"} {
+		uuid, err := backend.Add(desc)
+		if err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+		uuids = append(uuids, uuid)
+	}
+
+	model := NewReviewModelWithBackend(backend)
+	model.SetTasks(slices.Clone(uuids), len(uuids))
+	model.Update(model.loadCurrentTask()())
+	model.Update(model.completeCurrentTask()())
+	model.Update(model.waitCurrentTask("2024-06-20", "parts", "")())
+	model.Update(model.modifyCurrentTask("priority:H project:Travel")())
+	// An undone delete leaves the task as it was, apart from the review
+	model.Update(model.deleteCurrentTask()())
+	model.Update(model.undoLastAction()())
+	model.Update(model.reviewCurrentTask()())
+	model.Update(model.skipCurrentTask()())
+
+	r := model.report(now)
+	if r.Reviewed != 4 || r.Skipped != 1 {
+		t.Errorf("Expected 4 reviewed and 1 skipped, got %d and %d", r.Reviewed, r.Skipped)
+	}
+	if r.Counts != (reportCounts{Completed: 1, Deferred: 1, Modified: 1}) {
+		t.Errorf("Unexpected counts: %+v", r.Counts)
+	}
+	var outcomes []string
+	for _, task := range r.Tasks {
+		outcomes = append(outcomes, task.Description+" "+task.Outcome)
+	}
+	want := []string{"Pay rent completed", "Fix bike deferred", "Plan trip modified", "Order printer ink reviewed"}
+	if !slices.Equal(outcomes, want) {
+		t.Errorf("Expected outcomes %v, got %v", want, outcomes)
+	}
+	if actions := r.Tasks[3].Actions; !slices.Equal(actions, []string{"Delete", "Undo Delete", "Review"}) {
+		t.Errorf("Unexpected actions for the reviewed task: %v", actions)
+	}
+	if len(r.Tasks[3].Changes) != 0 {
+		t.Errorf("Expected no field changes for the reviewed task, got %v", r.Tasks[3].Changes)
+	}
+
+	md := string(r.Markdown())
+	for _, want := range []string{
+		"| 1 | 0 | 1 | 1 |",
+		"## Completed\n\n### Pay rent",
+		"- status: pending → completed",
+		"- waitreason: none → parts",
+		"- priority: none → H",
+		"- project: none → Travel",
+		"## Reviewed without changes\n\n- Order printer ink",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown report is missing %q:\n%s", want, md)
+		}
+	}
+
+	// The file extension picks the format
+	dir := t.TempDir()
+	path := filepath.Join(dir, "review.json")
+	if err := r.write(path); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	var decoded sessionReport
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Report is not JSON: %v", err)
+	}
+	if decoded.Counts != r.Counts || len(decoded.Tasks) != 4 || decoded.Tasks[2].Changes[0].Field != "project" {
+		t.Errorf("Unexpected JSON report: %s", data)
+	}
+
+	// The summary stays up once the confetti settles, so E still exports
+	if _, cmd := model.Update(celebrationCompleteMsg{}); cmd != nil || model.mode != ModeCelebrating || model.quitting {
+		t.Fatalf("Expected the summary kept on screen, got mode %v (quitting %v)", model.mode, model.quitting)
+	}
+	model.reportPath = filepath.Join(dir, "review.md")
+	_, export := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("E")})
+	model.Update(export())
+	if _, err := os.Stat(model.reportPath); err != nil || !strings.HasPrefix(model.message, "Report written") {
+		t.Errorf("Expected the report exported after the celebration, got %v: %q", err, model.message)
+	}
+}
+
+func TestSessionReportAfterResume(t *testing.T) {
+	backend := memory.New()
+	var uuids []string
+	for _, desc := range []string{"Pay rent", "Fix bike"} {
+		uuid, err := backend.Add(desc)
+		if err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+		uuids = append(uuids, uuid)
+	}
+
+	model := NewReviewModelWithBackend(backend)
+	model.SetTasks(slices.Clone(uuids), len(uuids))
+	model.Update(model.loadCurrentTask()())
+	model.Update(model.deleteCurrentTask()())
+	saved, err := model.sessionState(t.Context())
+	if err != nil {
+		t.Fatalf("sessionState failed: %v", err)
+	}
+
+	// Actions from before the resume keep their changes, so they are counted
+	resumed := NewReviewModelWithBackend(backend)
+	if err := resumed.resumeSession(t.Context(), &saved); err != nil {
+		t.Fatalf("resumeSession failed: %v", err)
+	}
+	resumed.Update(resumed.completeCurrentTask()())
+	if r := resumed.report(time.Now()); r.Counts != (reportCounts{Completed: 1, Deleted: 1}) {
+		t.Errorf("Unexpected counts after resume: %+v", r.Counts)
+	}
+}
@@ -1,31 +1,63 @@
 package review
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
 
 	"github.com/emiller/tasksh/internal/taskwarrior"
+	"github.com/emiller/tasksh/internal/timedb"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 // Run starts the interactive task review process
 func Run(limit int) error {
+	return RunWithBackend(context.Background(), taskwarrior.NewExecBackend(), limit)
+}
+
+// RunWithBackend starts the interactive task review process using the given
+// task backend. Cancelling ctx stops any task call in progress.
+func RunWithBackend(ctx context.Context, backend taskwarrior.TaskBackend, limit int) error {
+	return RunQueue(ctx, backend, taskwarrior.DefaultReviewQueue, limit)
+}
+
+// Options select what a review covers
+type Options struct {
+	Queue  taskwarrior.ReviewQueue
+	Limit  int  // Review at most this many tasks; 0 for all
+	Resume bool // Continue the queue's saved review instead
+	Report string // Write a session report here when the review ends
+}
+
+// RunQueue reviews the tasks due in a review queue
+func RunQueue(ctx context.Context, backend taskwarrior.TaskBackend, queue taskwarrior.ReviewQueue, limit int) error {
+	return RunWithOptions(ctx, backend, Options{Queue: queue, Limit: limit})
+}
+
+// RunWithOptions starts a review, or resumes the saved one
+func RunWithOptions(ctx context.Context, backend taskwarrior.TaskBackend, opts Options) error {
+	queue, limit := opts.Queue, opts.Limit
+
 	// Ensure review configuration is set up
-	if err := taskwarrior.EnsureReviewConfig(); err != nil {
+	if err := backend.EnsureReviewConfig(ctx, os.Stdout); err != nil {
 		return fmt.Errorf("failed to configure review: %w", err)
 	}
 
-	// Get lazy loading configuration
-	lazyLoadThreshold := 100 // default
-	if val := os.Getenv("TASKSH_LAZY_LOAD_THRESHOLD"); val != "" {
-		if threshold, err := strconv.Atoi(val); err == nil && threshold > 0 {
-			lazyLoadThreshold = threshold
-		}
+	if opts.Resume {
+		return resumeReview(ctx, backend, opts)
+	}
+	if s := savedSession(queue); s != nil {
+		fmt.Printf("An unfinished review from %s is saved; quitting this one replaces it. Use --resume to continue it instead.\n",
+			s.SavedAt.Local().Format("Jan 2 15:04"))
 	}
 
+	// Get lazy loading configuration
+	threshold := lazyLoadThreshold()
+
 	// Check if we should use lazy loading
-	uuids, err := taskwarrior.GetTasksForReview()
+	uuids, err := backend.GetTasksForQueue(ctx, queue)
 	if err != nil {
 		return fmt.Errorf("failed to get tasks for review: %w", err)
 	}
@@ -33,16 +65,16 @@ func Run(limit int) error {
 	totalTasks := len(uuids)
 	
 	// If we have many tasks, use lazy loading
-	if totalTasks > lazyLoadThreshold && limit == 0 {
-		fmt.Printf("Found %d tasks. Loading first %d for immediate review...\n", totalTasks, lazyLoadThreshold)
-		return runBubbleTeaReviewLazy(uuids, lazyLoadThreshold)
+	if totalTasks > threshold && limit == 0 {
+		fmt.Printf("Found %d tasks. Loading first %d for immediate review...\n", totalTasks, threshold)
+		return runBubbleTeaReviewLazy(ctx, backend, opts, uuids, threshold)
 	}
 
 	// Otherwise, use regular batch loading
 	fmt.Print("Loading tasks for review...")
 	
 	// Try the new batch approach first with progress
-	tasks, err := taskwarrior.GetTasksForReviewWithDataProgress(func(loaded, total int) {
+	tasks, err := backend.GetTasksWithDataProgress(ctx, uuids, func(loaded, total int) {
 		fmt.Printf("\rLoading tasks for review... %d/%d", loaded, total)
 	})
 	
@@ -56,11 +88,11 @@ func Run(limit int) error {
 			total = limit
 			tasks = tasks[:limit]
 		}
-		return runBubbleTeaReviewBatch(tasks, total)
+		return runBubbleTeaReviewBatch(ctx, backend, opts, tasks, total)
 	}
 
 	// Fall back to the old approach if batch export fails
-	uuids2, err2 := taskwarrior.GetTasksForReview()
+	uuids2, err2 := backend.GetTasksForQueue(ctx, queue)
 	if err2 != nil {
 		return fmt.Errorf("failed to get tasks for review: %w", err2)
 	}
@@ -79,21 +111,23 @@ func Run(limit int) error {
 		uuids = uuids[:limit]
 	}
 
-	return runBubbleTeaReview(uuids, total)
+	return runBubbleTeaReview(ctx, backend, opts, uuids, total)
 }
 
 // runBubbleTeaReview runs the Bubble Tea review interface
-func runBubbleTeaReview(uuids []string, total int) error {
+func runBubbleTeaReview(ctx context.Context, backend taskwarrior.TaskBackend, opts Options, uuids []string, total int) error {
 	// Show welcome message
 	showWelcomeMessage()
 
 	// Create and initialize the review model
-	model := NewReviewModel()
+	model := NewReviewModelWithContext(ctx, backend)
+	model.queue = opts.Queue
+	model.reportPath = opts.Report
 	model.SetTasks(uuids, total)
 
 	// Load the first task
 	if len(uuids) > 0 {
-		task, err := taskwarrior.GetTaskInfo(uuids[0])
+		task, err := backend.GetTaskInfo(ctx, uuids[0])
 		if err != nil {
 			return fmt.Errorf("failed to load first task: %w", err)
 		}
@@ -111,16 +145,20 @@ func runBubbleTeaReview(uuids []string, total int) error {
 		return nil
 	}
 
+	saveSession(ctx, model)
+	writeReport(model)
 	return nil
 }
 
 // runBubbleTeaReviewBatch runs the Bubble Tea review interface with pre-loaded task data
-func runBubbleTeaReviewBatch(tasks []*taskwarrior.TaskData, total int) error {
+func runBubbleTeaReviewBatch(ctx context.Context, backend taskwarrior.TaskBackend, opts Options, tasks []*taskwarrior.TaskData, total int) error {
 	// Show welcome message
 	showWelcomeMessage()
 
 	// Create and initialize the review model
-	model := NewReviewModel()
+	model := NewReviewModelWithContext(ctx, backend)
+	model.queue = opts.Queue
+	model.reportPath = opts.Report
 	
 	// Convert TaskData to Task format for compatibility
 	var uuids []string
@@ -128,14 +166,7 @@ func runBubbleTeaReviewBatch(tasks []*taskwarrior.TaskData, total int) error {
 	
 	for _, td := range tasks {
 		uuids = append(uuids, td.UUID)
-		taskMap[td.UUID] = &taskwarrior.Task{
-			UUID:        td.UUID,
-			Description: td.Description,
-			Project:     td.Project,
-			Priority:    td.Priority,
-			Status:      td.Status,
-			Due:         td.Due,
-		}
+		taskMap[td.UUID] = td.ToTask()
 	}
 	
 	model.SetTasks(uuids, total)
@@ -157,11 +188,13 @@ func runBubbleTeaReviewBatch(tasks []*taskwarrior.TaskData, total int) error {
 		return nil
 	}
 
+	saveSession(ctx, model)
+	writeReport(model)
 	return nil
 }
 
 // runBubbleTeaReviewLazy runs the review interface with lazy loading
-func runBubbleTeaReviewLazy(allUUIDs []string, initialLoad int) error {
+func runBubbleTeaReviewLazy(ctx context.Context, backend taskwarrior.TaskBackend, opts Options, allUUIDs []string, initialLoad int) error {
 	// Show welcome message
 	showWelcomeMessage()
 
@@ -174,7 +207,7 @@ func runBubbleTeaReviewLazy(allUUIDs []string, initialLoad int) error {
 	fmt.Printf("Loading first %d tasks...\n", len(firstBatch))
 	
 	// Load initial batch with progress
-	initialTasks, err := taskwarrior.GetTasksWithDataProgress(firstBatch, func(loaded, total int) {
+	initialTasks, err := backend.GetTasksWithDataProgress(ctx, firstBatch, func(loaded, total int) {
 		fmt.Printf("\rLoading tasks... %d/%d", loaded, total)
 	})
 	fmt.Print("\r                                        \r")
@@ -184,7 +217,9 @@ func runBubbleTeaReviewLazy(allUUIDs []string, initialLoad int) error {
 	}
 
 	// Create and initialize the review model
-	model := NewReviewModel()
+	model := NewReviewModelWithContext(ctx, backend)
+	model.queue = opts.Queue
+	model.reportPath = opts.Report
 	
 	// Set up initial tasks
 	var uuids []string
@@ -192,14 +227,7 @@ func runBubbleTeaReviewLazy(allUUIDs []string, initialLoad int) error {
 	
 	for _, td := range initialTasks {
 		uuids = append(uuids, td.UUID)
-		taskMap[td.UUID] = &taskwarrior.Task{
-			UUID:        td.UUID,
-			Description: td.Description,
-			Project:     td.Project,
-			Priority:    td.Priority,
-			Status:      td.Status,
-			Due:         td.Due,
-		}
+		taskMap[td.UUID] = td.ToTask()
 	}
 	
 	// Set all UUIDs but only loaded data for first batch
@@ -223,9 +251,68 @@ func runBubbleTeaReviewLazy(allUUIDs []string, initialLoad int) error {
 		return fmt.Errorf("failed to run review interface: %w", err)
 	}
 
+	saveSession(ctx, model)
+	writeReport(model)
+	return nil
+}
+
+// resumeReview continues the saved review of a queue
+func resumeReview(ctx context.Context, backend taskwarrior.TaskBackend, opts Options) error {
+	queue := opts.Queue
+	db, err := timedb.New()
+	if err != nil {
+		return fmt.Errorf("failed to open the review session store: %w", err)
+	}
+	s, err := db.LoadReviewSession(queue.Label())
+	if err != nil {
+		db.Close()
+		return err
+	}
+	if s == nil {
+		db.Close()
+		return fmt.Errorf("no saved review for queue %s", queue.Label())
+	}
+
+	model := NewReviewModelWithContext(ctx, backend)
+	model.queue = opts.Queue
+	model.reportPath = opts.Report
+	err = model.resumeSession(ctx, s)
+	if errors.Is(err, errNothingToResume) {
+		err = db.DeleteReviewSession(queue.Label())
+		db.Close()
+		if err != nil {
+			return err
+		}
+		fmt.Println("\nThere are no tasks left in the saved review.")
+		fmt.Println()
+		return nil
+	}
+	db.Close()
+	if err != nil {
+		return err
+	}
+
+	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	if _, err := p.Run(); err != nil {
+		return fmt.Errorf("failed to run review interface: %w", err)
+	}
+
+	saveSession(ctx, model)
+	writeReport(model)
 	return nil
 }
 
+// lazyLoadThreshold returns how many tasks are loaded up front before the
+// rest load in the background, from TASKSH_LAZY_LOAD_THRESHOLD
+func lazyLoadThreshold() int {
+	if val := os.Getenv("TASKSH_LAZY_LOAD_THRESHOLD"); val != "" {
+		if threshold, err := strconv.Atoi(val); err == nil && threshold > 0 {
+			return threshold
+		}
+	}
+	return 100 // default
+}
+
 func showWelcomeMessage() {
 	fmt.Println()
 	fmt.Println("Welcome to tasksh review!")
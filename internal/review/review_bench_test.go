@@ -3,18 +3,17 @@ package review
 import (
 	"fmt"
 	"testing"
-	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/emiller/tasksh/internal/taskwarrior"
+
+	"github.com/emiller/tasksh/internal/taskwarrior/memory"
 )
 
 // BenchmarkReviewModelView benchmarks the View rendering performance
 func BenchmarkReviewModelView(b *testing.B) {
-	tasks := createBenchmarkTasks(20)
-	model := createTestModel(tasks)
-	model.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
-	
+	backend, uuids := createBenchmarkTasks(b, 20)
+	model := createBenchmarkModel(b, backend, uuids...)
+
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		_ = model.View()
@@ -23,18 +22,17 @@ func BenchmarkReviewModelView(b *testing.B) {
 
 // BenchmarkReviewModelUpdate benchmarks Update with different message types
 func BenchmarkReviewModelUpdate(b *testing.B) {
-	tasks := createBenchmarkTasks(20)
-	model := createTestModel(tasks)
-	model.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
-	
+	backend, uuids := createBenchmarkTasks(b, 20)
+	model := createBenchmarkModel(b, backend, uuids...)
+
 	messages := []tea.Msg{
 		tea.KeyMsg{Type: tea.KeyDown},
 		tea.KeyMsg{Type: tea.KeyUp},
 		tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'?'}}, // Toggle help
-		tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}}, // Mark reviewed
-		tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}}, // Complete
+		tea.WindowSizeMsg{Width: 100, Height: 30},
+		tea.WindowSizeMsg{Width: 80, Height: 24},
 	}
-	
+
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		model.Update(messages[i%len(messages)])
@@ -44,112 +42,64 @@ func BenchmarkReviewModelUpdate(b *testing.B) {
 // BenchmarkRenderTask benchmarks task rendering with different complexities
 func BenchmarkRenderTask(b *testing.B) {
 	testCases := []struct {
-		name string
-		task *taskwarrior.Task
+		name          string
+		description   string
+		modifications []string
 	}{
 		{
-			name: "Simple",
-			task: &taskwarrior.Task{
-				UUID:        "simple-1",
-				Description: "Simple task",
-			},
+			name:        "Simple",
+			description: "Simple task",
 		},
 		{
-			name: "WithTags",
-			task: &taskwarrior.Task{
-				UUID:        "tags-1",
-				Description: "Task with tags",
-				Tags:        []string{"work", "urgent", "backend", "review"},
-			},
+			name:          "WithTags",
+			description:   "Task with tags",
+			modifications: []string{"+work", "+urgent", "+backend", "+review"},
 		},
 		{
-			name: "Complex",
-			task: &taskwarrior.Task{
-				UUID:        "complex-1",
-				Description: "Complex task with very long description that includes multiple details and requirements",
-				Project:     "big-project",
-				Tags:        []string{"work", "urgent", "backend", "review", "testing"},
-				Due:         "2024-12-25",
-				Priority:    "H",
-			},
+			name:          "Complex",
+			description:   "Complex task with very long description that includes multiple details and requirements",
+			modifications: []string{"project:big-project", "+work", "+urgent", "+backend", "+review", "+testing", "due:2024-12-25", "priority:H"},
 		},
 	}
-	
+
 	for _, tc := range testCases {
 		b.Run(tc.name, func(b *testing.B) {
-			model := createTestModel([]*taskwarrior.Task{tc.task})
-			model.width = 80
-			
-			b.ResetTimer()
-			for i := 0; i < b.N; i++ {
-				_ = model.renderTask(tc.task)
+			backend := memory.New()
+			uuid, err := backend.Add(tc.description, tc.modifications...)
+			if err != nil {
+				b.Fatalf("Failed to add task: %v", err)
 			}
-		})
-	}
-}
+			model := createBenchmarkModel(b, backend, uuid)
 
-// BenchmarkTransitionTo benchmarks state transitions
-func BenchmarkTransitionTo(b *testing.B) {
-	transitions := []struct {
-		name  string
-		state reviewState
-	}{
-		{"ToHelp", stateHelp},
-		{"ToModifying", stateModifying},
-		{"ToDeleting", stateDeleting},
-		{"ToCompleting", stateCompleting},
-		{"ToWaiting", stateWaiting},
-		{"ToMain", stateMain},
-	}
-	
-	tasks := createBenchmarkTasks(10)
-	
-	for _, tr := range transitions {
-		b.Run(tr.name, func(b *testing.B) {
-			model := createTestModel(tasks)
-			
 			b.ResetTimer()
 			for i := 0; i < b.N; i++ {
-				model.transitionTo(tr.state)
+				model.updateViewport()
 			}
 		})
 	}
 }
 
-// BenchmarkHandleReviewAction benchmarks different review actions
-func BenchmarkHandleReviewAction(b *testing.B) {
-	actions := []string{"r", "c", "e", "m", "d", "s"}
-	tasks := createBenchmarkTasks(20)
-	
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		model := createTestModel(tasks)
-		action := actions[i%len(actions)]
-		model.handleReviewAction(action)
+// BenchmarkRenderModes benchmarks View in the modes with their own panels
+func BenchmarkRenderModes(b *testing.B) {
+	modes := []struct {
+		name string
+		mode ReviewMode
+	}{
+		{"Viewing", ModeViewing},
+		{"ConfirmDelete", ModeConfirmDelete},
+		{"InputModification", ModeInputModification},
+		{"History", ModeHistory},
 	}
-}
 
-// BenchmarkRenderHelp benchmarks help rendering in different states
-func BenchmarkRenderHelp(b *testing.B) {
-	states := []reviewState{
-		stateMain,
-		stateHelp,
-		stateModifying,
-		stateCompleting,
-	}
-	
-	tasks := createBenchmarkTasks(5)
-	
-	for _, state := range states {
-		b.Run(string(state), func(b *testing.B) {
-			model := createTestModel(tasks)
-			model.state = state
-			model.width = 80
-			model.height = 24
-			
+	for _, tc := range modes {
+		b.Run(tc.name, func(b *testing.B) {
+			backend, uuids := createBenchmarkTasks(b, 5)
+			model := createBenchmarkModel(b, backend, uuids...)
+			model.mode = tc.mode
+
 			b.ResetTimer()
 			for i := 0; i < b.N; i++ {
-				_ = model.renderHelp()
+				_ = model.View()
 			}
 		})
 	}
@@ -157,32 +107,39 @@ func BenchmarkRenderHelp(b *testing.B) {
 
 // Helper functions
 
-func createBenchmarkTasks(count int) []*taskwarrior.Task {
-	tasks := make([]*taskwarrior.Task, count)
-	for i := 0; i < count; i++ {
-		tasks[i] = &taskwarrior.Task{
-			UUID:        fmt.Sprintf("bench-%d", i),
-			Description: fmt.Sprintf("Benchmark task %d with moderate description length", i),
-			Project:     fmt.Sprintf("project-%d", i%3),
-			Tags:        []string{"bench", fmt.Sprintf("tag%d", i%5)},
-			Priority:    []string{"H", "M", "L", ""}[i%4],
-			Due:         "",
-			Entry:       time.Now().AddDate(0, 0, -i),
+// createBenchmarkTasks fills an in-memory backend with count tasks, a third
+// of them due, and returns it with their UUIDs
+func createBenchmarkTasks(b *testing.B, count int) (*memory.Backend, []string) {
+	b.Helper()
+	backend := memory.New()
+	uuids := make([]string, count)
+	for i := range count {
+		modifications := []string{
+			fmt.Sprintf("project:project-%d", i%3),
+			"+bench",
+			fmt.Sprintf("+tag%d", i%5),
+		}
+		if priority := []string{"H", "M", "L", ""}[i%4]; priority != "" {
+			modifications = append(modifications, "priority:"+priority)
 		}
-		
 		if i%3 == 0 {
-			tasks[i].Due = time.Now().AddDate(0, 0, i).Format("2006-01-02")
+			modifications = append(modifications, fmt.Sprintf("due:now+%dd", i))
+		}
+		uuid, err := backend.Add(fmt.Sprintf("Benchmark task %d with moderate description length", i), modifications...)
+		if err != nil {
+			b.Fatalf("Failed to add task: %v", err)
 		}
+		uuids[i] = uuid
 	}
-	return tasks
+	return backend, uuids
 }
 
-func createTestModel(tasks []*taskwarrior.Task) *model {
-	return &model{
-		tasks:        tasks,
-		currentIndex: 0,
-		state:        stateMain,
-		width:        80,
-		height:       24,
-	}
-}
\ No newline at end of file
+// createBenchmarkModel reviews the given tasks with the first one loaded
+func createBenchmarkModel(b *testing.B, backend *memory.Backend, uuids ...string) *ReviewModel {
+	b.Helper()
+	model := NewReviewModelWithBackend(backend)
+	model.SetTasks(uuids, len(uuids))
+	model.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	model.Update(model.loadCurrentTask()())
+	return model
+}
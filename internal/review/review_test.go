@@ -174,6 +174,7 @@ func TestDiagnosticsOutput(t *testing.T) {
 		"Version: 2.0.0-go",
 		"Built with: Go",
 		"Taskwarrior:",
+		"Taskrc:",
 		"Mods (AI):", // New AI diagnostics
 		"Time Database:", // New time tracking diagnostics
 	}
@@ -0,0 +1,177 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/emiller/tasksh/internal/taskwarrior"
+	"github.com/emiller/tasksh/internal/timedb"
+)
+
+// errNothingToResume is returned when no task in a saved review is left
+var errNothingToResume = errors.New("nothing left to review in the saved session")
+
+// finished reports whether the review reached its end
+func (m *ReviewModel) finished() bool {
+	return m.mode == ModeCelebrating || m.current >= len(m.tasks)
+}
+
+// untouched reports whether nothing happened in the review yet
+func (m *ReviewModel) untouched() bool {
+	return m.current == 0 && m.reviewed == 0 && len(m.skipped) == 0 && len(m.pastActions) == 0
+}
+
+// sessionActions returns the actions taken in the review, undos included,
+// oldest first, with the changes each made
+func (m *ReviewModel) sessionActions() []timedb.SessionAction {
+	actions := slices.Clone(m.pastActions)
+	for _, entry := range m.journal.actions() {
+		action := timedb.SessionAction{Action: entry.action, At: entry.at}
+		for _, change := range entry.changes {
+			action.UUIDs = append(action.UUIDs, change.before.UUID)
+			action.Changes = append(action.Changes, timedb.SessionChange{Before: change.before, After: change.after})
+		}
+		actions = append(actions, action)
+	}
+	return actions
+}
+
+// sessionState returns the review's progress for saving. The tasks still
+// ahead keep their modification time, so changes made outside tasksh can be
+// noticed when the review resumes.
+func (m *ReviewModel) sessionState(ctx context.Context) (timedb.ReviewSession, error) {
+	ahead, err := m.backend.GetTasksWithDataProgress(ctx, m.tasks[m.current:], nil)
+	if err != nil {
+		return timedb.ReviewSession{}, fmt.Errorf("failed to load the tasks left to review: %w", err)
+	}
+	modified := make(map[string]time.Time, len(ahead))
+	for _, td := range ahead {
+		modified[td.UUID] = td.Modified.Time
+	}
+
+	s := timedb.ReviewSession{
+		Queue:    m.queue.Label(),
+		Tasks:    make([]timedb.SessionTask, len(m.tasks)),
+		Current:  m.current,
+		Reviewed: m.reviewed,
+		Skipped:  slices.Clone(m.skipped),
+		Actions:  m.sessionActions(),
+		Elapsed:  m.elapsed + time.Since(m.started),
+		SavedAt:  time.Now(),
+	}
+	for i, uuid := range m.tasks {
+		s.Tasks[i] = timedb.SessionTask{UUID: uuid, Modified: modified[uuid]}
+	}
+	return s, nil
+}
+
+// resumeSession restores a saved review with the tasks' current data. Tasks
+// still ahead that were closed or removed since are dropped, and those
+// changed outside tasksh are counted in the resume message.
+func (m *ReviewModel) resumeSession(ctx context.Context, s *timedb.ReviewSession) error {
+	uuids := make([]string, len(s.Tasks))
+	for i, st := range s.Tasks {
+		uuids[i] = st.UUID
+	}
+	tasks, err := m.backend.GetTasksWithDataProgress(ctx, uuids, nil)
+	if err != nil {
+		return fmt.Errorf("failed to load the saved review's tasks: %w", err)
+	}
+	byUUID := make(map[string]*taskwarrior.TaskData, len(tasks))
+	for _, td := range tasks {
+		byUUID[td.UUID] = td
+	}
+
+	var kept []string
+	cache := make(map[string]*taskwarrior.Task, len(tasks))
+	current, changed, dropped := s.Current, 0, 0
+	for i, st := range s.Tasks {
+		td := byUUID[st.UUID]
+		ahead := i >= s.Current
+		if td == nil || (ahead && td.Status != "pending" && td.Status != "waiting") {
+			if ahead {
+				dropped++
+			} else {
+				current--
+			}
+			continue
+		}
+		if ahead && !st.Modified.IsZero() && !td.Modified.Equal(st.Modified) {
+			changed++
+		}
+		kept = append(kept, st.UUID)
+		cache[st.UUID] = td.ToTask()
+	}
+	if current >= len(kept) {
+		return errNothingToResume
+	}
+
+	m.SetTasks(kept, len(kept))
+	m.taskCache = cache
+	m.current = current
+	m.reviewed = s.Reviewed
+	m.skipped = slices.DeleteFunc(slices.Clone(s.Skipped), func(uuid string) bool { return cache[uuid] == nil })
+	m.pastActions = s.Actions
+	m.elapsed = s.Elapsed
+	m.resumed = m.progressBar.SetPercent(float64(m.reviewed) / float64(len(kept)))
+	m.currentTask = cache[kept[current]]
+	m.updateViewport()
+
+	m.message = fmt.Sprintf("Resumed the review from %s (%d reviewed, %s spent).",
+		s.SavedAt.Local().Format("Jan 2 15:04"), s.Reviewed, s.Elapsed.Round(time.Minute))
+	if changed > 0 {
+		m.message += fmt.Sprintf(" %d changed outside tasksh since and were reloaded.", changed)
+	}
+	if dropped > 0 {
+		m.message += fmt.Sprintf(" %d no longer open and dropped.", dropped)
+	}
+	return nil
+}
+
+// saveSession saves an unfinished review so `tasksh review --resume` can
+// continue it, and removes the saved session once the review is done
+func saveSession(ctx context.Context, model *ReviewModel) {
+	if !model.finished() && model.untouched() {
+		return
+	}
+	db, err := model.openTimeDB()
+	if err != nil {
+		fmt.Printf("Warning: could not save the review session: %v\n", err)
+		return
+	}
+	defer db.Close()
+
+	queue := model.queue.Label()
+	if model.finished() {
+		if err := db.DeleteReviewSession(queue); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+		return
+	}
+
+	// The review has ended, so save it even if it was cancelled
+	s, err := model.sessionState(context.WithoutCancel(ctx))
+	if err == nil {
+		err = db.SaveReviewSession(s)
+	}
+	if err != nil {
+		fmt.Printf("Warning: could not save the review session: %v\n", err)
+		return
+	}
+	fmt.Print("Review saved; run the same review with --resume to continue it.\n\n")
+}
+
+// savedSession returns the saved session for a queue, or nil when there is
+// none or the session store can't be opened
+func savedSession(queue taskwarrior.ReviewQueue) *timedb.ReviewSession {
+	db, err := timedb.New()
+	if err != nil {
+		return nil
+	}
+	defer db.Close()
+	s, _ := db.LoadReviewSession(queue.Label())
+	return s
+}
@@ -0,0 +1,85 @@
+package review
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emiller/tasksh/internal/taskwarrior"
+	"github.com/emiller/tasksh/internal/taskwarrior/memory"
+)
+
+func TestResumeSession(t *testing.T) {
+	now := time.Date(2024, 6, 12, 10, 0, 0, 0, time.UTC)
+	backend := memory.New()
+	backend.SetClock(func() time.Time { return now })
+	var uuids []string
+	for _, desc := range []string{"Pay rent", "Fix bike", "Plan trip", "Order printer ink", "Call mum"} {
+		uuid, err := backend.Add(desc)
+		if err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+		uuids = append(uuids, uuid)
+	}
+
+	model := NewReviewModelWithBackend(backend)
+	model.queue = taskwarrior.DefaultReviewQueue
+	model.SetTasks(slices.Clone(uuids), len(uuids))
+	model.Update(model.loadCurrentTask()())
+	model.Update(model.reviewCurrentTask()())
+	model.Update(model.skipCurrentTask()())
+	if model.untouched() || model.finished() {
+		t.Fatalf("Expected a review in progress")
+	}
+
+	saved, err := model.sessionState(t.Context())
+	if err != nil {
+		t.Fatalf("sessionState failed: %v", err)
+	}
+	if saved.Current != 2 || saved.Reviewed != 1 || !slices.Equal(saved.Skipped, uuids[1:2]) || len(saved.Actions) != 1 {
+		t.Fatalf("Unexpected saved session: %+v", saved)
+	}
+
+	// Meanwhile one task is changed and one completed outside tasksh
+	now = now.Add(time.Hour)
+	if err := backend.ModifyTask(t.Context(), uuids[3], "priority:H"); err != nil {
+		t.Fatalf("ModifyTask failed: %v", err)
+	}
+	if err := backend.CompleteTask(t.Context(), uuids[4]); err != nil {
+		t.Fatalf("CompleteTask failed: %v", err)
+	}
+
+	resumed := NewReviewModelWithBackend(backend)
+	if err := resumed.resumeSession(t.Context(), &saved); err != nil {
+		t.Fatalf("resumeSession failed: %v", err)
+	}
+	if !slices.Equal(resumed.tasks, uuids[:4]) || resumed.current != 2 || resumed.reviewed != 1 || resumed.currentTask.Description != "Plan trip" {
+		t.Errorf("Expected to resume at the third task, got %v at %d with %d reviewed", resumed.tasks, resumed.current, resumed.reviewed)
+	}
+	if resumed.resumed == nil || resumed.progressBar.Percent() != 0.25 {
+		t.Errorf("Expected the restored progress shown on start, got %.2f", resumed.progressBar.Percent())
+	}
+	if !strings.Contains(resumed.message, "1 changed outside tasksh") || !strings.Contains(resumed.message, "1 no longer open") {
+		t.Errorf("Unexpected resume message: %q", resumed.message)
+	}
+	if resumed.taskCache[uuids[3]].Priority != "H" {
+		t.Errorf("Expected the changed task reloaded")
+	}
+	if actions := resumed.sessionActions(); len(actions) != 1 || !strings.HasPrefix(actions[0].Action, "Review") {
+		t.Errorf("Expected the earlier action kept, got %+v", actions)
+	}
+
+	// Reviewing the skipped task takes it out of the skipped set
+	resumed.current = 1
+	resumed.Update(resumed.reviewCurrentTask()())
+	if len(resumed.skipped) != 0 {
+		t.Errorf("Expected no skipped tasks, got %v", resumed.skipped)
+	}
+
+	// A session with nothing left can't be resumed
+	saved.Current = 4
+	if err := NewReviewModelWithBackend(backend).resumeSession(t.Context(), &saved); err != errNothingToResume {
+		t.Errorf("Expected errNothingToResume, got %v", err)
+	}
+}
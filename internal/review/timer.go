@@ -0,0 +1,91 @@
+package review
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/emiller/tasksh/internal/timedb"
+)
+
+type timerChangedMsg struct {
+	timedb.TimerChange
+}
+
+type timerTickMsg struct {
+	gen int
+}
+
+// toggleTimer starts or stops the current task, recording stopped intervals
+// in the profile's time database
+func (m *ReviewModel) toggleTimer() tea.Cmd {
+	task := m.currentTask
+	if task == nil {
+		return nil
+	}
+	ctx := m.ctx
+	previous := m.tracking
+	return func() tea.Msg {
+		db, err := timedb.Open(m.timeDBPath)
+		if err != nil {
+			return errorMsg{err}
+		}
+		defer db.Close()
+		change, err := timedb.ToggleTimer(ctx, m.backend, db, task, previous)
+		if err != nil {
+			return errorMsg{err}
+		}
+		return timerChangedMsg{change}
+	}
+}
+
+// recordWork stores an interval of work on a task in the time database at
+// timeDBPath
+func recordWork(timeDBPath, uuid string, since, now time.Time) error {
+	db, err := timedb.Open(timeDBPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.RecordWork(uuid, since, now)
+}
+
+// timerChanged follows a started or stopped timer, reloading the current
+// task for its new start date
+func (m *ReviewModel) timerChanged(msg timerChangedMsg) (tea.Model, tea.Cmd) {
+	m.message = msg.Message
+	if m.tracking != nil && m.tracking.UUID == msg.Stopped {
+		m.tracking = nil
+	}
+	if m.taskCache != nil && msg.Stopped != "" {
+		delete(m.taskCache, msg.Stopped)
+	}
+	cmds := []tea.Cmd{m.reloadCurrentTask()}
+	if msg.Started != nil {
+		m.tracking = msg.Started
+		m.timerGen++
+		cmds = append(cmds, m.tickTimer())
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// tickTimer schedules the next redraw of the status bar timer
+func (m *ReviewModel) tickTimer() tea.Cmd {
+	gen := m.timerGen
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return timerTickMsg{gen: gen}
+	})
+}
+
+// timerStatus renders the running timer for the status bar, naming the
+// task when it isn't the one on screen
+func (m *ReviewModel) timerStatus(now time.Time) string {
+	if m.tracking == nil {
+		return ""
+	}
+	status := "⏱ " + timedb.FormatElapsed(now.Sub(m.tracking.Since))
+	if m.currentTask == nil || m.currentTask.UUID != m.tracking.UUID {
+		status += " (" + m.tracking.Description + ")"
+	}
+	return status + " "
+}
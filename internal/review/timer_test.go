@@ -0,0 +1,132 @@
+package review
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/emiller/tasksh/internal/taskwarrior"
+	"github.com/emiller/tasksh/internal/taskwarrior/memory"
+	"github.com/emiller/tasksh/internal/timedb"
+)
+
+func TestTimer(t *testing.T) {
+	t.Setenv("TASKSH_TIMEDB", filepath.Join(t.TempDir(), "timedb.sqlite3"))
+	// Tasks start half an hour before the real clock, so each interval is long
+	// enough to record
+	backend := memory.New()
+	backend.SetClock(func() time.Time { return time.Now().Add(-30 * time.Minute) })
+	first, err := backend.Add("Fix bike")
+	if err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	second, err := backend.Add("Plan trip")
+	if err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+
+	model := NewReviewModelWithBackend(backend)
+	model.SetTasks([]string{first, second}, 2)
+	model.Update(tea.WindowSizeMsg{Width: 100, Height: 40})
+	model.Update(model.loadCurrentTask()())
+	press := func() {
+		_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("T")})
+		model.Update(cmd())
+		model.Update(model.loadCurrentTask()())
+	}
+
+	press()
+	if model.tracking == nil || model.tracking.UUID != first {
+		t.Fatalf("Expected the first task timed, got %+v", model.tracking)
+	}
+	if task, _ := backend.GetTaskInfo(t.Context(), first); task.Start.IsZero() {
+		t.Errorf("Expected the first task started")
+	}
+	if view := model.View(); !strings.Contains(view, "⏱ 30:0") {
+		t.Errorf("Expected the elapsed time in the status bar:\n%s", view)
+	}
+
+	// Starting another task stops the first and records its interval
+	model.Update(model.skipCurrentTask()())
+	model.Update(model.loadCurrentTask()())
+	press()
+	if model.tracking == nil || model.tracking.UUID != second {
+		t.Fatalf("Expected the second task timed, got %+v", model.tracking)
+	}
+	if task, _ := backend.GetTaskInfo(t.Context(), first); !task.Start.IsZero() {
+		t.Errorf("Expected the first task stopped")
+	}
+
+	// Completing the active task ends its interval too
+	model.Update(model.completeCurrentTask()())
+	if model.tracking != nil {
+		t.Errorf("Expected the timer stopped by completing, got %+v", model.tracking)
+	}
+
+	db, err := timedb.New()
+	if err != nil {
+		t.Fatalf("Failed to open timedb: %v", err)
+	}
+	defer db.Close()
+	for _, uuid := range []string{first, second} {
+		sessions, err := db.WorkSessions(uuid)
+		if err != nil || len(sessions) != 1 {
+			t.Fatalf("Expected one work session, got %v, %v", sessions, err)
+		}
+		if d := sessions[0].Duration(); d < 29*time.Minute || d > 31*time.Minute {
+			t.Errorf("Expected about 30 minutes worked, got %v", d)
+		}
+	}
+}
+
+func TestTimerStopsOnProfileSwitch(t *testing.T) {
+	for _, env := range []string{"TASKRC", "TASKDATA", "OPENAI_API_KEY", "OPENAI_API_KEY_CMD"} {
+		t.Setenv(env, "")
+	}
+	t.Setenv("TASKSH_TIMEDB", filepath.Join(t.TempDir(), "timedb.sqlite3"))
+	backend := memory.New()
+	backend.SetClock(func() time.Time { return time.Now().Add(-30 * time.Minute) })
+	uuid, err := backend.Add("Fix bike")
+	if err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+
+	model := NewReviewModelWithBackend(backend)
+	model.SetTasks([]string{uuid}, 1)
+	model.Update(model.loadCurrentTask()())
+	model.Update(model.toggleTimer()())
+	if model.tracking == nil {
+		t.Fatalf("Expected the task timed: %s", model.message)
+	}
+	gen := model.timerGen
+
+	work := taskwarrior.Profile{Name: "work", TimeDB: filepath.Join(t.TempDir(), "work.sqlite3")}
+	model.Update(model.switchProfile(work)())
+	if model.tracking != nil || model.timerGen == gen || !strings.Contains(model.message, `Stopped timing "Fix bike"`) {
+		t.Errorf("Expected the timer ended by the switch, got %+v: %s", model.tracking, model.message)
+	}
+	if task, _ := backend.GetTaskInfo(t.Context(), uuid); !task.Start.IsZero() {
+		t.Error("Expected the task stopped")
+	}
+
+	// The interval goes to the profile it was timed in
+	db, err := timedb.New()
+	if err != nil {
+		t.Fatalf("Failed to open timedb: %v", err)
+	}
+	defer db.Close()
+	if sessions, err := db.WorkSessions(uuid); err != nil || len(sessions) != 1 {
+		t.Errorf("Expected the interval recorded in the old profile, got %v, %v", sessions, err)
+	}
+	workDB, err := timedb.Open(work.TimeDB)
+	if err != nil {
+		t.Fatalf("Failed to open the work timedb: %v", err)
+	}
+	defer workDB.Close()
+	if sessions, _ := workDB.WorkSessions(uuid); len(sessions) != 0 {
+		t.Errorf("Expected nothing recorded in the new profile, got %v", sessions)
+	}
+}
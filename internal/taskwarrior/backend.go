@@ -0,0 +1,233 @@
+package taskwarrior
+
+import (
+	"context"
+	"io"
+	"os/exec"
+)
+
+// TaskBackend is the set of task operations used by the review, planning and
+// AI packages. The default implementation shells out to the task binary;
+// alternative implementations (such as the in-memory backend) let the TUIs
+// and tests run without a Taskwarrior install. Every call except
+// CreateEditCommand, which runs interactively, takes a context so that a hung
+// task process can be cancelled.
+type TaskBackend interface {
+	// Configuration
+	EnsureReviewConfig(ctx context.Context, w io.Writer) error
+	GetUrgencyCoefficients(ctx context.Context) (UrgencyCoefficients, error)
+	GetUDAs(ctx context.Context) ([]UDADefinition, error)
+
+	// Queries
+	GetTasksForReview(ctx context.Context) ([]string, error)
+	GetTasksForQueue(ctx context.Context, queue ReviewQueue) ([]string, error)
+	GetTasksWithDataProgress(ctx context.Context, uuids []string, progressFn func(loaded, total int)) ([]*TaskData, error)
+	FilterUUIDs(ctx context.Context, filter []string) ([]string, error)
+	GetTaskInfo(ctx context.Context, uuid string) (*Task, error)
+	GetProjects(ctx context.Context) ([]string, error)
+	GetTags(ctx context.Context) ([]string, error)
+
+	// Task actions
+	CreateEditCommand(uuid string) *exec.Cmd
+	ModifyTask(ctx context.Context, uuid, modifications string) error
+	CompleteTask(ctx context.Context, uuid string) error
+	DeleteTask(ctx context.Context, uuid string) error
+	MarkTaskReviewed(ctx context.Context, uuid string) error
+	StartTask(ctx context.Context, uuid string) error
+	StopTask(ctx context.Context, uuid string) error
+	WaitTask(ctx context.Context, uuid, waitUntil, reason, waitingOn string) error
+	SetDueDate(ctx context.Context, uuid, dueDate string) error
+	RemoveDueDate(ctx context.Context, uuid string) error
+	RemoveWaitDate(ctx context.Context, uuid string) error
+	AnnotateTask(ctx context.Context, uuid, text string) error
+	DenotateTask(ctx context.Context, uuid, text string) error
+	RestoreTask(ctx context.Context, td *TaskData) error
+	UndoLastAction(ctx context.Context) error
+	ExecuteCommand(ctx context.Context, args []string) (string, error)
+
+	// Batch actions change several tasks in one call
+	ModifyTasks(ctx context.Context, uuids []string, modifications []string) error
+	CompleteTasks(ctx context.Context, uuids []string) error
+	DeleteTasks(ctx context.Context, uuids []string) error
+
+	// Contexts
+	GetContexts(ctx context.Context) ([]string, error)
+	SetContext(ctx context.Context, contextName string) error
+	GetCurrentContext(ctx context.Context) (string, error)
+}
+
+// ExecBackend implements TaskBackend by running the task command
+type ExecBackend struct {
+	env []string // Environment task runs with, nil for the process's own
+}
+
+// NewExecBackend creates a backend that runs the task command
+func NewExecBackend() *ExecBackend {
+	return &ExecBackend{}
+}
+
+// NewProfileBackend creates a backend that runs the task command with the
+// profile's taskrc and data, leaving the process environment alone
+func NewProfileBackend(p Profile) *ExecBackend {
+	return &ExecBackend{env: p.Environ()}
+}
+
+// ForProfile returns the backend to use for a profile: for an ExecBackend a
+// new one with the profile's environment, and otherwise the backend itself,
+// which keeps its own data
+func ForProfile(backend TaskBackend, p Profile) TaskBackend {
+	if _, ok := backend.(*ExecBackend); ok {
+		return NewProfileBackend(p)
+	}
+	return backend
+}
+
+// bind gives ctx the backend's environment for the package functions
+func (b *ExecBackend) bind(ctx context.Context) context.Context {
+	return withEnv(ctx, b.env)
+}
+
+func (b *ExecBackend) EnsureReviewConfig(ctx context.Context, w io.Writer) error {
+	return EnsureReviewConfig(b.bind(ctx), w)
+}
+
+func (b *ExecBackend) GetUrgencyCoefficients(ctx context.Context) (UrgencyCoefficients, error) {
+	return GetUrgencyCoefficients(b.bind(ctx))
+}
+
+func (b *ExecBackend) GetUDAs(ctx context.Context) ([]UDADefinition, error) {
+	return GetUDAs(b.bind(ctx))
+}
+
+func (b *ExecBackend) GetTasksForReview(ctx context.Context) ([]string, error) {
+	return GetTasksForReview(b.bind(ctx))
+}
+
+func (b *ExecBackend) GetTasksForQueue(ctx context.Context, queue ReviewQueue) ([]string, error) {
+	return GetTasksForQueue(b.bind(ctx), queue)
+}
+
+func (b *ExecBackend) GetTasksWithDataProgress(ctx context.Context, uuids []string, progressFn func(loaded, total int)) ([]*TaskData, error) {
+	return GetTasksWithDataProgress(b.bind(ctx), uuids, progressFn)
+}
+
+func (b *ExecBackend) FilterUUIDs(ctx context.Context, filter []string) ([]string, error) {
+	return FilterUUIDs(b.bind(ctx), filter)
+}
+
+func (b *ExecBackend) GetTaskInfo(ctx context.Context, uuid string) (*Task, error) {
+	return GetTaskInfo(b.bind(ctx), uuid)
+}
+
+func (b *ExecBackend) GetProjects(ctx context.Context) ([]string, error) {
+	return GetProjects(b.bind(ctx))
+}
+
+func (b *ExecBackend) GetTags(ctx context.Context) ([]string, error) {
+	return GetTags(b.bind(ctx))
+}
+
+func (b *ExecBackend) CreateEditCommand(uuid string) *exec.Cmd {
+	cmd := CreateEditCommand(uuid)
+	cmd.Env = b.env
+	return cmd
+}
+
+func (b *ExecBackend) ModifyTask(ctx context.Context, uuid, modifications string) error {
+	return ModifyTask(b.bind(ctx), uuid, modifications)
+}
+
+func (b *ExecBackend) CompleteTask(ctx context.Context, uuid string) error {
+	return CompleteTask(b.bind(ctx), uuid)
+}
+
+func (b *ExecBackend) DeleteTask(ctx context.Context, uuid string) error {
+	return DeleteTask(b.bind(ctx), uuid)
+}
+
+func (b *ExecBackend) MarkTaskReviewed(ctx context.Context, uuid string) error {
+	return MarkTaskReviewed(b.bind(ctx), uuid)
+}
+
+func (b *ExecBackend) StartTask(ctx context.Context, uuid string) error {
+	return StartTask(b.bind(ctx), uuid)
+}
+
+func (b *ExecBackend) StopTask(ctx context.Context, uuid string) error {
+	return StopTask(b.bind(ctx), uuid)
+}
+
+func (b *ExecBackend) WaitTask(ctx context.Context, uuid, waitUntil, reason, waitingOn string) error {
+	return WaitTask(b.bind(ctx), uuid, waitUntil, reason, waitingOn)
+}
+
+func (b *ExecBackend) SetDueDate(ctx context.Context, uuid, dueDate string) error {
+	return SetDueDate(b.bind(ctx), uuid, dueDate)
+}
+
+func (b *ExecBackend) RemoveDueDate(ctx context.Context, uuid string) error {
+	return RemoveDueDate(b.bind(ctx), uuid)
+}
+
+func (b *ExecBackend) RemoveWaitDate(ctx context.Context, uuid string) error {
+	return RemoveWaitDate(b.bind(ctx), uuid)
+}
+
+func (b *ExecBackend) AnnotateTask(ctx context.Context, uuid, text string) error {
+	return AnnotateTask(b.bind(ctx), uuid, text)
+}
+
+func (b *ExecBackend) DenotateTask(ctx context.Context, uuid, text string) error {
+	return DenotateTask(b.bind(ctx), uuid, text)
+}
+
+func (b *ExecBackend) RestoreTask(ctx context.Context, td *TaskData) error {
+	return RestoreTask(b.bind(ctx), td)
+}
+
+func (b *ExecBackend) UndoLastAction(ctx context.Context) error {
+	return UndoLastAction(b.bind(ctx))
+}
+
+func (b *ExecBackend) ExecuteCommand(ctx context.Context, args []string) (string, error) {
+	return ExecuteCommand(b.bind(ctx), args)
+}
+
+func (b *ExecBackend) ModifyTasks(ctx context.Context, uuids []string, modifications []string) error {
+	return ModifyTasks(b.bind(ctx), uuids, modifications)
+}
+
+func (b *ExecBackend) CompleteTasks(ctx context.Context, uuids []string) error {
+	return CompleteTasks(b.bind(ctx), uuids)
+}
+
+func (b *ExecBackend) DeleteTasks(ctx context.Context, uuids []string) error {
+	return DeleteTasks(b.bind(ctx), uuids)
+}
+
+func (b *ExecBackend) GetContexts(ctx context.Context) ([]string, error) {
+	return GetContexts(b.bind(ctx))
+}
+
+func (b *ExecBackend) SetContext(ctx context.Context, contextName string) error {
+	return SetContext(b.bind(ctx), contextName)
+}
+
+func (b *ExecBackend) GetCurrentContext(ctx context.Context) (string, error) {
+	return GetCurrentContext(b.bind(ctx))
+}
+
+// BatchLoadTasksFrom loads task data from the given backend as Task structs
+func BatchLoadTasksFrom(ctx context.Context, backend TaskBackend, uuids []string) (map[string]*Task, error) {
+	taskData, err := backend.GetTasksWithDataProgress(ctx, uuids, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	taskMap := make(map[string]*Task)
+	for _, td := range taskData {
+		taskMap[td.UUID] = td.ToTask()
+	}
+
+	return taskMap, nil
+}
@@ -0,0 +1,260 @@
+package taskwarrior
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// championFile is the TaskChampion database used by Taskwarrior 3
+const championFile = "taskchampion.sqlite3"
+
+// DirectReadEnabled reports whether task data should be read straight from
+// the TaskChampion database instead of `task export`. It is off by default
+// and enabled with TASKSH_DIRECT_READ=1.
+func DirectReadEnabled() bool {
+	val := os.Getenv("TASKSH_DIRECT_READ")
+	enabled, err := strconv.ParseBool(val)
+	return err == nil && enabled
+}
+
+// ChampionReader reads tasks from a TaskChampion database. It opens the
+// database read-only; all writes still go through the task command.
+type ChampionReader struct {
+	db *sql.DB
+}
+
+// OpenChampion opens the TaskChampion database in dataDir read-only
+func OpenChampion(dataDir string) (*ChampionReader, error) {
+	// The DSN needs an absolute path: a relative one puts its first
+	// segment in the URL's host
+	path, err := filepath.Abs(filepath.Join(dataDir, championFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve TaskChampion database: %w", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("failed to find TaskChampion database: %w", err)
+	}
+
+	dsn := (&url.URL{Scheme: "file", Path: path, RawQuery: "mode=ro"}).String()
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open TaskChampion database: %w", err)
+	}
+
+	return &ChampionReader{db: db}, nil
+}
+
+// Close closes the database
+func (r *ChampionReader) Close() error {
+	return r.db.Close()
+}
+
+// Tasks returns the tasks with the given UUIDs, in the order requested.
+// UUIDs that are not in the database are skipped.
+func (r *ChampionReader) Tasks(ctx context.Context, uuids []string) ([]*TaskData, error) {
+	ids, err := r.workingSet(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt, err := r.db.PrepareContext(ctx, "SELECT data FROM tasks WHERE uuid = ?")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query TaskChampion database: %w", err)
+	}
+	defer stmt.Close()
+
+	tasks := make([]*TaskData, 0, len(uuids))
+	for _, uuid := range uuids {
+		var data string
+		if err := stmt.QueryRowContext(ctx, uuid).Scan(&data); err != nil {
+			if err == sql.ErrNoRows {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read task %s: %w", uuid, err)
+		}
+
+		task, err := decodeChampionTask(uuid, data)
+		if err != nil {
+			return nil, err
+		}
+		task.ID = ids[uuid]
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
+
+// workingSet maps UUIDs to their working-set IDs
+func (r *ChampionReader) workingSet(ctx context.Context) (map[string]int, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT id, uuid FROM working_set")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read working set: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make(map[string]int)
+	for rows.Next() {
+		var id int
+		var uuid sql.NullString
+		if err := rows.Scan(&id, &uuid); err != nil {
+			return nil, fmt.Errorf("failed to read working set: %w", err)
+		}
+		if uuid.Valid {
+			ids[uuid.String] = id
+		}
+	}
+	return ids, rows.Err()
+}
+
+// decodeChampionTask converts a TaskChampion property map into TaskData.
+// TaskChampion stores every property as a string and dates as epoch seconds.
+// Urgency is not stored, so it is left at zero; readChampionTasks fills it in.
+func decodeChampionTask(uuid, data string) (*TaskData, error) {
+	var props map[string]string
+	if err := json.Unmarshal([]byte(data), &props); err != nil {
+		return nil, fmt.Errorf("failed to parse task %s: %w", uuid, err)
+	}
+
+	task := &TaskData{
+		UUID:        uuid,
+		Description: props["description"],
+		Project:     props["project"],
+		Priority:    props["priority"],
+		Status:      props["status"],
+		Due:         championDate(props["due"]),
+		Wait:        championDate(props["wait"]),
+		Entry:       championDate(props["entry"]),
+		Modified:    championDate(props["modified"]),
+		Reviewed:    championDate(props["reviewed"]),
+		Scheduled:   championDate(props["scheduled"]),
+		Until:       championDate(props["until"]),
+		Start:       championDate(props["start"]),
+		End:         championDate(props["end"]),
+		Recur:       props["recur"],
+		Mask:        props["mask"],
+		Parent:      props["parent"],
+	}
+	if imask, err := strconv.Atoi(props["imask"]); err == nil {
+		task.Imask = imask
+	}
+
+	for key, value := range props {
+		switch {
+		case strings.HasPrefix(key, "tag_"):
+			task.Tags = append(task.Tags, strings.TrimPrefix(key, "tag_"))
+		case strings.HasPrefix(key, "dep_"):
+			task.Depends = append(task.Depends, strings.TrimPrefix(key, "dep_"))
+		case strings.HasPrefix(key, "annotation_"):
+			task.Annotations = append(task.Annotations, Annotation{
+				Entry:       championDate(strings.TrimPrefix(key, "annotation_")),
+				Description: value,
+			})
+		case !taskDataFields[key]:
+			if task.UDA == nil {
+				task.UDA = make(map[string]string)
+			}
+			task.UDA[key] = value
+		}
+	}
+
+	// Map iteration order is random; keep output stable
+	sort.Strings(task.Tags)
+	sort.Strings(task.Depends)
+	sort.Slice(task.Annotations, func(i, j int) bool {
+		return task.Annotations[i].Entry.Before(task.Annotations[j].Entry.Time)
+	})
+
+	return task, nil
+}
+
+// championDate converts an epoch-seconds string to a Date, also accepting
+// dates already in an export format
+func championDate(value string) Date {
+	secs, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		date, _ := ParseDate(value)
+		return date
+	}
+	return NewDate(time.Unix(secs, 0))
+}
+
+// championDataDir returns the Taskwarrior data directory
+func championDataDir(ctx context.Context) (string, error) {
+	if dir := getenv(ctx, "TASKDATA"); dir != "" {
+		return dir, nil
+	}
+	if rc, err := loadTaskrc(ctx); err == nil {
+		return rc.DataLocation(), nil
+	}
+	if dir, err := executeTask(ctx, "_get", "rc.data.location"); err == nil && dir != "" {
+		return expandHome(dir), nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".task"), nil
+}
+
+// expandHome expands a leading ~ in a path
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~"); ok {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(homeDir, rest)
+		}
+	}
+	return path
+}
+
+// OpenTasks returns the tasks in the working set, which holds every open
+// task
+func (r *ChampionReader) OpenTasks(ctx context.Context) ([]*TaskData, error) {
+	ids, err := r.workingSet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	uuids := make([]string, 0, len(ids))
+	for uuid := range ids {
+		uuids = append(uuids, uuid)
+	}
+	return r.Tasks(ctx, uuids)
+}
+
+// readChampionTasks loads tasks directly from the TaskChampion database,
+// with urgency computed as `task export` would report it
+func readChampionTasks(ctx context.Context, uuids []string) ([]*TaskData, error) {
+	dataDir, err := championDataDir(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := OpenChampion(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	tasks, err := reader.Tasks(ctx, uuids)
+	if err != nil {
+		return nil, err
+	}
+	// Dependencies on tasks outside the request still block
+	open, err := reader.OpenTasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	coefficients, _ := GetUrgencyCoefficients(ctx)
+	ComputeUrgency(append(tasks[:len(tasks):len(tasks)], open...), coefficients, time.Now())
+	return tasks, nil
+}
@@ -0,0 +1,200 @@
+package taskwarrior
+
+import (
+	"database/sql"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// createChampionDB writes a minimal TaskChampion database with the given tasks
+func createChampionDB(t *testing.T, dir string, tasks map[string]string, workingSet []string) {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", filepath.Join(dir, championFile))
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	stmts := []string{
+		"CREATE TABLE tasks (uuid STRING PRIMARY KEY, data STRING)",
+		"CREATE TABLE working_set (id INTEGER PRIMARY KEY, uuid STRING)",
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("Failed to create schema: %v", err)
+		}
+	}
+	for uuid, data := range tasks {
+		if _, err := db.Exec("INSERT INTO tasks (uuid, data) VALUES (?, ?)", uuid, data); err != nil {
+			t.Fatalf("Failed to insert task: %v", err)
+		}
+	}
+	for i, uuid := range workingSet {
+		if _, err := db.Exec("INSERT INTO working_set (id, uuid) VALUES (?, ?)", i+1, uuid); err != nil {
+			t.Fatalf("Failed to insert working set entry: %v", err)
+		}
+	}
+}
+
+func TestChampionReader(t *testing.T) {
+	dir := t.TempDir()
+	pending := "a1b2c3d4-0000-4000-8000-000000000001"
+	done := "a1b2c3d4-0000-4000-8000-000000000002"
+
+	createChampionDB(t, dir, map[string]string{
+		pending: `{"description":"Write docs","status":"pending","project":"tasksh","priority":"H","entry":"1717200000","modified":"1717286400","due":"1718409600","reviewed":"1717286400","scheduled":"1718064000","tag_docs":"","tag_review":"","annotation_1717286400":"Check examples","dep_b0000000-0000-4000-8000-000000000009":"","estimate":"2h"}`,
+		done:    `{"description":"Old work","status":"completed","entry":"1717200000","modified":"1717200000"}`,
+	}, []string{pending})
+
+	reader, err := OpenChampion(dir)
+	if err != nil {
+		t.Fatalf("OpenChampion failed: %v", err)
+	}
+	defer reader.Close()
+
+	tasks, err := reader.Tasks(t.Context(), []string{done, pending, "missing-uuid"})
+	if err != nil {
+		t.Fatalf("Tasks failed: %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("Expected 2 tasks, got %d", len(tasks))
+	}
+
+	if tasks[0].UUID != done || tasks[0].ID != 0 || tasks[0].Status != "completed" {
+		t.Errorf("Unexpected completed task: %+v", tasks[0])
+	}
+
+	task := tasks[1]
+	if task.ID != 1 {
+		t.Errorf("Expected working set ID 1, got %d", task.ID)
+	}
+	if task.Description != "Write docs" || task.Project != "tasksh" || task.Priority != "H" {
+		t.Errorf("Unexpected task fields: %+v", task)
+	}
+	if task.Due.String() != "20240615T000000Z" {
+		t.Errorf("Expected due 20240615T000000Z, got %s", task.Due)
+	}
+	if task.Entry.String() != "20240601T000000Z" || task.Reviewed.String() != "20240602T000000Z" || task.Scheduled.String() != "20240611T000000Z" {
+		t.Errorf("Unexpected dates: entry=%s reviewed=%s scheduled=%s", task.Entry, task.Reviewed, task.Scheduled)
+	}
+	if len(task.Tags) != 2 || task.Tags[0] != "docs" || task.Tags[1] != "review" {
+		t.Errorf("Expected tags [docs review], got %v", task.Tags)
+	}
+	if len(task.Annotations) != 1 || task.Annotations[0].Description != "Check examples" || task.Annotations[0].Entry.String() != "20240602T000000Z" {
+		t.Errorf("Unexpected annotations: %+v", task.Annotations)
+	}
+	if len(task.Depends) != 1 || task.Depends[0] != "b0000000-0000-4000-8000-000000000009" {
+		t.Errorf("Unexpected depends: %v", task.Depends)
+	}
+	if task.UDA["estimate"] != "2h" || len(task.UDA) != 1 {
+		t.Errorf("Expected estimate UDA only, got %v", task.UDA)
+	}
+}
+
+func TestOpenChampionRelative(t *testing.T) {
+	dir := t.TempDir()
+	createChampionDB(t, dir, map[string]string{
+		"a1b2c3d4-0000-4000-8000-000000000001": `{"description":"Write docs","status":"pending","entry":"1717200000"}`,
+	}, nil)
+	t.Chdir(filepath.Dir(dir))
+
+	reader, err := OpenChampion(filepath.Base(dir))
+	if err != nil {
+		t.Fatalf("OpenChampion failed: %v", err)
+	}
+	defer reader.Close()
+	tasks, err := reader.Tasks(t.Context(), []string{"a1b2c3d4-0000-4000-8000-000000000001"})
+	if err != nil || len(tasks) != 1 {
+		t.Fatalf("Expected the task from a relative data dir, got %v, %v", tasks, err)
+	}
+}
+
+func TestOpenChampionMissing(t *testing.T) {
+	if _, err := OpenChampion(t.TempDir()); err == nil {
+		t.Error("Expected error for missing TaskChampion database")
+	}
+}
+
+func TestDirectReadEnabled(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"", false},
+		{"0", false},
+		{"1", true},
+		{"true", true},
+		{"yes", false},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("TASKSH_DIRECT_READ", tt.value)
+		if got := DirectReadEnabled(); got != tt.want {
+			t.Errorf("DirectReadEnabled() with %q = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestGetTasksWithDataDirectRead(t *testing.T) {
+	dir := t.TempDir()
+	uuid := "a1b2c3d4-0000-4000-8000-000000000003"
+	createChampionDB(t, dir, map[string]string{
+		uuid: `{"description":"Direct","status":"pending","entry":"1717200000","modified":"1717200000"}`,
+	}, []string{uuid})
+
+	t.Setenv("TASKDATA", dir)
+	t.Setenv("TASKSH_DIRECT_READ", "1")
+
+	var progressCalls int
+	tasks, err := GetTasksWithDataProgress(t.Context(), []string{uuid}, func(loaded, total int) {
+		progressCalls++
+	})
+	if err != nil {
+		t.Fatalf("GetTasksWithDataProgress failed: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].Description != "Direct" {
+		t.Fatalf("Unexpected tasks: %+v", tasks)
+	}
+	if progressCalls != 1 {
+		t.Errorf("Expected 1 progress call, got %d", progressCalls)
+	}
+}
+
+func TestReadChampionTasksUrgency(t *testing.T) {
+	dir := t.TempDir()
+	blocker := "a1b2c3d4-0000-4000-8000-000000000004"
+	blocked := "a1b2c3d4-0000-4000-8000-000000000005"
+	createChampionDB(t, dir, map[string]string{
+		blocker: `{"description":"Blocker","status":"pending","priority":"H","entry":"1717200000","modified":"1717200000"}`,
+		blocked: `{"description":"Blocked","status":"pending","entry":"1717200000","modified":"1717200000","dep_` + blocker + `":""}`,
+	}, []string{blocker, blocked})
+
+	taskrc := filepath.Join(dir, "taskrc")
+	if err := os.WriteFile(taskrc, nil, 0o644); err != nil {
+		t.Fatalf("Failed to write taskrc: %v", err)
+	}
+	t.Setenv("TASKRC", taskrc)
+	t.Setenv("TASKDATA", dir)
+
+	// Only the blocker is requested; the task it blocks is still counted
+	tasks, err := readChampionTasks(t.Context(), []string{blocker})
+	if err != nil {
+		t.Fatalf("readChampionTasks failed: %v", err)
+	}
+	if len(tasks) != 1 {
+		t.Fatalf("Expected 1 task, got %d", len(tasks))
+	}
+
+	c := DefaultUrgencyCoefficients()
+	want := c.Urgency(tasks[0], false, true, time.Now())
+	if got := tasks[0].Urgency; math.Abs(got-want) > 0.01 {
+		t.Errorf("Urgency = %.2f, want %.2f", got, want)
+	}
+	if tasks[0].Urgency < c.UDA["priority.H"]+c.Blocking {
+		t.Errorf("Urgency %.2f misses the priority and blocking terms", tasks[0].Urgency)
+	}
+}
@@ -1,69 +1,124 @@
 package taskwarrior
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
+	"time"
 )
 
-// Task represents a Taskwarrior task
-type Task struct {
-	UUID        string
-	Description string
-	Project     string
-	Priority    string
-	Status      string
-	Due         string
-}
+// defaultCommandTimeout bounds a single task call when TASKSH_TIMEOUT is unset
+const defaultCommandTimeout = 30 * time.Second
 
 // CheckAvailable verifies that the task command is available
-func CheckAvailable() error {
-	cmd := exec.Command("task", "version")
-	if err := cmd.Run(); err != nil {
+func CheckAvailable(ctx context.Context) error {
+	if err := runTask(ctx, []string{"version"}, nil, nil, nil); err != nil {
 		return fmt.Errorf("task command not found: %w", err)
 	}
 	return nil
 }
 
-// executeTask runs a task command and returns the output
-func executeTask(args ...string) (string, error) {
-	cmd := exec.Command("task", args...)
-	output, err := cmd.Output()
-	if err != nil {
-		return "", fmt.Errorf("task command failed: %w", err)
+// executeTask runs a task command and returns the output. Failures are
+// returned as a *TaskError carrying the captured stderr.
+func executeTask(ctx context.Context, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	if err := runTask(ctx, args, nil, &stdout, &stderr); err != nil {
+		return "", newTaskError(args, stderr.String(), err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// CommandTimeout returns the per-call timeout for task commands, read from
+// TASKSH_TIMEOUT as a duration ("45s") or whole seconds ("45"). 0 disables it.
+func CommandTimeout() time.Duration {
+	val := os.Getenv("TASKSH_TIMEOUT")
+	if val == "" {
+		return defaultCommandTimeout
+	}
+	if secs, err := strconv.Atoi(val); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if d, err := time.ParseDuration(val); err == nil && d >= 0 {
+		return d
 	}
-	return strings.TrimSpace(string(output)), nil
+	return defaultCommandTimeout
 }
 
-// EnsureReviewConfig sets up the required UDA and report for review
-func EnsureReviewConfig() error {
+// runTask runs a task command that is killed when ctx is done or the
+// per-call timeout expires
+func runTask(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if timeout := CommandTimeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, "task", args...)
+	cmd.Env = commandEnv(ctx)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	// Hook processes can hold the output pipes open after task is killed
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	if err != nil && ctx.Err() != nil {
+		// Report the cancellation or timeout rather than "signal: killed"
+		return ctx.Err()
+	}
+	return err
+}
+
+// ReviewFilter selects pending or waiting tasks not reviewed within the
+// default queue's interval. It is stored as the _reviewed report filter.
+var ReviewFilter = reviewDue(DefaultReviewQueue.Interval)
+
+// EnsureReviewConfig sets up the required UDA and report for review,
+// reporting each setting it adds to w
+func EnsureReviewConfig(ctx context.Context, w io.Writer) error {
 	// Check if reviewed UDA exists
-	output, err := executeTask("_get", "rc.uda.reviewed.type")
-	if err != nil || output != "date" {
-		fmt.Println("Setting up 'reviewed' UDA...")
-		if _, err := executeTask("rc.confirmation:no", "rc.verbose:nothing", "config", "uda.reviewed.type", "date"); err != nil {
+	if getConfig(ctx, "uda.reviewed.type") != "date" {
+		fmt.Fprintln(w, "Setting up 'reviewed' UDA...")
+		if _, err := executeTask(ctx, "rc.confirmation:no", "rc.verbose:nothing", "config", "uda.reviewed.type", "date"); err != nil {
 			return fmt.Errorf("failed to set reviewed UDA type: %w", err)
 		}
-		if _, err := executeTask("rc.confirmation:no", "rc.verbose:nothing", "config", "uda.reviewed.label", "Reviewed"); err != nil {
+		if _, err := executeTask(ctx, "rc.confirmation:no", "rc.verbose:nothing", "config", "uda.reviewed.label", "Reviewed"); err != nil {
 			return fmt.Errorf("failed to set reviewed UDA label: %w", err)
 		}
 	}
 
+	// Wait reasons are kept in UDAs so they can be queried
+	for _, uda := range WaitUDAs {
+		if getConfig(ctx, "uda."+uda.Name+".type") == uda.Type {
+			continue
+		}
+		fmt.Fprintf(w, "Setting up '%s' UDA...\n", uda.Name)
+		if _, err := executeTask(ctx, "rc.confirmation:no", "rc.verbose:nothing", "config", "uda."+uda.Name+".type", uda.Type); err != nil {
+			return fmt.Errorf("failed to set %s UDA type: %w", uda.Name, err)
+		}
+		if _, err := executeTask(ctx, "rc.confirmation:no", "rc.verbose:nothing", "config", "uda."+uda.Name+".label", uda.Label); err != nil {
+			return fmt.Errorf("failed to set %s UDA label: %w", uda.Name, err)
+		}
+	}
+
 	// Check if _reviewed report exists
-	output, err = executeTask("_get", "rc.report._reviewed.columns")
-	if err != nil || output != "uuid" {
-		fmt.Println("Setting up '_reviewed' report...")
+	if getConfig(ctx, "report._reviewed.columns") != "uuid" {
+		fmt.Fprintln(w, "Setting up '_reviewed' report...")
 		reportArgs := [][]string{
 			{"rc.confirmation:no", "rc.verbose:nothing", "config", "report._reviewed.description", "Tasksh review report. Adjust the filter to your needs."},
 			{"rc.confirmation:no", "rc.verbose:nothing", "config", "report._reviewed.columns", "uuid"},
 			{"rc.confirmation:no", "rc.verbose:nothing", "config", "report._reviewed.sort", "reviewed+,modified+"},
-			{"rc.confirmation:no", "rc.verbose:nothing", "config", "report._reviewed.filter", "( reviewed.none: or reviewed.before:now-6days ) and ( +PENDING or +WAITING )"},
+			{"rc.confirmation:no", "rc.verbose:nothing", "config", "report._reviewed.filter", ReviewFilter.String()},
 		}
 		
 		for _, args := range reportArgs {
-			if _, err := executeTask(args...); err != nil {
+			if _, err := executeTask(ctx, args...); err != nil {
 				return fmt.Errorf("failed to configure _reviewed report: %w", err)
 			}
 		}
@@ -72,10 +127,24 @@ func EnsureReviewConfig() error {
 	return nil
 }
 
+// getConfig reads a setting from the taskrc, falling back to `task _get`
+// when the taskrc can't be read
+func getConfig(ctx context.Context, name string) string {
+	if rc, err := loadTaskrc(ctx); err == nil {
+		value, _ := rc.Get(name)
+		return value
+	}
+	output, err := executeTask(ctx, "_get", "rc."+name)
+	if err != nil {
+		return ""
+	}
+	return output
+}
+
 
 // GetTasksForReview returns a list of task UUIDs that need review
-func GetTasksForReview() ([]string, error) {
-	output, err := executeTask(
+func GetTasksForReview(ctx context.Context) ([]string, error) {
+	output, err := executeTask(ctx,
 		"rc.color=off",
 		"rc.detection=off",
 		"rc._forcecolor=off",
@@ -93,31 +162,52 @@ func GetTasksForReview() ([]string, error) {
 	return strings.Split(output, "\n"), nil
 }
 
-// TaskData represents the full task data from JSON export
-type TaskData struct {
-	ID          int     `json:"id"`
-	UUID        string  `json:"uuid"`
-	Description string  `json:"description"`
-	Project     string  `json:"project,omitempty"`
-	Priority    string  `json:"priority,omitempty"`
-	Status      string  `json:"status"`
-	Due         string  `json:"due,omitempty"`
-	Wait        string  `json:"wait,omitempty"`
-	Entry       string  `json:"entry"`
-	Modified    string  `json:"modified"`
-	Reviewed    string  `json:"reviewed,omitempty"`
-	Urgency     float64 `json:"urgency"`
+// GetTasksForQueue returns the UUIDs of the tasks due for review in a queue,
+// running the _reviewed report with the queue's filter and sort and then
+// applying its interval rules
+func GetTasksForQueue(ctx context.Context, queue ReviewQueue) ([]string, error) {
+	expr, err := queue.Expr()
+	if err != nil {
+		return nil, err
+	}
+	output, err := executeTask(ctx,
+		"rc.color=off",
+		"rc.detection=off",
+		"rc._forcecolor=off",
+		"rc.verbose=nothing",
+		"rc.report._reviewed.filter="+expr.String(),
+		"rc.report._reviewed.sort="+queue.SortSpec(),
+		"_reviewed",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tasks for review queue %s: %w", queue.Name, err)
+	}
+
+	if output == "" {
+		return []string{}, nil
+	}
+	uuids := strings.Split(output, "\n")
+	if len(queue.Rules) == 0 {
+		return uuids, nil
+	}
+
+	// Interval rules are evaluated here rather than in the report filter
+	tasks, err := GetTasksWithDataProgress(ctx, uuids, nil)
+	if err != nil {
+		return nil, err
+	}
+	return queue.Due(tasks, time.Now()), nil
 }
 
 // GetTasksForReviewWithData returns tasks that need review with full data
-func GetTasksForReviewWithData() ([]*TaskData, error) {
-	return GetTasksForReviewWithDataProgress(nil)
+func GetTasksForReviewWithData(ctx context.Context) ([]*TaskData, error) {
+	return GetTasksForReviewWithDataProgress(ctx, nil)
 }
 
 // GetTasksForReviewWithDataProgress returns tasks with optional progress callback
-func GetTasksForReviewWithDataProgress(progressFn func(loaded, total int)) ([]*TaskData, error) {
+func GetTasksForReviewWithDataProgress(ctx context.Context, progressFn func(loaded, total int)) ([]*TaskData, error) {
 	// First get the UUIDs using the existing method
-	uuids, err := GetTasksForReview()
+	uuids, err := GetTasksForReview(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get task UUIDs: %w", err)
 	}
@@ -127,20 +217,31 @@ func GetTasksForReviewWithDataProgress(progressFn func(loaded, total int)) ([]*T
 	}
 
 	// Use the shared batch loading utility with progress
-	return GetTasksWithDataProgress(uuids, progressFn)
+	return GetTasksWithDataProgress(ctx, uuids, progressFn)
 }
 
 // GetTasksWithData loads full task data for the given UUIDs using batch export
-func GetTasksWithData(uuids []string) ([]*TaskData, error) {
-	return GetTasksWithDataProgress(uuids, nil)
+func GetTasksWithData(ctx context.Context, uuids []string) ([]*TaskData, error) {
+	return GetTasksWithDataProgress(ctx, uuids, nil)
 }
 
 // GetTasksWithDataProgress loads full task data with optional progress callback
-func GetTasksWithDataProgress(uuids []string, progressFn func(loaded, total int)) ([]*TaskData, error) {
+func GetTasksWithDataProgress(ctx context.Context, uuids []string, progressFn func(loaded, total int)) ([]*TaskData, error) {
 	if len(uuids) == 0 {
 		return []*TaskData{}, nil
 	}
 
+	// Read straight from the TaskChampion database when enabled, falling
+	// back to export if it is unavailable
+	if DirectReadEnabled() {
+		if tasks, err := readChampionTasks(ctx, uuids); err == nil {
+			if progressFn != nil {
+				progressFn(len(uuids), len(uuids))
+			}
+			return tasks, nil
+		}
+	}
+
 	// Chunk UUIDs to avoid command line length limits
 	// Task command line can handle ~100 UUIDs safely
 	const chunkSize = 100
@@ -160,7 +261,7 @@ func GetTasksWithDataProgress(uuids []string, progressFn func(loaded, total int)
 			args = append(args, "uuid:"+uuid)
 		}
 		
-		output, err := executeTask(args...)
+		output, err := executeTask(ctx, args...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to export tasks: %w", err)
 		}
@@ -187,49 +288,42 @@ func GetTasksWithDataProgress(uuids []string, progressFn func(loaded, total int)
 }
 
 // BatchLoadTasks loads task data as Task structs (for compatibility)
-func BatchLoadTasks(uuids []string) (map[string]*Task, error) {
-	taskData, err := GetTasksWithData(uuids)
-	if err != nil {
-		return nil, err
+func BatchLoadTasks(ctx context.Context, uuids []string) (map[string]*Task, error) {
+	return BatchLoadTasksFrom(ctx, NewExecBackend(), uuids)
+}
+
+// FilterUUIDs returns the UUIDs of tasks matching the given filter arguments
+func FilterUUIDs(ctx context.Context, filter []string) ([]string, error) {
+	args := []string{
+		"rc.color=off",
+		"rc.detection=off",
+		"rc._forcecolor=off",
+		"rc.verbose=nothing",
 	}
+	args = append(args, filter...)
+	args = append(args, "uuids")
 
-	taskMap := make(map[string]*Task)
-	for _, td := range taskData {
-		taskMap[td.UUID] = &Task{
-			UUID:        td.UUID,
-			Description: td.Description,
-			Project:     td.Project,
-			Priority:    td.Priority,
-			Status:      td.Status,
-			Due:         td.Due,
-		}
+	output, err := executeTask(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter tasks: %w", err)
 	}
 
-	return taskMap, nil
+	return strings.Fields(output), nil
 }
 
 // GetTaskInfo retrieves detailed information about a task
-func GetTaskInfo(uuid string) (*Task, error) {
-	// Get task description
-	desc, err := executeTask("_get", uuid+".description")
+func GetTaskInfo(ctx context.Context, uuid string) (*Task, error) {
+	output, err := executeTask(ctx, "rc.json.array=off", "uuid:"+uuid, "export")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get task description: %w", err)
+		return nil, fmt.Errorf("failed to get task info: %w", err)
+	}
+	if output == "" {
+		return nil, fmt.Errorf("task not found: %s", uuid)
 	}
 
-	// Get other task details
-	project, _ := executeTask("_get", uuid+".project")
-	priority, _ := executeTask("_get", uuid+".priority")
-	status, _ := executeTask("_get", uuid+".status")
-	due, _ := executeTask("_get", uuid+".due")
-
-	return &Task{
-		UUID:        uuid,
-		Description: strings.TrimSpace(desc),
-		Project:     strings.TrimSpace(project),
-		Priority:    strings.TrimSpace(priority),
-		Status:      strings.TrimSpace(status),
-		Due:         strings.TrimSpace(due),
-	}, nil
+	// With json.array off, export prints one task per line
+	line, _, _ := strings.Cut(output, "\n")
+	return parseTask([]byte(line))
 }
 
 // ShowTaskInfo displays detailed task information using the task command
@@ -248,7 +342,7 @@ func CreateEditCommand(uuid string) *exec.Cmd {
 }
 
 // EditTask opens the task for editing (legacy function for non-Bubble Tea contexts)
-func EditTask(uuid string) error {
+func EditTask(ctx context.Context, uuid string) error {
 	cmd := exec.Command("task", "rc.confirmation:no", "rc.verbose:nothing", uuid, "edit")
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
@@ -258,94 +352,196 @@ func EditTask(uuid string) error {
 	}
 
 	// Mark as reviewed after editing
-	return MarkTaskReviewed(uuid)
+	return MarkTaskReviewed(ctx, uuid)
 }
 
 // ModifyTask applies modifications to a task
-func ModifyTask(uuid, modifications string) error {
-	args := []string{"rc.confirmation:no", "rc.verbose:nothing", uuid, "modify"}
+func ModifyTask(ctx context.Context, uuid, modifications string) error {
+	// Changes to a recurring instance stay on that instance; callers apply
+	// them to the rest of the series themselves
+	args := []string{"rc.confirmation:no", "rc.recurrence.confirmation:no", "rc.verbose:nothing", uuid, "modify"}
 	args = append(args, strings.Fields(modifications)...)
 	
-	if _, err := executeTask(args...); err != nil {
+	if _, err := executeTask(ctx, args...); err != nil {
 		return fmt.Errorf("failed to modify task: %w", err)
 	}
 	return nil
 }
 
 // CompleteTask marks a task as completed
-func CompleteTask(uuid string) error {
-	if _, err := executeTask("rc.confirmation:no", "rc.verbose:nothing", uuid, "done"); err != nil {
+func CompleteTask(ctx context.Context, uuid string) error {
+	if _, err := executeTask(ctx, "rc.confirmation:no", "rc.verbose:nothing", uuid, "done"); err != nil {
 		return fmt.Errorf("failed to complete task: %w", err)
 	}
 	return nil
 }
 
 // DeleteTask deletes a task
-func DeleteTask(uuid string) error {
-	if _, err := executeTask("rc.confirmation:no", "rc.verbose:nothing", uuid, "delete"); err != nil {
+func DeleteTask(ctx context.Context, uuid string) error {
+	if _, err := executeTask(ctx, "rc.confirmation:no", "rc.recurrence.confirmation:no", "rc.verbose:nothing", uuid, "delete"); err != nil {
 		return fmt.Errorf("failed to delete task: %w", err)
 	}
 	return nil
 }
 
 // MarkTaskReviewed marks a task as reviewed
-func MarkTaskReviewed(uuid string) error {
-	if _, err := executeTask("rc.confirmation:no", "rc.verbose:nothing", uuid, "modify", "reviewed:now"); err != nil {
+func MarkTaskReviewed(ctx context.Context, uuid string) error {
+	if _, err := executeTask(ctx, "rc.confirmation:no", "rc.verbose:nothing", uuid, "modify", "reviewed:now"); err != nil {
 		return fmt.Errorf("failed to mark task as reviewed: %w", err)
 	}
 	return nil
 }
 
-// WaitTask sets a task to waiting status with specified date and optional reason
-func WaitTask(uuid, waitUntil, reason string) error {
-	args := []string{"rc.confirmation:no", "rc.verbose:nothing", uuid, "modify", "wait:" + waitUntil}
-	
-	// Add reason as annotation if provided
-	if reason != "" {
-		args = append(args, "+waiting")
-		// Add annotation with reason
-		if _, err := executeTask("rc.confirmation:no", "rc.verbose:nothing", uuid, "annotate", "Wait reason: "+reason); err != nil {
-			// Don't fail if annotation fails, just log
-			fmt.Printf("Warning: Could not add wait reason annotation: %v\n", err)
-		}
-	} else {
-		args = append(args, "+waiting")
+// StartTask marks a task as active, setting its start date to now
+func StartTask(ctx context.Context, uuid string) error {
+	if _, err := executeTask(ctx, "rc.confirmation:no", "rc.verbose:nothing", uuid, "start"); err != nil {
+		return fmt.Errorf("failed to start task: %w", err)
 	}
-	
-	if _, err := executeTask(args...); err != nil {
+	return nil
+}
+
+// StopTask marks an active task as no longer active, removing its start date
+func StopTask(ctx context.Context, uuid string) error {
+	if _, err := executeTask(ctx, "rc.confirmation:no", "rc.verbose:nothing", uuid, "stop"); err != nil {
+		return fmt.Errorf("failed to stop task: %w", err)
+	}
+	return nil
+}
+
+// WaitTask hides a task until waitUntil, recording the optional reason and
+// the person it waits on in the wait UDAs
+func WaitTask(ctx context.Context, uuid, waitUntil, reason, waitingOn string) error {
+	args := []string{"rc.confirmation:no", "rc.verbose:nothing", uuid, "modify"}
+	args = append(args, WaitModifications(waitUntil, reason, waitingOn, time.Now())...)
+	if _, err := executeTask(ctx, args...); err != nil {
 		return fmt.Errorf("failed to set task to waiting: %w", err)
 	}
 	return nil
 }
 
+// batchArgs starts a command on several tasks. rc.bulk:0 stops Taskwarrior
+// asking before it changes more than a few tasks.
+func batchArgs(uuids []string, command string) []string {
+	args := []string{"rc.confirmation:no", "rc.bulk:0", "rc.recurrence.confirmation:no", "rc.verbose:nothing"}
+	args = append(args, uuids...)
+	return append(args, command)
+}
+
+// ModifyTasks applies the same modifications to several tasks in one call
+func ModifyTasks(ctx context.Context, uuids []string, modifications []string) error {
+	// Without UUIDs the command would modify every task
+	if len(uuids) == 0 {
+		return nil
+	}
+	args := append(batchArgs(uuids, "modify"), modifications...)
+	if _, err := executeTask(ctx, args...); err != nil {
+		return fmt.Errorf("failed to modify tasks: %w", err)
+	}
+	return nil
+}
+
+// CompleteTasks marks several tasks as completed in one call
+func CompleteTasks(ctx context.Context, uuids []string) error {
+	if len(uuids) == 0 {
+		return nil
+	}
+	if _, err := executeTask(ctx, batchArgs(uuids, "done")...); err != nil {
+		return fmt.Errorf("failed to complete tasks: %w", err)
+	}
+	return nil
+}
+
+// DeleteTasks deletes several tasks in one call
+func DeleteTasks(ctx context.Context, uuids []string) error {
+	if len(uuids) == 0 {
+		return nil
+	}
+	if _, err := executeTask(ctx, batchArgs(uuids, "delete")...); err != nil {
+		return fmt.Errorf("failed to delete tasks: %w", err)
+	}
+	return nil
+}
+
+// AnnotateTask adds an annotation to a task
+func AnnotateTask(ctx context.Context, uuid, text string) error {
+	if _, err := executeTask(ctx, "rc.confirmation:no", "rc.verbose:nothing", uuid, "annotate", "--", text); err != nil {
+		return fmt.Errorf("failed to annotate task: %w", err)
+	}
+	return nil
+}
+
+// DenotateTask removes the annotation with the given text from a task
+func DenotateTask(ctx context.Context, uuid, text string) error {
+	if _, err := executeTask(ctx, "rc.confirmation:no", "rc.verbose:nothing", uuid, "denotate", "--", text); err != nil {
+		return fmt.Errorf("failed to remove annotation: %w", err)
+	}
+	return nil
+}
+
 // SetDueDate sets or updates the due date for a task
-func SetDueDate(uuid, dueDate string) error {
-	if _, err := executeTask("rc.confirmation:no", "rc.verbose:nothing", uuid, "modify", "due:"+dueDate); err != nil {
+func SetDueDate(ctx context.Context, uuid, dueDate string) error {
+	if _, err := executeTask(ctx, "rc.confirmation:no", "rc.verbose:nothing", uuid, "modify", "due:"+dueDate); err != nil {
 		return fmt.Errorf("failed to set due date: %w", err)
 	}
 	return nil
 }
 
 // RemoveDueDate removes the due date from a task
-func RemoveDueDate(uuid string) error {
-	if _, err := executeTask("rc.confirmation:no", "rc.verbose:nothing", uuid, "modify", "due:"); err != nil {
+func RemoveDueDate(ctx context.Context, uuid string) error {
+	if _, err := executeTask(ctx, "rc.confirmation:no", "rc.verbose:nothing", uuid, "modify", "due:"); err != nil {
 		return fmt.Errorf("failed to remove due date: %w", err)
 	}
 	return nil
 }
 
-// RemoveWaitDate removes the wait date from a task
-func RemoveWaitDate(uuid string) error {
-	args := []string{"rc.confirmation:no", "rc.verbose:nothing", uuid, "modify", "wait:", "-waiting"}
-	if _, err := executeTask(args...); err != nil {
+// RemoveWaitDate removes the wait date, the waiting tag and the wait UDAs
+// from a task, along with wait reason annotations older versions recorded
+func RemoveWaitDate(ctx context.Context, uuid string) error {
+	args := append([]string{"rc.confirmation:no", "rc.verbose:nothing", uuid, "modify"}, UnwaitModifications...)
+	if _, err := executeTask(ctx, args...); err != nil {
 		return fmt.Errorf("failed to remove wait date: %w", err)
 	}
+
+	task, err := GetTaskInfo(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	for _, text := range LegacyWaitAnnotations(task.Annotations) {
+		if err := DenotateTask(ctx, uuid, text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExecuteCommand runs an arbitrary task command and returns its combined output
+func ExecuteCommand(ctx context.Context, args []string) (string, error) {
+	var output bytes.Buffer
+	if err := runTask(ctx, args, nil, &output, &output); err != nil {
+		return output.String(), newTaskError(args, output.String(), err)
+	}
+	return output.String(), nil
+}
+
+// RestoreTask replaces a task with the given data using `task import`, which
+// also removes attributes the data doesn't have
+func RestoreTask(ctx context.Context, td *TaskData) error {
+	data, err := json.Marshal(td)
+	if err != nil {
+		return fmt.Errorf("failed to encode task %s: %w", td.UUID, err)
+	}
+
+	args := []string{"rc.confirmation:no", "rc.verbose:nothing", "import"}
+	var stderr bytes.Buffer
+	if err := runTask(ctx, args, bytes.NewReader(data), io.Discard, &stderr); err != nil {
+		return fmt.Errorf("failed to restore task: %w", newTaskError(args, stderr.String(), err))
+	}
 	return nil
 }
 
 // UndoLastAction undoes the most recent taskwarrior change
-func UndoLastAction() error {
-	output, err := executeTask("rc.confirmation:no", "undo")
+func UndoLastAction(ctx context.Context) error {
+	output, err := executeTask(ctx, "rc.confirmation:no", "undo")
 	if err != nil {
 		return fmt.Errorf("failed to undo last action: %w", err)
 	}
@@ -359,8 +555,17 @@ func UndoLastAction() error {
 }
 
 // GetContexts returns a list of available contexts
-func GetContexts() ([]string, error) {
-	output, err := executeTask("context")
+func GetContexts(ctx context.Context) ([]string, error) {
+	if rc, err := loadTaskrc(ctx); err == nil {
+		var contexts []string
+		for _, def := range rc.Contexts() {
+			contexts = append(contexts, def.Name)
+		}
+		return append(contexts, "none"), nil
+	}
+
+	// Without a readable taskrc, parse the `task context` listing
+	output, err := executeTask(ctx, "context")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get contexts: %w", err)
 	}
@@ -404,16 +609,20 @@ func GetContexts() ([]string, error) {
 }
 
 // SetContext switches to the specified context
-func SetContext(contextName string) error {
-	if _, err := executeTask("context", contextName); err != nil {
+func SetContext(ctx context.Context, contextName string) error {
+	if _, err := executeTask(ctx, "context", contextName); err != nil {
 		return fmt.Errorf("failed to set context: %w", err)
 	}
 	return nil
 }
 
 // GetCurrentContext returns the currently active context
-func GetCurrentContext() (string, error) {
-	output, err := executeTask("context")
+func GetCurrentContext(ctx context.Context) (string, error) {
+	if rc, err := loadTaskrc(ctx); err == nil {
+		return rc.CurrentContext(), nil
+	}
+
+	output, err := executeTask(ctx, "context")
 	if err != nil {
 		return "", fmt.Errorf("failed to get current context: %w", err)
 	}
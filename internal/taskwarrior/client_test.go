@@ -0,0 +1,88 @@
+package taskwarrior
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCommandTimeout(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", defaultCommandTimeout},
+		{"45", 45 * time.Second},
+		{"1m30s", 90 * time.Second},
+		{"0", 0},
+		{"-5", defaultCommandTimeout},
+		{"soon", defaultCommandTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("TASKSH_TIMEOUT", tt.value)
+		if got := CommandTimeout(); got != tt.want {
+			t.Errorf("CommandTimeout() with %q = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+// installFakeTask puts a task script that runs body at the front of PATH.
+// TASKRC points at a missing file so config is read through the script.
+func installFakeTask(t *testing.T, body string) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("TASKRC", filepath.Join(dir, "taskrc"))
+	script := "#!/bin/sh\n" + body + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "task"), []byte(script), 0o755); err != nil {
+		t.Fatalf("Failed to write fake task: %v", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestExecuteTaskTimeout(t *testing.T) {
+	installFakeTask(t, "exec sleep 5")
+	t.Setenv("TASKSH_TIMEOUT", "100ms")
+
+	start := time.Now()
+	_, err := executeTask(t.Context(), "export")
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Timeout took too long: %v", elapsed)
+	}
+
+	var taskErr *TaskError
+	if !errors.As(err, &taskErr) || taskErr.Kind != ErrTimedOut {
+		t.Fatalf("Expected timeout TaskError, got %v", err)
+	}
+	if taskErr.Hint() == "" {
+		t.Error("Expected a hint for timeouts")
+	}
+}
+
+func TestExecuteTaskCancel(t *testing.T) {
+	installFakeTask(t, "exec sleep 5")
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	_, err := executeTask(ctx, "export")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestExecuteTaskCapturesStderr(t *testing.T) {
+	installFakeTask(t, "echo 'No matches.' >&2; exit 1")
+
+	_, err := executeTask(t.Context(), "uuid:missing", "done")
+	var taskErr *TaskError
+	if !errors.As(err, &taskErr) {
+		t.Fatalf("Expected *TaskError, got %v", err)
+	}
+	if taskErr.ExitCode != 1 || taskErr.Kind != ErrNoMatches || taskErr.Stderr != "No matches." {
+		t.Errorf("Unexpected error fields: %+v", taskErr)
+	}
+}
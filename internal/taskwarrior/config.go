@@ -1,10 +1,14 @@
 package taskwarrior
 
-import "strings"
+import (
+	"context"
+	"fmt"
+	"strings"
+)
 
 // GetProjects returns a list of existing projects
-func GetProjects() ([]string, error) {
-	output, err := executeTask("rc.verbose:nothing", "_projects")
+func GetProjects(ctx context.Context) ([]string, error) {
+	output, err := executeTask(ctx, "rc.verbose:nothing", "_projects")
 	if err != nil {
 		return []string{}, nil // Return empty list if no projects
 	}
@@ -25,8 +29,8 @@ func GetProjects() ([]string, error) {
 }
 
 // GetTags returns a list of existing tags
-func GetTags() ([]string, error) {
-	output, err := executeTask("rc.verbose:nothing", "_tags")
+func GetTags(ctx context.Context) ([]string, error) {
+	output, err := executeTask(ctx, "rc.verbose:nothing", "_tags")
 	if err != nil {
 		return []string{}, nil // Return empty list if no tags
 	}
@@ -46,6 +50,15 @@ func GetTags() ([]string, error) {
 	return filtered, nil
 }
 
+// GetUDAs returns the user defined attributes declared in the taskrc
+func GetUDAs(ctx context.Context) ([]UDADefinition, error) {
+	rc, err := loadTaskrc(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get UDAs: %w", err)
+	}
+	return rc.UDAs(), nil
+}
+
 // GetPriorities returns available priority levels
 func GetPriorities() []string {
 	return []string{"priority:H", "priority:M", "priority:L", "priority:"}
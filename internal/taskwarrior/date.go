@@ -0,0 +1,125 @@
+package taskwarrior
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/emiller/tasksh/internal/taskwarrior/filter"
+)
+
+// exportDateFormat is the compact ISO 8601 format used by task export
+const exportDateFormat = "20060102T150405Z"
+
+// Date is a Taskwarrior timestamp. The zero Date means the attribute is
+// unset. Dates are kept in UTC and rendered in local time.
+type Date struct {
+	time.Time
+}
+
+// NewDate wraps t as a Date, dropping sub-second precision as Taskwarrior does
+func NewDate(t time.Time) Date {
+	if t.IsZero() {
+		return Date{}
+	}
+	return Date{t.UTC().Truncate(time.Second)}
+}
+
+// ParseDate parses the compact export format (20240615T100000Z) or the
+// extended format (2024-06-15T10:00:00Z). An empty value is the zero Date.
+func ParseDate(value string) (Date, error) {
+	if value == "" {
+		return Date{}, nil
+	}
+	t, ok := filter.ParseDate(value)
+	if !ok {
+		return Date{}, fmt.Errorf("invalid date %q", value)
+	}
+	return NewDate(t), nil
+}
+
+// String returns the date in the compact export format, or "" if unset
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.UTC().Format(exportDateFormat)
+}
+
+// MarshalJSON encodes the date in the compact export format
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON decodes a date in either export format
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("invalid date value: %s", data)
+	}
+	parsed, err := ParseDate(value)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// LocalString renders the date in local time, leaving out the time of day
+// at local midnight, or "" if unset
+func (d Date) LocalString() string {
+	if d.IsZero() {
+		return ""
+	}
+	local := d.Local()
+	if local.Hour() == 0 && local.Minute() == 0 && local.Second() == 0 {
+		return local.Format("2006-01-02")
+	}
+	return local.Format("2006-01-02 15:04")
+}
+
+// Relative describes the date relative to now, e.g. "in 3d" or "2h ago"
+func (d Date) Relative(now time.Time) string {
+	if d.IsZero() {
+		return ""
+	}
+	diff := d.Sub(now)
+	if diff < 0 {
+		return formatSpan(-diff) + " ago"
+	}
+	return "in " + formatSpan(diff)
+}
+
+// DueRelative describes a due date relative to now, e.g. "in 3d" or
+// "overdue 2h"
+func (d Date) DueRelative(now time.Time) string {
+	if d.IsZero() {
+		return ""
+	}
+	if diff := d.Sub(now); diff < 0 {
+		return "overdue " + formatSpan(-diff)
+	}
+	return d.Relative(now)
+}
+
+// formatSpan renders a duration in the largest whole unit, as in
+// Taskwarrior's relative date columns
+func formatSpan(d time.Duration) string {
+	const day = 24 * time.Hour
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d/time.Second))
+	case d < time.Hour:
+		return fmt.Sprintf("%dmin", int(d/time.Minute))
+	case d < day:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	case d < 14*day:
+		return fmt.Sprintf("%dd", int(d/day))
+	case d < 90*day:
+		return fmt.Sprintf("%dw", int(d/(7*day)))
+	case d < 365*day:
+		return fmt.Sprintf("%dmo", int(d/(30*day)))
+	default:
+		return fmt.Sprintf("%dy", int(d/(365*day)))
+	}
+}
@@ -0,0 +1,95 @@
+package taskwarrior
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	want := time.Date(2024, 6, 15, 10, 0, 0, 0, time.UTC)
+	for _, value := range []string{"20240615T100000Z", "2024-06-15T10:00:00Z", "2024-06-15T12:00:00+02:00"} {
+		d, err := ParseDate(value)
+		if err != nil {
+			t.Fatalf("ParseDate(%q) failed: %v", value, err)
+		}
+		if !d.Equal(want) || d.String() != "20240615T100000Z" {
+			t.Errorf("ParseDate(%q) = %s, want %s", value, d, want)
+		}
+	}
+
+	if d, err := ParseDate(""); err != nil || !d.IsZero() {
+		t.Errorf("ParseDate(\"\") = %v, %v; want zero date", d, err)
+	}
+	if _, err := ParseDate("next tuesday"); err == nil {
+		t.Error("Expected error for invalid date")
+	}
+}
+
+func TestDateJSONRoundTrip(t *testing.T) {
+	data := `{"uuid":"u","description":"d","status":"pending","due":"2024-06-15T10:00:00Z","entry":"20240601T000000Z","modified":"20240601T000000Z","urgency":0}`
+
+	var td TaskData
+	if err := json.Unmarshal([]byte(data), &td); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	encoded, err := json.Marshal(td)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	got := string(encoded)
+	if !strings.Contains(got, `"due":"20240615T100000Z"`) || !strings.Contains(got, `"entry":"20240601T000000Z"`) {
+		t.Errorf("Dates not encoded in export format: %s", got)
+	}
+	if strings.Contains(got, `"wait"`) || strings.Contains(got, `"scheduled"`) {
+		t.Errorf("Unset dates should be omitted: %s", got)
+	}
+
+	var bad TaskData
+	if err := json.Unmarshal([]byte(`{"uuid":"u","due":"soon"}`), &bad); err == nil {
+		t.Error("Expected error for invalid due date")
+	}
+}
+
+func TestDateLocalString(t *testing.T) {
+	midnight := NewDate(time.Date(2024, 6, 15, 0, 0, 0, 0, time.Local))
+	if got := midnight.LocalString(); got != "2024-06-15" {
+		t.Errorf("LocalString() = %q, want 2024-06-15", got)
+	}
+	afternoon := NewDate(time.Date(2024, 6, 15, 14, 30, 0, 0, time.Local))
+	if got := afternoon.LocalString(); got != "2024-06-15 14:30" {
+		t.Errorf("LocalString() = %q, want 2024-06-15 14:30", got)
+	}
+	if got := (Date{}).LocalString(); got != "" {
+		t.Errorf("LocalString() of zero date = %q", got)
+	}
+}
+
+func TestDateRelative(t *testing.T) {
+	now := time.Date(2024, 6, 12, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		offset  time.Duration
+		want    string
+		wantDue string
+	}{
+		{3*24*time.Hour + time.Hour, "in 3d", "in 3d"},
+		{-2*time.Hour - 10*time.Minute, "2h ago", "overdue 2h"},
+		{45 * time.Minute, "in 45min", "in 45min"},
+		{-30 * time.Second, "30s ago", "overdue 30s"},
+		{21 * 24 * time.Hour, "in 3w", "in 3w"},
+		{-120 * 24 * time.Hour, "4mo ago", "overdue 4mo"},
+		{800 * 24 * time.Hour, "in 2y", "in 2y"},
+	}
+
+	for _, tt := range tests {
+		d := NewDate(now.Add(tt.offset))
+		if got := d.Relative(now); got != tt.want {
+			t.Errorf("Relative(%v) = %q, want %q", tt.offset, got, tt.want)
+		}
+		if got := d.DueRelative(now); got != tt.wantDue {
+			t.Errorf("DueRelative(%v) = %q, want %q", tt.offset, got, tt.wantDue)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package taskwarrior
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ErrorKind classifies why a task command failed
+type ErrorKind int
+
+const (
+	ErrUnknown ErrorKind = iota
+	ErrNotInstalled
+	ErrLockHeld
+	ErrNoMatches
+	ErrInvalidFilter
+	ErrHookRejected
+	ErrTimedOut
+	ErrCanceled
+)
+
+// String returns a short name for the error kind
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrNotInstalled:
+		return "not installed"
+	case ErrLockHeld:
+		return "lock held"
+	case ErrNoMatches:
+		return "no matches"
+	case ErrInvalidFilter:
+		return "invalid filter"
+	case ErrHookRejected:
+		return "hook rejected"
+	case ErrTimedOut:
+		return "timed out"
+	case ErrCanceled:
+		return "canceled"
+	default:
+		return "unknown"
+	}
+}
+
+// TaskError describes a failed task command
+type TaskError struct {
+	Args     []string
+	ExitCode int
+	Stderr   string
+	Kind     ErrorKind
+	Err      error
+}
+
+// newTaskError builds a TaskError from a failed command and its stderr
+func newTaskError(args []string, stderr string, err error) *TaskError {
+	exitCode := -1
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		exitCode = exitErr.ExitCode()
+	}
+
+	stderr = strings.TrimSpace(stderr)
+	return &TaskError{
+		Args:     args,
+		ExitCode: exitCode,
+		Stderr:   stderr,
+		Kind:     classifyError(stderr, err),
+		Err:      err,
+	}
+}
+
+// classifyError works out the error kind from Taskwarrior's stderr
+func classifyError(stderr string, err error) ErrorKind {
+	switch {
+	case errors.Is(err, exec.ErrNotFound):
+		return ErrNotInstalled
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrTimedOut
+	case errors.Is(err, context.Canceled):
+		return ErrCanceled
+	}
+
+	lower := strings.ToLower(stderr)
+	switch {
+	case strings.Contains(lower, "unable to lock"),
+		strings.Contains(lower, "database is locked"),
+		strings.Contains(lower, "lock file"):
+		return ErrLockHeld
+	case strings.Contains(lower, "hook error"),
+		strings.Contains(lower, "hook script"):
+		return ErrHookRejected
+	case strings.Contains(lower, "no matches"),
+		strings.Contains(lower, "no tasks specified"):
+		return ErrNoMatches
+	case strings.Contains(lower, "mismatched parenthes"),
+		strings.Contains(lower, "could not be evaluated"),
+		strings.Contains(lower, "is not a valid"),
+		strings.Contains(lower, "unrecognized"),
+		strings.Contains(lower, "malformed"):
+		return ErrInvalidFilter
+	}
+	return ErrUnknown
+}
+
+// Error returns Taskwarrior's own message, falling back to the exec error
+func (e *TaskError) Error() string {
+	return fmt.Sprintf("task command failed: %s", e.Message())
+}
+
+// Unwrap returns the underlying exec error
+func (e *TaskError) Unwrap() error {
+	return e.Err
+}
+
+// Message returns the first line of stderr, or the exec error if stderr is empty
+func (e *TaskError) Message() string {
+	for _, line := range strings.Split(e.Stderr, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("exit code %d", e.ExitCode)
+}
+
+// Hint suggests how to fix the failure, or "" if there is no suggestion
+func (e *TaskError) Hint() string {
+	switch e.Kind {
+	case ErrNotInstalled:
+		return "Install Taskwarrior and make sure 'task' is on your PATH"
+	case ErrLockHeld:
+		return "Another task process is using the database; wait for it to finish and try again"
+	case ErrNoMatches:
+		return "No tasks matched; check the filter or whether the task still exists"
+	case ErrInvalidFilter:
+		return "Check the filter syntax, e.g. quoting, parentheses and date values"
+	case ErrHookRejected:
+		return "A Taskwarrior hook rejected the change; see the hook output above"
+	case ErrTimedOut:
+		return "Taskwarrior did not respond in time, often a hook waiting on the network or a held lock; raise TASKSH_TIMEOUT if it is just slow"
+	}
+	return ""
+}
+
+// ErrorHint returns the remediation hint for err if it wraps a TaskError
+func ErrorHint(err error) string {
+	var taskErr *TaskError
+	if errors.As(err, &taskErr) {
+		return taskErr.Hint()
+	}
+	return ""
+}
+
+// FormatError formats err for display, adding a hint line for task failures
+func FormatError(err error) string {
+	if hint := ErrorHint(err); hint != "" {
+		return fmt.Sprintf("Error: %v\nHint: %s", err, hint)
+	}
+	return fmt.Sprintf("Error: %v", err)
+}
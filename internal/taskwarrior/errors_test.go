@@ -0,0 +1,70 @@
+package taskwarrior
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		stderr string
+		want   ErrorKind
+	}{
+		{"Unable to lock data file /home/u/.task/pending.data", ErrLockHeld},
+		{"Error: database is locked", ErrLockHeld},
+		{"No matches.", ErrNoMatches},
+		{"No tasks specified.", ErrNoMatches},
+		{"Mismatched parentheses in expression", ErrInvalidFilter},
+		{"'tomorow' is not a valid date in the 'Y-M-D' format.", ErrInvalidFilter},
+		{"Hook Error: Expected feedback from failing hook script: on-modify.check", ErrHookRejected},
+		{"Something unexpected happened", ErrUnknown},
+		{"", ErrUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := classifyError(tt.stderr, errors.New("exit status 1")); got != tt.want {
+			t.Errorf("classifyError(%q) = %v, want %v", tt.stderr, got, tt.want)
+		}
+	}
+}
+
+func TestTaskErrorMessage(t *testing.T) {
+	err := newTaskError([]string{"export"}, "\nUnable to lock data file\nsecond line\n", errors.New("exit status 1"))
+
+	if err.Error() != "task command failed: Unable to lock data file" {
+		t.Errorf("Unexpected error message: %q", err.Error())
+	}
+	if err.Kind != ErrLockHeld || err.Hint() == "" {
+		t.Errorf("Expected lock error with hint, got %v %q", err.Kind, err.Hint())
+	}
+
+	// Wrapping keeps the hint reachable
+	wrapped := fmt.Errorf("failed to load tasks: %w", err)
+	if ErrorHint(wrapped) != err.Hint() {
+		t.Error("ErrorHint did not find wrapped TaskError")
+	}
+	if formatted := FormatError(wrapped); !strings.Contains(formatted, "\nHint: ") {
+		t.Errorf("FormatError missing hint: %q", formatted)
+	}
+	if formatted := FormatError(errors.New("plain")); formatted != "Error: plain" {
+		t.Errorf("FormatError(plain) = %q", formatted)
+	}
+}
+
+func TestExecuteTaskNotInstalled(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	_, err := executeTask(t.Context(), "version")
+	var taskErr *TaskError
+	if !errors.As(err, &taskErr) {
+		t.Fatalf("Expected *TaskError, got %v", err)
+	}
+	if taskErr.Kind != ErrNotInstalled {
+		t.Errorf("Expected ErrNotInstalled, got %v", taskErr.Kind)
+	}
+	if len(taskErr.Args) != 1 || taskErr.Args[0] != "version" {
+		t.Errorf("Unexpected args: %v", taskErr.Args)
+	}
+}
@@ -0,0 +1,243 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateFormat is the compact ISO format Taskwarrior uses in JSON export
+const dateFormat = "20060102T150405Z"
+
+var durationPattern = regexp.MustCompile(`^(\d+(?:\.\d+)?)?([a-z]+)$`)
+
+// FormatDate renders a time in Taskwarrior's export format
+func FormatDate(t time.Time) string {
+	return t.UTC().Format(dateFormat)
+}
+
+// ParseDate parses a date as stored in task data
+func ParseDate(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range []string{dateFormat, time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// ResolveDate evaluates a Taskwarrior date expression such as "tomorrow",
+// "now-6days", "friday", "2weeks" or "2024-01-15" relative to now
+func ResolveDate(expr string, now time.Time) (time.Time, error) {
+	expr = strings.ToLower(strings.TrimSpace(expr))
+	if expr == "" {
+		return time.Time{}, fmt.Errorf("empty date expression")
+	}
+
+	// Absolute dates; the compact format is always UTC
+	if t, err := time.Parse(dateFormat, strings.ToUpper(expr)); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, strings.ToUpper(expr), now.Location()); err == nil {
+			return t, nil
+		}
+	}
+
+	// "next week", "next month", "next year"
+	if rest, ok := strings.CutPrefix(expr, "next "); ok {
+		d, err := parseDuration("1" + rest)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date expression %q", expr)
+		}
+		return d.addTo(StartOfDay(now)), nil
+	}
+
+	// base[+-]duration, e.g. now-6days or eod+1h
+	if i := strings.IndexAny(expr, "+-"); i > 0 {
+		base, err := resolveNamedDate(expr[:i], now)
+		if err != nil {
+			return time.Time{}, err
+		}
+		d, err := parseDuration(expr[i+1:])
+		if err != nil {
+			return time.Time{}, err
+		}
+		if expr[i] == '-' {
+			d = d.negate()
+		}
+		return d.addTo(base), nil
+	}
+
+	if t, err := resolveNamedDate(expr, now); err == nil {
+		return t, nil
+	}
+
+	// A bare duration is relative to now
+	d, err := parseDuration(expr)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date expression %q", expr)
+	}
+	return d.addTo(now), nil
+}
+
+// resolveNamedDate evaluates named dates such as today, eow or monday
+func resolveNamedDate(name string, now time.Time) (time.Time, error) {
+	today := StartOfDay(now)
+	switch name {
+	case "now":
+		return now, nil
+	case "today", "sod":
+		return today, nil
+	case "eod":
+		return today.AddDate(0, 0, 1).Add(-time.Second), nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	case "sow":
+		return today.AddDate(0, 0, -int(today.Weekday())), nil
+	case "eow":
+		return today.AddDate(0, 0, 7-int(today.Weekday())).Add(-time.Second), nil
+	case "som":
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()), nil
+	case "eom":
+		return time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, now.Location()).Add(-time.Second), nil
+	case "soy":
+		return time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location()), nil
+	case "eoy":
+		return time.Date(now.Year()+1, 1, 1, 0, 0, 0, 0, now.Location()).Add(-time.Second), nil
+	}
+
+	// Weekday names resolve to the next occurrence after today
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		full := strings.ToLower(wd.String())
+		if name == full || name == full[:3] {
+			days := (int(wd) - int(today.Weekday()) + 7) % 7
+			if days == 0 {
+				days = 7
+			}
+			return today.AddDate(0, 0, days), nil
+		}
+	}
+
+	// Month names resolve to the first day of the next occurrence
+	for m := time.January; m <= time.December; m++ {
+		full := strings.ToLower(m.String())
+		if name == full || name == full[:3] {
+			year := now.Year()
+			if m <= now.Month() {
+				year++
+			}
+			return time.Date(year, m, 1, 0, 0, 0, 0, now.Location()), nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unknown date %q", name)
+}
+
+// StartOfDay returns midnight of the day containing t
+func StartOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// calendarDuration is a duration that may include calendar units
+type calendarDuration struct {
+	years, months, days int
+	clock               time.Duration
+}
+
+func (d calendarDuration) addTo(t time.Time) time.Time {
+	return t.AddDate(d.years, d.months, d.days).Add(d.clock)
+}
+
+func (d calendarDuration) negate() calendarDuration {
+	return calendarDuration{-d.years, -d.months, -d.days, -d.clock}
+}
+
+// parseDuration parses Taskwarrior durations such as 3d, 2weeks or 1month
+func parseDuration(s string) (calendarDuration, error) {
+	m := durationPattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(s)))
+	if m == nil {
+		return calendarDuration{}, fmt.Errorf("invalid duration %q", s)
+	}
+
+	n := 1.0
+	if m[1] != "" {
+		var err error
+		if n, err = strconv.ParseFloat(m[1], 64); err != nil {
+			return calendarDuration{}, fmt.Errorf("invalid duration %q", s)
+		}
+	}
+
+	switch m[2] {
+	case "s", "sec", "secs", "second", "seconds":
+		return calendarDuration{clock: time.Duration(n * float64(time.Second))}, nil
+	case "min", "mins", "minute", "minutes":
+		return calendarDuration{clock: time.Duration(n * float64(time.Minute))}, nil
+	case "h", "hr", "hrs", "hour", "hours":
+		return calendarDuration{clock: time.Duration(n * float64(time.Hour))}, nil
+	case "d", "day", "days":
+		return calendarDuration{days: int(n)}, nil
+	case "w", "wk", "wks", "week", "weeks":
+		return calendarDuration{days: int(n * 7)}, nil
+	case "mo", "mth", "mths", "month", "months":
+		return calendarDuration{months: int(n)}, nil
+	case "q", "qtr", "qtrs", "quarter", "quarters":
+		return calendarDuration{months: int(n * 3)}, nil
+	case "y", "yr", "yrs", "year", "years":
+		return calendarDuration{years: int(n)}, nil
+	}
+
+	return calendarDuration{}, fmt.Errorf("invalid duration %q", s)
+}
+
+// recurrencePeriods maps the named recur values to durations
+var recurrencePeriods = map[string]calendarDuration{
+	"daily":      {days: 1},
+	"day":        {days: 1},
+	"weekly":     {days: 7},
+	"sennight":   {days: 7},
+	"biweekly":   {days: 14},
+	"fortnight":  {days: 14},
+	"monthly":    {months: 1},
+	"bimonthly":  {months: 2},
+	"quarterly":  {months: 3},
+	"semiannual": {months: 6},
+	"annual":     {years: 1},
+	"yearly":     {years: 1},
+	"biannual":   {years: 2},
+	"biyearly":   {years: 2},
+}
+
+// AddRecurrence advances t by n periods of a recur value such as "weekly",
+// "weekdays" or "3d"
+func AddRecurrence(t time.Time, recur string, n int) (time.Time, error) {
+	recur = strings.ToLower(strings.TrimSpace(recur))
+	if recur == "weekdays" {
+		for range n {
+			t = t.AddDate(0, 0, 1)
+			for t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+				t = t.AddDate(0, 0, 1)
+			}
+		}
+		return t, nil
+	}
+
+	period, ok := recurrencePeriods[recur]
+	if !ok {
+		var err error
+		if period, err = parseDuration(recur); err != nil {
+			return time.Time{}, fmt.Errorf("invalid recurrence %q", recur)
+		}
+	}
+	for range n {
+		t = period.addTo(t)
+	}
+	return t, nil
+}
@@ -0,0 +1,74 @@
+package filter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolveDate(t *testing.T) {
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"now", fixedNow},
+		{"today", time.Date(2024, 6, 12, 0, 0, 0, 0, time.Local)},
+		{"tomorrow", time.Date(2024, 6, 13, 0, 0, 0, 0, time.Local)},
+		{"eod", time.Date(2024, 6, 12, 23, 59, 59, 0, time.Local)},
+		{"friday", time.Date(2024, 6, 14, 0, 0, 0, 0, time.Local)},
+		{"wednesday", time.Date(2024, 6, 19, 0, 0, 0, 0, time.Local)},
+		{"january", time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local)},
+		{"2024-07-01", time.Date(2024, 7, 1, 0, 0, 0, 0, time.Local)},
+		{"20240701T120000Z", time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)},
+		{"now-6days", fixedNow.AddDate(0, 0, -6)},
+		{"2weeks", fixedNow.AddDate(0, 0, 14)},
+		{"3d", fixedNow.AddDate(0, 0, 3)},
+		{"next month", time.Date(2024, 7, 12, 0, 0, 0, 0, time.Local)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := ResolveDate(tt.expr, fixedNow)
+			if err != nil {
+				t.Fatalf("ResolveDate(%q) error: %v", tt.expr, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ResolveDate(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ResolveDate("someday-maybe", fixedNow); err == nil {
+		t.Error("expected error for invalid date expression")
+	}
+}
+
+func TestAddRecurrence(t *testing.T) {
+	friday := time.Date(2024, 6, 14, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		recur string
+		n     int
+		want  time.Time
+	}{
+		{"weekly", 1, time.Date(2024, 6, 21, 9, 0, 0, 0, time.UTC)},
+		{"monthly", 2, time.Date(2024, 8, 14, 9, 0, 0, 0, time.UTC)},
+		{"weekdays", 1, time.Date(2024, 6, 17, 9, 0, 0, 0, time.UTC)},
+		{"3d", 2, time.Date(2024, 6, 20, 9, 0, 0, 0, time.UTC)},
+		{"quarterly", 0, friday},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.recur, func(t *testing.T) {
+			got, err := AddRecurrence(friday, tt.recur, tt.n)
+			if err != nil {
+				t.Fatalf("AddRecurrence(%q) error: %v", tt.recur, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("AddRecurrence(%q, %d) = %v, want %v", tt.recur, tt.n, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := AddRecurrence(friday, "sometimes", 1); err == nil {
+		t.Error("expected error for invalid recurrence")
+	}
+}
@@ -0,0 +1,350 @@
+package filter
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record is task data a filter can be evaluated against
+type Record interface {
+	// Attr returns the string value of an attribute or UDA, "" if unset.
+	// Dates use the export format, "tags" and "depends" are comma separated.
+	Attr(name string) string
+	// HasTag reports whether the task carries a user tag
+	HasTag(tag string) bool
+}
+
+// Matcher reports whether a record matches a compiled filter
+type Matcher func(r Record) bool
+
+func matchAll(Record) bool { return true }
+
+// Compile turns an expression into a Matcher, resolving relative dates
+// such as eow against now
+func Compile(expr Expr, now time.Time) (Matcher, error) {
+	if expr == nil {
+		return matchAll, nil
+	}
+	return expr.compile(now)
+}
+
+// Match reports whether a single record matches the expression
+func Match(expr Expr, r Record, now time.Time) (bool, error) {
+	match, err := Compile(expr, now)
+	if err != nil {
+		return false, err
+	}
+	return match(r), nil
+}
+
+func (e AndExpr) compile(now time.Time) (Matcher, error) {
+	matchers, err := compileAll(e, now)
+	if err != nil {
+		return nil, err
+	}
+	return func(r Record) bool {
+		for _, m := range matchers {
+			if !m(r) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+func (e OrExpr) compile(now time.Time) (Matcher, error) {
+	matchers, err := compileAll(e, now)
+	if err != nil {
+		return nil, err
+	}
+	return func(r Record) bool {
+		for _, m := range matchers {
+			if m(r) {
+				return true
+			}
+		}
+		return len(matchers) == 0
+	}, nil
+}
+
+func compileAll(exprs []Expr, now time.Time) ([]Matcher, error) {
+	matchers := make([]Matcher, 0, len(exprs))
+	for _, expr := range exprs {
+		m, err := expr.compile(now)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, m)
+	}
+	return matchers, nil
+}
+
+func (e NotExpr) compile(now time.Time) (Matcher, error) {
+	inner, err := e.Expr.compile(now)
+	if err != nil {
+		return nil, err
+	}
+	return func(r Record) bool { return !inner(r) }, nil
+}
+
+func (e TagExpr) compile(now time.Time) (Matcher, error) {
+	want := !e.Exclude
+	return func(r Record) bool { return hasTag(r, e.Tag, now) == want }, nil
+}
+
+func (e Word) compile(time.Time) (Matcher, error) {
+	word := strings.ToLower(string(e))
+	return func(r Record) bool {
+		return strings.Contains(strings.ToLower(r.Attr("description")), word)
+	}, nil
+}
+
+func (e Compare) compile(now time.Time) (Matcher, error) {
+	attr, modifier, value := e.Attr, e.Modifier, e.Value
+	if attr == "tags" {
+		return tagsMatcher(modifier, value)
+	}
+
+	switch modifier {
+	case "none":
+		return func(r Record) bool { return r.Attr(attr) == "" }, nil
+	case "any":
+		return func(r Record) bool { return r.Attr(attr) != "" }, nil
+	}
+
+	// Attributes not listed are user defined and compared as strings
+	switch attributeKinds[attr] {
+	case kindDate:
+		return dateMatcher(attr, modifier, value, now)
+	case kindNumber:
+		return numberMatcher(attr, modifier, value)
+	default:
+		return stringMatcher(attr, modifier, value)
+	}
+}
+
+// IsWaiting reports whether a task is hidden until its wait date
+func IsWaiting(r Record, now time.Time) bool {
+	status := r.Attr("status")
+	if status == "waiting" {
+		return true
+	}
+	wait, ok := ParseDate(r.Attr("wait"))
+	return status == "pending" && ok && wait.After(now)
+}
+
+// hasTag reports whether the task has a tag, including virtual tags
+func hasTag(r Record, tag string, now time.Time) bool {
+	status := r.Attr("status")
+	due, hasDue := ParseDate(r.Attr("due"))
+	today := StartOfDay(now)
+	active := status == "pending" || status == "waiting"
+
+	switch tag {
+	case "PENDING", "READY":
+		return status == "pending" && !IsWaiting(r, now)
+	case "WAITING":
+		return IsWaiting(r, now)
+	case "COMPLETED":
+		return status == "completed"
+	case "DELETED":
+		return status == "deleted"
+	case "TAGGED":
+		return r.Attr("tags") != ""
+	case "PROJECT":
+		return r.Attr("project") != ""
+	case "PRIORITY":
+		return r.Attr("priority") != ""
+	case "ANNOTATED":
+		return r.Attr("annotations") != ""
+	case "SCHEDULED":
+		return r.Attr("scheduled") != ""
+	case "UNTIL":
+		return r.Attr("until") != ""
+	case "ACTIVE":
+		return active && r.Attr("start") != ""
+	case "RECURRING":
+		return r.Attr("recur") != ""
+	case "PARENT":
+		return status == "recurring"
+	case "CHILD", "INSTANCE":
+		return r.Attr("parent") != ""
+	case "OVERDUE":
+		return active && hasDue && due.Before(now)
+	case "DUE":
+		return active && hasDue && due.Before(today.AddDate(0, 0, 8))
+	case "TODAY":
+		return hasDue && !due.Before(today) && due.Before(today.AddDate(0, 0, 1))
+	case "TOMORROW":
+		return hasDue && !due.Before(today.AddDate(0, 0, 1)) && due.Before(today.AddDate(0, 0, 2))
+	case "YESTERDAY":
+		return hasDue && !due.Before(today.AddDate(0, 0, -1)) && due.Before(today)
+	}
+	return r.HasTag(tag)
+}
+
+type attributeKind int
+
+const (
+	kindString attributeKind = iota
+	kindDate
+	kindNumber
+)
+
+var attributeKinds = map[string]attributeKind{
+	"uuid":        kindString,
+	"description": kindString,
+	"project":     kindString,
+	"priority":    kindString,
+	"status":      kindString,
+	"due":         kindDate,
+	"wait":        kindDate,
+	"entry":       kindDate,
+	"modified":    kindDate,
+	"reviewed":    kindDate,
+	"scheduled":   kindDate,
+	"until":       kindDate,
+	"start":       kindDate,
+	"end":         kindDate,
+	"recur":       kindString,
+	"parent":      kindString,
+	"depends":     kindString,
+	"id":          kindNumber,
+	"urgency":     kindNumber,
+}
+
+func dateMatcher(attr, modifier, value string, now time.Time) (Matcher, error) {
+	if value == "" {
+		return func(r Record) bool { return r.Attr(attr) == "" }, nil
+	}
+
+	target, err := ResolveDate(value, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// A value without a time of day matches the whole day
+	dayMatch := target.Equal(StartOfDay(target))
+
+	stored := func(r Record) (time.Time, bool) {
+		return ParseDate(r.Attr(attr))
+	}
+
+	switch modifier {
+	case "before", "below", "under":
+		return func(r Record) bool {
+			t, ok := stored(r)
+			return ok && t.Before(target)
+		}, nil
+	case "after", "above", "over":
+		return func(r Record) bool {
+			t, ok := stored(r)
+			return ok && t.After(target)
+		}, nil
+	case "", "is", "equals":
+		return func(r Record) bool {
+			t, ok := stored(r)
+			return ok && sameInstant(t, target, dayMatch)
+		}, nil
+	case "isnt", "not":
+		return func(r Record) bool {
+			t, ok := stored(r)
+			return !ok || !sameInstant(t, target, dayMatch)
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported modifier %q for %s", modifier, attr)
+}
+
+func sameInstant(t, target time.Time, dayMatch bool) bool {
+	if dayMatch {
+		t = t.In(target.Location())
+		return t.Year() == target.Year() && t.YearDay() == target.YearDay()
+	}
+	return t.Equal(target)
+}
+
+func numberMatcher(attr, modifier, value string) (Matcher, error) {
+	target, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number %q for %s", value, attr)
+	}
+
+	number := func(r Record) float64 {
+		n, _ := strconv.ParseFloat(r.Attr(attr), 64)
+		return n
+	}
+
+	switch modifier {
+	case "before", "below", "under":
+		return func(r Record) bool { return number(r) < target }, nil
+	case "after", "above", "over":
+		return func(r Record) bool { return number(r) > target }, nil
+	case "", "is", "equals":
+		return func(r Record) bool { return number(r) == target }, nil
+	case "isnt", "not":
+		return func(r Record) bool { return number(r) != target }, nil
+	}
+
+	return nil, fmt.Errorf("unsupported modifier %q for %s", modifier, attr)
+}
+
+func stringMatcher(attr, modifier, value string) (Matcher, error) {
+	lower := strings.ToLower(value)
+
+	switch modifier {
+	case "":
+		switch attr {
+		case "project":
+			// project:Home also matches Home.Garden
+			return func(r Record) bool {
+				p := r.Attr(attr)
+				return p == value || strings.HasPrefix(p, value+".")
+			}, nil
+		case "uuid":
+			return func(r Record) bool { return strings.HasPrefix(r.Attr(attr), lower) }, nil
+		case "description":
+			return func(r Record) bool { return strings.Contains(strings.ToLower(r.Attr(attr)), lower) }, nil
+		}
+		return func(r Record) bool { return r.Attr(attr) == value }, nil
+	case "is", "equals":
+		return func(r Record) bool { return r.Attr(attr) == value }, nil
+	case "isnt", "not":
+		return func(r Record) bool { return r.Attr(attr) != value }, nil
+	case "has", "contains":
+		return func(r Record) bool { return strings.Contains(strings.ToLower(r.Attr(attr)), lower) }, nil
+	case "hasnt":
+		return func(r Record) bool { return !strings.Contains(strings.ToLower(r.Attr(attr)), lower) }, nil
+	case "startswith", "left":
+		return func(r Record) bool { return strings.HasPrefix(r.Attr(attr), value) }, nil
+	case "endswith", "right":
+		return func(r Record) bool { return strings.HasSuffix(r.Attr(attr), value) }, nil
+	case "word":
+		return func(r Record) bool { return slices.Contains(strings.Fields(r.Attr(attr)), value) }, nil
+	case "noword":
+		return func(r Record) bool { return !slices.Contains(strings.Fields(r.Attr(attr)), value) }, nil
+	case "before", "below", "under":
+		return func(r Record) bool { return r.Attr(attr) < value }, nil
+	case "after", "above", "over":
+		return func(r Record) bool { return r.Attr(attr) > value }, nil
+	}
+
+	return nil, fmt.Errorf("unsupported modifier %q for %s", modifier, attr)
+}
+
+func tagsMatcher(modifier, value string) (Matcher, error) {
+	switch modifier {
+	case "", "has", "contains", "is", "equals", "word":
+		return func(r Record) bool { return r.HasTag(value) }, nil
+	case "hasnt", "isnt", "not", "noword":
+		return func(r Record) bool { return !r.HasTag(value) }, nil
+	case "none":
+		return func(r Record) bool { return r.Attr("tags") == "" }, nil
+	case "any":
+		return func(r Record) bool { return r.Attr("tags") != "" }, nil
+	}
+	return nil, fmt.Errorf("unsupported modifier %q for tags", modifier)
+}
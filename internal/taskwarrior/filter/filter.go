@@ -0,0 +1,234 @@
+// Package filter builds Taskwarrior filter expressions. An expression renders
+// to task command-line arguments and can also be evaluated against task data
+// in memory, so queries can be composed and unit tested without the task
+// binary.
+package filter
+
+import (
+	"strings"
+	"time"
+)
+
+// Expr is a filter expression
+type Expr interface {
+	// Args renders the expression as task command-line arguments
+	Args() []string
+	// String renders the expression as one filter string with values
+	// quoted, suitable for storing in a report definition
+	String() string
+
+	terms(quote bool) []string
+	compile(now time.Time) (Matcher, error)
+}
+
+// AndExpr matches when every expression matches. An empty AndExpr matches
+// every task.
+type AndExpr []Expr
+
+// OrExpr matches when any expression matches
+type OrExpr []Expr
+
+// NotExpr negates an expression
+type NotExpr struct {
+	Expr Expr
+}
+
+// TagExpr matches a tag or virtual tag such as PENDING or OVERDUE
+type TagExpr struct {
+	Tag     string
+	Exclude bool
+}
+
+// Compare matches an attribute with a modifier, e.g. due.before:eow
+type Compare struct {
+	Attr     string
+	Modifier string
+	Value    string
+}
+
+// Word matches tasks whose description contains the word
+type Word string
+
+// And combines expressions so that all must match
+func And(exprs ...Expr) AndExpr {
+	return AndExpr(exprs)
+}
+
+// Or combines expressions so that any may match
+func Or(exprs ...Expr) OrExpr {
+	return OrExpr(exprs)
+}
+
+// Not negates an expression
+func Not(expr Expr) NotExpr {
+	return NotExpr{Expr: expr}
+}
+
+// HasTag matches tasks with the tag, rendered as +tag
+func HasTag(tag string) TagExpr {
+	return TagExpr{Tag: tag}
+}
+
+// NoTag matches tasks without the tag, rendered as -tag
+func NoTag(tag string) TagExpr {
+	return TagExpr{Tag: tag, Exclude: true}
+}
+
+// Attribute names a task attribute or UDA for building comparisons
+type Attribute string
+
+// Attr starts a comparison on the named attribute
+func Attr(name string) Attribute {
+	return Attribute(name)
+}
+
+func (a Attribute) compare(modifier, value string) Compare {
+	return Compare{Attr: string(a), Modifier: modifier, Value: value}
+}
+
+// Eq matches with Taskwarrior's default semantics, e.g. project:Home also
+// matches Home.Garden
+func (a Attribute) Eq(value string) Compare { return a.compare("", value) }
+
+// Is matches the exact value
+func (a Attribute) Is(value string) Compare { return a.compare("is", value) }
+
+// Isnt matches anything but the exact value
+func (a Attribute) Isnt(value string) Compare { return a.compare("isnt", value) }
+
+// Has matches values containing the text
+func (a Attribute) Has(value string) Compare { return a.compare("has", value) }
+
+// Hasnt matches values not containing the text
+func (a Attribute) Hasnt(value string) Compare { return a.compare("hasnt", value) }
+
+// StartsWith matches values with the prefix
+func (a Attribute) StartsWith(value string) Compare { return a.compare("startswith", value) }
+
+// EndsWith matches values with the suffix
+func (a Attribute) EndsWith(value string) Compare { return a.compare("endswith", value) }
+
+// Before matches dates before the date expression, or lower values
+func (a Attribute) Before(value string) Compare { return a.compare("before", value) }
+
+// After matches dates after the date expression, or higher values
+func (a Attribute) After(value string) Compare { return a.compare("after", value) }
+
+// Under matches numbers below the value
+func (a Attribute) Under(value string) Compare { return a.compare("under", value) }
+
+// Over matches numbers above the value
+func (a Attribute) Over(value string) Compare { return a.compare("over", value) }
+
+// None matches tasks without the attribute
+func (a Attribute) None() Compare { return a.compare("none", "") }
+
+// Any matches tasks with the attribute set
+func (a Attribute) Any() Compare { return a.compare("any", "") }
+
+func (e AndExpr) Args() []string { return e.terms(false) }
+func (e AndExpr) String() string { return join(e) }
+func (e OrExpr) Args() []string  { return e.terms(false) }
+func (e OrExpr) String() string  { return join(e) }
+func (e NotExpr) Args() []string { return e.terms(false) }
+func (e NotExpr) String() string { return join(e) }
+func (e TagExpr) Args() []string { return e.terms(false) }
+func (e TagExpr) String() string { return join(e) }
+func (e Compare) Args() []string { return e.terms(false) }
+func (e Compare) String() string { return join(e) }
+func (e Word) Args() []string    { return e.terms(false) }
+func (e Word) String() string    { return join(e) }
+
+func join(e Expr) string {
+	return strings.Join(e.terms(true), " ")
+}
+
+func (e AndExpr) terms(quote bool) []string {
+	if len(e) == 1 {
+		return e[0].terms(quote)
+	}
+	var out []string
+	for i, expr := range e {
+		if i > 0 {
+			out = append(out, "and")
+		}
+		out = append(out, group(expr, quote)...)
+	}
+	return out
+}
+
+func (e OrExpr) terms(quote bool) []string {
+	if len(e) == 1 {
+		return e[0].terms(quote)
+	}
+	var out []string
+	for i, expr := range e {
+		if i > 0 {
+			out = append(out, "or")
+		}
+		out = append(out, group(expr, quote)...)
+	}
+	return out
+}
+
+func (e NotExpr) terms(quote bool) []string {
+	return append([]string{"not"}, group(e.Expr, quote)...)
+}
+
+func (e TagExpr) terms(bool) []string {
+	if e.Exclude {
+		return []string{"-" + e.Tag}
+	}
+	return []string{"+" + e.Tag}
+}
+
+func (e Compare) terms(quote bool) []string {
+	name := e.Attr
+	if e.Modifier != "" {
+		name += "." + e.Modifier
+	}
+	value := e.Value
+	if quote {
+		value = quoteValue(value)
+	}
+	return []string{name + ":" + value}
+}
+
+func (e Word) terms(quote bool) []string {
+	if quote {
+		return []string{quoteValue(string(e))}
+	}
+	return []string{string(e)}
+}
+
+// group parenthesizes compound expressions so they keep their meaning when
+// nested
+func group(e Expr, quote bool) []string {
+	terms := e.terms(quote)
+	switch e := e.(type) {
+	case AndExpr:
+		if len(e) <= 1 {
+			return terms
+		}
+	case OrExpr:
+		if len(e) <= 1 {
+			return terms
+		}
+	default:
+		return terms
+	}
+	out := append([]string{"("}, terms...)
+	return append(out, ")")
+}
+
+// quoteValue quotes a value containing spaces, quotes or parentheses so the
+// Taskwarrior lexer reads it as a single token
+func quoteValue(value string) string {
+	if !strings.ContainsAny(value, " \t'\"()") {
+		return value
+	}
+	if !strings.Contains(value, "'") {
+		return "'" + value + "'"
+	}
+	return `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+}
@@ -0,0 +1,168 @@
+package filter
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fixedNow is Wednesday 2024-06-12 10:00 local time
+var fixedNow = time.Date(2024, 6, 12, 10, 0, 0, 0, time.Local)
+
+// testRecord is a task given as attribute values, with tags comma separated
+type testRecord map[string]string
+
+func (r testRecord) Attr(name string) string { return r[name] }
+
+func (r testRecord) HasTag(tag string) bool {
+	return slices.Contains(strings.Split(r["tags"], ","), tag)
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     Expr
+		wantArgs []string
+		wantStr  string
+	}{
+		{
+			name:     "attribute",
+			expr:     Attr("due").Before("eow"),
+			wantArgs: []string{"due.before:eow"},
+			wantStr:  "due.before:eow",
+		},
+		{
+			name:     "nested or",
+			expr:     And(Or(Attr("reviewed").None(), Attr("reviewed").Before("now-6days")), Or(HasTag("PENDING"), HasTag("WAITING"))),
+			wantArgs: []string{"(", "reviewed.none:", "or", "reviewed.before:now-6days", ")", "and", "(", "+PENDING", "or", "+WAITING", ")"},
+			wantStr:  "( reviewed.none: or reviewed.before:now-6days ) and ( +PENDING or +WAITING )",
+		},
+		{
+			name:     "not",
+			expr:     Not(Or(NoTag("home"), Attr("project").Eq("Work"))),
+			wantArgs: []string{"not", "(", "-home", "or", "project:Work", ")"},
+			wantStr:  "not ( -home or project:Work )",
+		},
+		{
+			name:     "quoting",
+			expr:     And(Attr("project").Is("My Project"), Word("it's")),
+			wantArgs: []string{"project.is:My Project", "and", "it's"},
+			wantStr:  `project.is:'My Project' and "it's"`,
+		},
+		{
+			name:     "single child",
+			expr:     And(Or(HasTag("next"))),
+			wantArgs: []string{"+next"},
+			wantStr:  "+next",
+		},
+		{
+			name:     "empty",
+			expr:     And(),
+			wantArgs: nil,
+			wantStr:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.expr.Args(); !slices.Equal(got, tt.wantArgs) {
+				t.Errorf("Args() = %q, want %q", got, tt.wantArgs)
+			}
+			if got := tt.expr.String(); got != tt.wantStr {
+				t.Errorf("String() = %q, want %q", got, tt.wantStr)
+			}
+		})
+	}
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	inputs := []string{
+		"( reviewed.none: or reviewed.before:now-6days ) and ( +PENDING or +WAITING )",
+		"not ( -home or project:Work )",
+		"project.is:'My Project' and urgency.over:5",
+		`description.has:'say "hi"' and +next`,
+		`description.has:"it's \"here\"" and -home`,
+	}
+
+	for _, input := range inputs {
+		expr, err := Parse([]string{input})
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", input, err)
+		}
+		if got := expr.String(); got != input {
+			t.Errorf("Parse(%q).String() = %q", input, got)
+		}
+	}
+
+	expr, err := Parse([]string{"rc.verbose=nothing", "(due:today", "or", "+OVERDUE)", "+work"})
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	want := AndExpr{OrExpr{Compare{Attr: "due", Value: "today"}, TagExpr{Tag: "OVERDUE"}}, TagExpr{Tag: "work"}}
+	if expr.String() != want.String() {
+		t.Errorf("Parse = %q, want %q", expr, want)
+	}
+
+	for _, bad := range []string{"( +a", "+a )", "and +a", "+a or"} {
+		if _, err := Parse([]string{bad}); err == nil {
+			t.Errorf("Parse(%q) expected error", bad)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	overdue := testRecord{"description": "Pay rent", "status": "pending", "due": "20240611T000000Z", "tags": "home", "urgency": "12.5"}
+	waiting := testRecord{"description": "Renew passport", "status": "pending", "wait": "20240620T000000Z", "project": "Admin.Travel"}
+	done := testRecord{"description": "Old work", "status": "completed", "reviewed": "20240601T000000Z"}
+
+	tests := []struct {
+		name string
+		expr Expr
+		want []testRecord
+	}{
+		{"virtual pending", HasTag("PENDING"), []testRecord{overdue}},
+		{"virtual waiting", HasTag("WAITING"), []testRecord{waiting}},
+		{"virtual overdue", HasTag("OVERDUE"), []testRecord{overdue}},
+		{"user tag", HasTag("home"), []testRecord{overdue}},
+		{"exclude tag", NoTag("home"), []testRecord{waiting, done}},
+		{"project prefix", Attr("project").Eq("Admin"), []testRecord{waiting}},
+		{"date before", Attr("due").Before("today"), []testRecord{overdue}},
+		{"number over", Attr("urgency").Over("10"), []testRecord{overdue}},
+		{"none", Attr("reviewed").None(), []testRecord{overdue, waiting}},
+		{"word", Word("RENT"), []testRecord{overdue}},
+		{"or", Or(HasTag("COMPLETED"), HasTag("WAITING")), []testRecord{waiting, done}},
+		{"not", Not(HasTag("PENDING")), []testRecord{waiting, done}},
+		{"empty and", And(), []testRecord{overdue, waiting, done}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := Compile(tt.expr, fixedNow)
+			if err != nil {
+				t.Fatalf("Compile(%q) failed: %v", tt.expr, err)
+			}
+			var got []testRecord
+			for _, r := range []testRecord{overdue, waiting, done} {
+				if match(r) {
+					got = append(got, r)
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("%q matched %v, want %v", tt.expr, got, tt.want)
+			}
+			for i := range got {
+				if got[i]["description"] != tt.want[i]["description"] {
+					t.Errorf("%q matched %v, want %v", tt.expr, got, tt.want)
+				}
+			}
+		})
+	}
+
+	if _, err := Compile(Attr("due").Before("someday-maybe"), fixedNow); err == nil {
+		t.Error("Expected error for invalid date")
+	}
+	if _, err := Compile(Compare{Attr: "project", Modifier: "bogus", Value: "x"}, fixedNow); err == nil {
+		t.Error("Expected error for unsupported modifier")
+	}
+}
@@ -0,0 +1,215 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Parse reads Taskwarrior filter arguments into an expression. It
+// understands and/or/not, parentheses, +TAG/-TAG, attribute modifiers and
+// bare description words. rc.* overrides are skipped.
+func Parse(args []string) (Expr, error) {
+	p := &parser{tokens: tokenize(args)}
+	if len(p.tokens) == 0 {
+		return AndExpr{}, nil
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in filter", p.tokens[p.pos])
+	}
+	return expr, nil
+}
+
+// tokenize splits filter arguments into words and parentheses, keeping
+// quoted values together
+func tokenize(args []string) []string {
+	var tokens []string
+	for _, arg := range args {
+		for _, word := range fields(arg) {
+			if strings.HasPrefix(word, "rc.") {
+				continue
+			}
+			for strings.HasPrefix(word, "(") {
+				tokens = append(tokens, "(")
+				word = word[1:]
+			}
+			closing := 0
+			for strings.HasSuffix(word, ")") {
+				closing++
+				word = word[:len(word)-1]
+			}
+			if word != "" {
+				tokens = append(tokens, Unquote(word))
+			}
+			for i := 0; i < closing; i++ {
+				tokens = append(tokens, ")")
+			}
+		}
+	}
+	return tokens
+}
+
+// fields splits on whitespace outside of single or double quotes
+func fields(s string) []string {
+	var out []string
+	var current strings.Builder
+	var quote rune
+	inWord, escaped := false, false
+	for _, r := range s {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case quote != 0:
+			current.WriteRune(r)
+			if r == '\\' {
+				escaped = true
+			} else if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+			current.WriteRune(r)
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				out = append(out, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			inWord = true
+			current.WriteRune(r)
+		}
+	}
+	if inWord {
+		out = append(out, current.String())
+	}
+	return out
+}
+
+// Unquote strips the quotes around a word or an attribute value, as the
+// Taskwarrior lexer does
+func Unquote(word string) string {
+	prefix := ""
+	value := word
+	if i := strings.Index(word, ":"); i > 0 && !strings.ContainsAny(word[:i], `'"`) {
+		prefix, value = word[:i+1], word[i+1:]
+	}
+	if len(value) >= 2 {
+		if q := value[0]; (q == '\'' || q == '"') && value[len(value)-1] == q {
+			value = value[1 : len(value)-1]
+			if q == '"' {
+				value = strings.ReplaceAll(value, `\"`, `"`)
+			}
+		}
+	}
+	return prefix + value
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	or := OrExpr{first}
+	for p.peek() == "or" {
+		p.pos++
+		next, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		or = append(or, next)
+	}
+	if len(or) == 1 {
+		return first, nil
+	}
+	return or, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	first, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	and := AndExpr{first}
+	for {
+		next := p.peek()
+		if next == "" || next == ")" || next == "or" {
+			break
+		}
+		if next == "and" {
+			p.pos++
+		}
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, expr)
+	}
+	if len(and) == 1 {
+		return first, nil
+	}
+	return and, nil
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	tok := p.peek()
+	switch tok {
+	case "":
+		return nil, fmt.Errorf("unexpected end of filter")
+	case "not":
+		p.pos++
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Not(inner), nil
+	case "(":
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis in filter")
+		}
+		p.pos++
+		return inner, nil
+	case ")", "and", "or":
+		return nil, fmt.Errorf("unexpected %q in filter", tok)
+	}
+
+	p.pos++
+	return parseTerm(tok), nil
+}
+
+// parseTerm reads a single filter term
+func parseTerm(tok string) Expr {
+	if len(tok) > 1 && (tok[0] == '+' || tok[0] == '-') {
+		return TagExpr{Tag: tok[1:], Exclude: tok[0] == '-'}
+	}
+
+	name, value, ok := strings.Cut(tok, ":")
+	if !ok {
+		return Word(tok)
+	}
+	attr, modifier, _ := strings.Cut(name, ".")
+	return Compare{Attr: attr, Modifier: modifier, Value: value}
+}
@@ -0,0 +1,166 @@
+package taskwarrior
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// DependencyGraph links tasks through their depends attribute. A task
+// depends on (is blocked by) the tasks it lists, and blocks the tasks that
+// list it. Depends entries for tasks outside the graph are treated as done.
+type DependencyGraph struct {
+	tasks    map[string]*TaskData
+	uuids    []string            // in the order given
+	blocking map[string][]string // uuid to the tasks that depend on it
+}
+
+// NewDependencyGraph builds a graph over the given tasks
+func NewDependencyGraph(tasks []*TaskData) *DependencyGraph {
+	g := &DependencyGraph{
+		tasks:    make(map[string]*TaskData, len(tasks)),
+		blocking: make(map[string][]string),
+	}
+	for _, td := range tasks {
+		if _, ok := g.tasks[td.UUID]; ok {
+			continue
+		}
+		g.tasks[td.UUID] = td
+		g.uuids = append(g.uuids, td.UUID)
+	}
+	for _, uuid := range g.uuids {
+		for _, dep := range g.tasks[uuid].Depends {
+			if _, ok := g.tasks[dep]; ok {
+				g.blocking[dep] = append(g.blocking[dep], uuid)
+			}
+		}
+	}
+	return g
+}
+
+// LoadDependencyGraph builds a graph over the given tasks, loading every
+// task they depend on, transitively, that is not already among them
+func LoadDependencyGraph(ctx context.Context, backend TaskBackend, tasks []*TaskData) (*DependencyGraph, error) {
+	seen := make(map[string]bool, len(tasks))
+	for _, td := range tasks {
+		seen[td.UUID] = true
+	}
+	all := slices.Clone(tasks)
+	for loaded := tasks; len(loaded) > 0; {
+		var missing []string
+		for _, td := range loaded {
+			for _, dep := range td.Depends {
+				if !seen[dep] {
+					seen[dep] = true
+					missing = append(missing, dep)
+				}
+			}
+		}
+		if len(missing) == 0 {
+			break
+		}
+
+		var err error
+		loaded, err = backend.GetTasksWithDataProgress(ctx, missing, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load task dependencies: %w", err)
+		}
+		all = append(all, loaded...)
+	}
+	return NewDependencyGraph(all), nil
+}
+
+// IsOpen reports whether a task still has to be done
+func IsOpen(td *TaskData) bool {
+	return td.Status == "pending" || td.Status == "waiting"
+}
+
+// Tasks returns the tasks in the graph in the order given
+func (g *DependencyGraph) Tasks() []*TaskData {
+	tasks := make([]*TaskData, len(g.uuids))
+	for i, uuid := range g.uuids {
+		tasks[i] = g.tasks[uuid]
+	}
+	return tasks
+}
+
+// Task returns the task with the UUID, or nil if it is not in the graph
+func (g *DependencyGraph) Task(uuid string) *TaskData {
+	return g.tasks[uuid]
+}
+
+// BlockedBy returns the tasks the task depends on
+func (g *DependencyGraph) BlockedBy(uuid string) []*TaskData {
+	td := g.tasks[uuid]
+	if td == nil {
+		return nil
+	}
+	var blockers []*TaskData
+	for _, dep := range td.Depends {
+		if blocker := g.tasks[dep]; blocker != nil {
+			blockers = append(blockers, blocker)
+		}
+	}
+	return blockers
+}
+
+// Blocking returns the tasks that depend on the task
+func (g *DependencyGraph) Blocking(uuid string) []*TaskData {
+	var blocked []*TaskData
+	for _, dependent := range g.blocking[uuid] {
+		blocked = append(blocked, g.tasks[dependent])
+	}
+	return blocked
+}
+
+// OpenBlockers returns the UUIDs of the unfinished tasks the task depends on
+func (g *DependencyGraph) OpenBlockers(uuid string) []string {
+	var open []string
+	for _, blocker := range g.BlockedBy(uuid) {
+		if IsOpen(blocker) {
+			open = append(open, blocker.UUID)
+		}
+	}
+	return open
+}
+
+// Order sorts uuids so that each task comes after its unfinished blockers
+// in the list, otherwise keeping the given order. Tasks in a dependency
+// cycle keep their relative order.
+func (g *DependencyGraph) Order(uuids []string) []string {
+	remaining := make(map[string]bool, len(uuids))
+	for _, uuid := range uuids {
+		remaining[uuid] = true
+	}
+	ready := func(uuid string) bool {
+		for _, blocker := range g.OpenBlockers(uuid) {
+			if remaining[blocker] && blocker != uuid {
+				return false
+			}
+		}
+		return true
+	}
+
+	ordered := make([]string, 0, len(remaining))
+	for len(remaining) > 0 {
+		next := ""
+		for _, uuid := range uuids {
+			if remaining[uuid] && ready(uuid) {
+				next = uuid
+				break
+			}
+		}
+		if next == "" {
+			// Only cycles are left; take the first remaining task
+			for _, uuid := range uuids {
+				if remaining[uuid] {
+					next = uuid
+					break
+				}
+			}
+		}
+		ordered = append(ordered, next)
+		delete(remaining, next)
+	}
+	return ordered
+}
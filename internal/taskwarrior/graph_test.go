@@ -0,0 +1,60 @@
+package taskwarrior
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDependencyGraph(t *testing.T) {
+	release := &TaskData{UUID: "release", Status: "pending", Depends: []string{"docs", "ci", "gone"}}
+	docs := &TaskData{UUID: "docs", Status: "completed"}
+	ci := &TaskData{UUID: "ci", Status: "pending", Depends: []string{"runner"}}
+	runner := &TaskData{UUID: "runner", Status: "waiting"}
+	graph := NewDependencyGraph([]*TaskData{release, docs, ci, runner})
+
+	uuidsOf := func(tasks []*TaskData) []string {
+		var uuids []string
+		for _, td := range tasks {
+			uuids = append(uuids, td.UUID)
+		}
+		return uuids
+	}
+
+	// Depends entries outside the graph are left out
+	if got := uuidsOf(graph.BlockedBy("release")); !slices.Equal(got, []string{"docs", "ci"}) {
+		t.Errorf("BlockedBy(release) = %v", got)
+	}
+	if got := uuidsOf(graph.Blocking("ci")); !slices.Equal(got, []string{"release"}) {
+		t.Errorf("Blocking(ci) = %v", got)
+	}
+	if got := graph.OpenBlockers("release"); !slices.Equal(got, []string{"ci"}) {
+		t.Errorf("OpenBlockers(release) = %v, want only the unfinished blocker", got)
+	}
+	if graph.Task("gone") != nil {
+		t.Error("Task outside the graph should be nil")
+	}
+}
+
+func TestDependencyGraphOrder(t *testing.T) {
+	tasks := []*TaskData{
+		{UUID: "a", Status: "pending", Depends: []string{"c"}},
+		{UUID: "b", Status: "pending"},
+		{UUID: "c", Status: "pending", Depends: []string{"d"}},
+		{UUID: "d", Status: "pending"},
+		{UUID: "e", Status: "pending", Depends: []string{"f"}},
+		{UUID: "f", Status: "pending", Depends: []string{"e"}},
+	}
+	graph := NewDependencyGraph(tasks)
+
+	// Blockers move ahead; a cycle keeps its order; the rest stays as given
+	got := graph.Order([]string{"a", "b", "c", "d", "e", "f"})
+	want := []string{"b", "d", "c", "a", "e", "f"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Order() = %v, want %v", got, want)
+	}
+
+	// Blockers outside the list don't hold a task back
+	if got := graph.Order([]string{"a", "b"}); !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("Order() = %v, want [a b]", got)
+	}
+}
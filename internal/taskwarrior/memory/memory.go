@@ -0,0 +1,907 @@
+// Package memory provides an in-memory implementation of
+// taskwarrior.TaskBackend. It supports the filters, modifications and undo
+// used by tasksh, so the review and planning interfaces can be exercised in
+// tests and demos without a Taskwarrior installation.
+package memory
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/emiller/tasksh/internal/taskwarrior"
+	"github.com/emiller/tasksh/internal/taskwarrior/filter"
+)
+
+// record is a stored task
+type record struct {
+	data taskwarrior.TaskData
+}
+
+func (r *record) clone() *record {
+	c := &record{data: r.data}
+	c.data.Tags = slices.Clone(r.data.Tags)
+	c.data.Annotations = slices.Clone(r.data.Annotations)
+	c.data.Depends = slices.Clone(r.data.Depends)
+	if r.data.UDA != nil {
+		c.data.UDA = make(map[string]string, len(r.data.UDA))
+		for k, v := range r.data.UDA {
+			c.data.UDA[k] = v
+		}
+	}
+	return c
+}
+
+// snapshotData returns a copy of the task data safe to hand to callers
+func (r *record) snapshotData() *taskwarrior.TaskData {
+	data := r.clone().data
+	return &data
+}
+
+// Backend is an in-memory task store implementing taskwarrior.TaskBackend
+type Backend struct {
+	mu       sync.Mutex
+	tasks    []*record
+	history  [][]*record
+	contexts map[string]string
+	context  string
+	udas     map[string]taskwarrior.UDADefinition
+	urgency  taskwarrior.UrgencyCoefficients
+	now      func() time.Time
+}
+
+var _ taskwarrior.TaskBackend = (*Backend)(nil)
+
+// New creates an empty in-memory backend
+func New() *Backend {
+	return &Backend{
+		contexts: make(map[string]string),
+		udas:     make(map[string]taskwarrior.UDADefinition),
+		urgency:  taskwarrior.DefaultUrgencyCoefficients(),
+		now:      time.Now,
+	}
+}
+
+// Load replaces the backend contents with tasks from a `task export` JSON stream
+func (b *Backend) Load(r io.Reader) error {
+	var exported []taskwarrior.TaskData
+	if err := json.NewDecoder(r).Decode(&exported); err != nil {
+		return fmt.Errorf("failed to parse task JSON: %w", err)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tasks = nil
+	b.history = nil
+	for _, td := range exported {
+		rec := &record{data: td}
+		if rec.data.UUID == "" {
+			rec.data.UUID = newUUID()
+		}
+		b.tasks = append(b.tasks, rec)
+	}
+	b.renumber()
+	return nil
+}
+
+// SetClock overrides the time source used for dates and filters
+func (b *Backend) SetClock(now func() time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.now = now
+}
+
+// DefineContext adds a named context with the given filter
+func (b *Backend) DefineContext(name, contextFilter string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.contexts[name] = contextFilter
+}
+
+// DefineUDA declares a user defined attribute
+func (b *Backend) DefineUDA(def taskwarrior.UDADefinition) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.udas[def.Name] = def
+}
+
+// Add creates a pending task and returns its UUID. Modifications use the
+// same syntax as ModifyTask, e.g. "project:Home", "+errand" or "due:tomorrow".
+func (b *Backend) Add(description string, modifications ...string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	rec := &record{data: taskwarrior.TaskData{
+		UUID:        newUUID(),
+		Description: description,
+		Status:      "pending",
+		Entry:       taskwarrior.NewDate(now),
+		Modified:    taskwarrior.NewDate(now),
+	}}
+
+	if err := applyModifications(rec, modifications, now); err != nil {
+		return "", fmt.Errorf("failed to add task: %w", err)
+	}
+	normalizeWaiting(rec, now)
+
+	b.snapshot()
+	b.tasks = append(b.tasks, rec)
+	b.renumber()
+	return rec.data.UUID, nil
+}
+
+// EnsureReviewConfig declares the wait UDAs; the review filter is built in
+func (b *Backend) EnsureReviewConfig(ctx context.Context, w io.Writer) error {
+	for _, uda := range taskwarrior.WaitUDAs {
+		b.DefineUDA(uda)
+	}
+	return nil
+}
+
+// GetUrgencyCoefficients returns the coefficients used to compute urgency
+func (b *Backend) GetUrgencyCoefficients(ctx context.Context) (taskwarrior.UrgencyCoefficients, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.urgency, nil
+}
+
+// GetUDAs returns the declared user defined attributes sorted by name
+func (b *Backend) GetUDAs(ctx context.Context) ([]taskwarrior.UDADefinition, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	udas := make([]taskwarrior.UDADefinition, 0, len(b.udas))
+	for _, def := range b.udas {
+		udas = append(udas, def)
+	}
+	sort.Slice(udas, func(i, j int) bool { return udas[i].Name < udas[j].Name })
+	return udas, nil
+}
+
+// SetUrgencyCoefficients changes the coefficients used to compute urgency
+func (b *Backend) SetUrgencyCoefficients(c taskwarrior.UrgencyCoefficients) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.urgency = c
+}
+
+// GetTasksForReview returns the UUIDs in the default review queue
+func (b *Backend) GetTasksForReview(ctx context.Context) ([]string, error) {
+	uuids, err := b.GetTasksForQueue(ctx, taskwarrior.DefaultReviewQueue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tasks for review: %w", err)
+	}
+	return uuids, nil
+}
+
+// GetTasksForQueue returns the UUIDs matched by a review queue's filter and
+// due under its interval rules, in the queue's sort order
+func (b *Backend) GetTasksForQueue(ctx context.Context, queue taskwarrior.ReviewQueue) ([]string, error) {
+	expr, err := queue.Expr()
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	matched, err := b.match(ctx, expr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tasks for review queue %s: %w", queue.Name, err)
+	}
+
+	order := taskwarrior.SortOrder(queue.SortSpec())
+	slices.SortStableFunc(matched, func(a, c *record) int {
+		return order(&a.data, &c.data)
+	})
+
+	tasks := make([]*taskwarrior.TaskData, 0, len(matched))
+	for _, rec := range matched {
+		tasks = append(tasks, &rec.data)
+	}
+	return queue.Due(tasks, b.now()), nil
+}
+
+// GetTasksWithDataProgress returns copies of the requested tasks in request order
+func (b *Backend) GetTasksWithDataProgress(ctx context.Context, uuids []string, progressFn func(loaded, total int)) ([]*taskwarrior.TaskData, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refreshUrgency()
+	tasks := make([]*taskwarrior.TaskData, 0, len(uuids))
+	for _, uuid := range uuids {
+		if rec := b.find(uuid); rec != nil {
+			tasks = append(tasks, rec.snapshotData())
+		}
+	}
+
+	if progressFn != nil {
+		progressFn(len(uuids), len(uuids))
+	}
+	return tasks, nil
+}
+
+// FilterUUIDs returns the UUIDs of tasks matching the filter and the active context
+func (b *Backend) FilterUUIDs(ctx context.Context, args []string) ([]string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	matched, err := b.filter(ctx, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter tasks: %w", err)
+	}
+
+	uuids := make([]string, 0, len(matched))
+	for _, rec := range matched {
+		uuids = append(uuids, rec.data.UUID)
+	}
+	return uuids, nil
+}
+
+// GetTaskInfo returns a single task
+func (b *Backend) GetTaskInfo(ctx context.Context, uuid string) (*taskwarrior.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	rec := b.find(uuid)
+	if rec == nil {
+		return nil, fmt.Errorf("task not found: %s", uuid)
+	}
+	return rec.snapshotData().ToTask(), nil
+}
+
+// GetProjects returns the projects used by pending tasks
+func (b *Backend) GetProjects(ctx context.Context) ([]string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	seen := make(map[string]bool)
+	var projects []string
+	for _, rec := range b.tasks {
+		if rec.data.Project == "" || seen[rec.data.Project] {
+			continue
+		}
+		if rec.data.Status == "pending" || rec.data.Status == "waiting" {
+			seen[rec.data.Project] = true
+			projects = append(projects, rec.data.Project)
+		}
+	}
+	sort.Strings(projects)
+	return projects, nil
+}
+
+// GetTags returns all tags in use, prefixed with + like taskwarrior.GetTags
+func (b *Backend) GetTags(ctx context.Context) ([]string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	seen := make(map[string]bool)
+	var tags []string
+	for _, rec := range b.tasks {
+		for _, tag := range rec.data.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, "+"+tag)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+// CreateEditCommand returns nil; there is no editor for in-memory tasks
+func (b *Backend) CreateEditCommand(uuid string) *exec.Cmd {
+	return nil
+}
+
+// ModifyTask applies space-separated modifications to a task
+func (b *Backend) ModifyTask(ctx context.Context, uuid, modifications string) error {
+	if err := b.update(ctx, uuid, func(rec *record, now time.Time) error {
+		return applyModifications(rec, strings.Fields(modifications), now)
+	}); err != nil {
+		return fmt.Errorf("failed to modify task: %w", err)
+	}
+	return nil
+}
+
+// CompleteTask marks a task as completed
+func (b *Backend) CompleteTask(ctx context.Context, uuid string) error {
+	if err := b.update(ctx, uuid, func(rec *record, now time.Time) error {
+		return setStatus(rec, "completed", now)
+	}); err != nil {
+		return fmt.Errorf("failed to complete task: %w", err)
+	}
+	return nil
+}
+
+// DeleteTask marks a task as deleted
+func (b *Backend) DeleteTask(ctx context.Context, uuid string) error {
+	if err := b.update(ctx, uuid, func(rec *record, now time.Time) error {
+		return setStatus(rec, "deleted", now)
+	}); err != nil {
+		return fmt.Errorf("failed to delete task: %w", err)
+	}
+	return nil
+}
+
+// MarkTaskReviewed sets the reviewed date to now
+func (b *Backend) MarkTaskReviewed(ctx context.Context, uuid string) error {
+	if err := b.update(ctx, uuid, func(rec *record, now time.Time) error {
+		rec.data.Reviewed = taskwarrior.NewDate(now)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to mark task as reviewed: %w", err)
+	}
+	return nil
+}
+
+// StartTask marks a task as active from now
+func (b *Backend) StartTask(ctx context.Context, uuid string) error {
+	if err := b.update(ctx, uuid, func(rec *record, now time.Time) error {
+		if !rec.data.Start.IsZero() {
+			return fmt.Errorf("task %s is already active", uuid)
+		}
+		rec.data.Start = taskwarrior.NewDate(now)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to start task: %w", err)
+	}
+	return nil
+}
+
+// StopTask removes an active task's start date
+func (b *Backend) StopTask(ctx context.Context, uuid string) error {
+	if err := b.update(ctx, uuid, func(rec *record, now time.Time) error {
+		if rec.data.Start.IsZero() {
+			return fmt.Errorf("task %s is not active", uuid)
+		}
+		rec.data.Start = taskwarrior.Date{}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to stop task: %w", err)
+	}
+	return nil
+}
+
+// WaitTask hides a task until the given date, recording the reason and the
+// person it waits on in the wait UDAs
+func (b *Backend) WaitTask(ctx context.Context, uuid, waitUntil, reason, waitingOn string) error {
+	if err := b.update(ctx, uuid, func(rec *record, now time.Time) error {
+		wait, err := filter.ResolveDate(waitUntil, now)
+		if err != nil {
+			return err
+		}
+		rec.data.Wait = taskwarrior.NewDate(wait)
+		if !slices.Contains(rec.data.Tags, "waiting") {
+			rec.data.Tags = append(rec.data.Tags, "waiting")
+		}
+		if rec.data.UDA == nil {
+			rec.data.UDA = make(map[string]string)
+		}
+		rec.data.UDA[taskwarrior.WaitSinceUDA] = taskwarrior.NewDate(now).String()
+		if reason != "" {
+			rec.data.UDA[taskwarrior.WaitReasonUDA] = reason
+		}
+		if waitingOn != "" {
+			rec.data.UDA[taskwarrior.WaitingOnUDA] = waitingOn
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to set task to waiting: %w", err)
+	}
+	return nil
+}
+
+// AnnotateTask adds an annotation to a task
+func (b *Backend) AnnotateTask(ctx context.Context, uuid, text string) error {
+	if err := b.update(ctx, uuid, func(rec *record, now time.Time) error {
+		annotate(rec, text, now)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to annotate task: %w", err)
+	}
+	return nil
+}
+
+// DenotateTask removes the first annotation with exactly the given text
+func (b *Backend) DenotateTask(ctx context.Context, uuid, text string) error {
+	if err := b.update(ctx, uuid, func(rec *record, now time.Time) error {
+		i := slices.IndexFunc(rec.data.Annotations, func(a taskwarrior.Annotation) bool { return a.Description == text })
+		if i < 0 {
+			return fmt.Errorf("no annotation %q", text)
+		}
+		rec.data.Annotations = slices.Delete(rec.data.Annotations, i, i+1)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to remove annotation: %w", err)
+	}
+	return nil
+}
+
+// SetDueDate sets or updates the due date for a task
+func (b *Backend) SetDueDate(ctx context.Context, uuid, dueDate string) error {
+	if err := b.update(ctx, uuid, func(rec *record, now time.Time) error {
+		return applyModifications(rec, []string{"due:" + dueDate}, now)
+	}); err != nil {
+		return fmt.Errorf("failed to set due date: %w", err)
+	}
+	return nil
+}
+
+// RemoveDueDate removes the due date from a task
+func (b *Backend) RemoveDueDate(ctx context.Context, uuid string) error {
+	if err := b.update(ctx, uuid, func(rec *record, now time.Time) error {
+		rec.data.Due = taskwarrior.Date{}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to remove due date: %w", err)
+	}
+	return nil
+}
+
+// RemoveWaitDate removes the wait date, the waiting tag, the wait UDAs and
+// any legacy wait reason annotations from a task
+func (b *Backend) RemoveWaitDate(ctx context.Context, uuid string) error {
+	if err := b.update(ctx, uuid, func(rec *record, now time.Time) error {
+		legacy := taskwarrior.LegacyWaitAnnotations(rec.data.Annotations)
+		rec.data.Annotations = slices.DeleteFunc(rec.data.Annotations, func(a taskwarrior.Annotation) bool {
+			return slices.Contains(legacy, a.Description)
+		})
+		return applyModifications(rec, taskwarrior.UnwaitModifications, now)
+	}); err != nil {
+		return fmt.Errorf("failed to remove wait date: %w", err)
+	}
+	return nil
+}
+
+// ModifyTasks applies the same modifications to several tasks as one change
+func (b *Backend) ModifyTasks(ctx context.Context, uuids []string, modifications []string) error {
+	if err := b.updateAll(ctx, uuids, func(rec *record, now time.Time) error {
+		return applyModifications(rec, modifications, now)
+	}); err != nil {
+		return fmt.Errorf("failed to modify tasks: %w", err)
+	}
+	return nil
+}
+
+// CompleteTasks marks several tasks as completed as one change
+func (b *Backend) CompleteTasks(ctx context.Context, uuids []string) error {
+	if err := b.updateAll(ctx, uuids, func(rec *record, now time.Time) error {
+		return setStatus(rec, "completed", now)
+	}); err != nil {
+		return fmt.Errorf("failed to complete tasks: %w", err)
+	}
+	return nil
+}
+
+// DeleteTasks marks several tasks as deleted as one change
+func (b *Backend) DeleteTasks(ctx context.Context, uuids []string) error {
+	if err := b.updateAll(ctx, uuids, func(rec *record, now time.Time) error {
+		return setStatus(rec, "deleted", now)
+	}); err != nil {
+		return fmt.Errorf("failed to delete tasks: %w", err)
+	}
+	return nil
+}
+
+// RestoreTask replaces a task with a copy of td, adding it if it is not
+// stored, like `task import`
+func (b *Backend) RestoreTask(ctx context.Context, td *taskwarrior.TaskData) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	restored := (&record{data: *td}).clone()
+	b.snapshot()
+	if i := slices.IndexFunc(b.tasks, func(r *record) bool { return r.data.UUID == td.UUID }); i >= 0 {
+		b.tasks[i] = restored
+	} else {
+		b.tasks = append(b.tasks, restored)
+	}
+	b.renumber()
+	return nil
+}
+
+// UndoLastAction restores the state before the most recent change
+func (b *Backend) UndoLastAction(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.history) == 0 {
+		return fmt.Errorf("no operations available to undo")
+	}
+	b.tasks = b.history[len(b.history)-1]
+	b.history = b.history[:len(b.history)-1]
+	return nil
+}
+
+// ExecuteCommand runs a small subset of task commands: add, and
+// <uuid> done|delete|modify|annotate
+func (b *Backend) ExecuteCommand(ctx context.Context, args []string) (string, error) {
+	var words []string
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "rc.") {
+			words = append(words, arg)
+		}
+	}
+	if len(words) == 0 {
+		return "", fmt.Errorf("task command failed: no command given")
+	}
+
+	if words[0] == "add" {
+		if len(words) < 2 {
+			return "", fmt.Errorf("task command failed: additional text must be provided")
+		}
+		var desc, mods []string
+		for _, w := range words[1:] {
+			if _, _, isMod := strings.Cut(w, ":"); isMod || strings.HasPrefix(w, "+") {
+				mods = append(mods, w)
+			} else {
+				desc = append(desc, w)
+			}
+		}
+		uuid, err := b.Add(strings.Join(desc, " "), mods...)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Created task %s.\n", uuid), nil
+	}
+
+	if len(words) < 2 {
+		return "", fmt.Errorf("task command failed: unsupported command %q", words[0])
+	}
+
+	ref, command, rest := words[0], words[1], words[2:]
+	var err error
+	switch command {
+	case "done":
+		err = b.CompleteTask(ctx, ref)
+	case "delete":
+		err = b.DeleteTask(ctx, ref)
+	case "modify":
+		err = b.ModifyTask(ctx, ref, strings.Join(rest, " "))
+	case "annotate":
+		err = b.AnnotateTask(ctx, ref, strings.Join(rest, " "))
+	case "denotate":
+		err = b.DenotateTask(ctx, ref, strings.Join(rest, " "))
+	default:
+		return "", fmt.Errorf("task command failed: unsupported command %q", command)
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Task %s: %s applied.\n", ref, command), nil
+}
+
+// GetContexts returns the defined contexts followed by "none"
+func (b *Backend) GetContexts(ctx context.Context) ([]string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	contexts := make([]string, 0, len(b.contexts)+1)
+	for name := range b.contexts {
+		contexts = append(contexts, name)
+	}
+	sort.Strings(contexts)
+	return append(contexts, "none"), nil
+}
+
+// SetContext activates a context; "none" clears it
+func (b *Backend) SetContext(ctx context.Context, contextName string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if contextName == "none" {
+		b.context = ""
+		return nil
+	}
+	if _, ok := b.contexts[contextName]; !ok {
+		return fmt.Errorf("failed to set context: context '%s' not found", contextName)
+	}
+	b.context = contextName
+	return nil
+}
+
+// GetCurrentContext returns the active context, or "none"
+func (b *Backend) GetCurrentContext(ctx context.Context) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.context == "" {
+		return "none", nil
+	}
+	return b.context, nil
+}
+
+// filter returns the records matching the filter arguments and the active
+// context. Callers must hold b.mu.
+func (b *Backend) filter(ctx context.Context, args []string) ([]*record, error) {
+	expr, err := filter.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+	return b.match(ctx, expr)
+}
+
+// match returns the records matching the expression and the active context.
+// Callers must hold b.mu.
+func (b *Backend) match(ctx context.Context, expr filter.Expr) ([]*record, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if b.context != "" {
+		scope, err := filter.Parse([]string{b.contexts[b.context]})
+		if err != nil {
+			return nil, err
+		}
+		expr = filter.And(scope, expr)
+	}
+
+	matches, err := filter.Compile(expr, b.now())
+	if err != nil {
+		return nil, err
+	}
+
+	b.refreshUrgency()
+
+	var matched []*record
+	for _, rec := range b.tasks {
+		if matches(&rec.data) {
+			matched = append(matched, rec)
+		}
+	}
+	return matched, nil
+}
+
+// refreshUrgency recomputes the urgency of every task, as task export does.
+// Callers must hold b.mu.
+func (b *Backend) refreshUrgency() {
+	data := make([]*taskwarrior.TaskData, len(b.tasks))
+	for i, rec := range b.tasks {
+		data[i] = &rec.data
+	}
+	taskwarrior.ComputeUrgency(data, b.urgency, b.now())
+}
+
+// find looks a task up by UUID, UUID prefix or ID. Callers must hold b.mu.
+func (b *Backend) find(ref string) *record {
+	if id, err := strconv.Atoi(ref); err == nil && id > 0 {
+		for _, rec := range b.tasks {
+			if rec.data.ID == id {
+				return rec
+			}
+		}
+		return nil
+	}
+	for _, rec := range b.tasks {
+		if rec.data.UUID == ref {
+			return rec
+		}
+	}
+	if len(ref) >= 8 {
+		for _, rec := range b.tasks {
+			if strings.HasPrefix(rec.data.UUID, ref) {
+				return rec
+			}
+		}
+	}
+	return nil
+}
+
+// update applies fn to a copy of the task, committing it with an undo
+// snapshot only if fn succeeds
+func (b *Backend) update(ctx context.Context, ref string, fn func(rec *record, now time.Time) error) error {
+	return b.updateAll(ctx, []string{ref}, fn)
+}
+
+// updateAll applies fn to copies of several tasks, committing them together
+// with one undo snapshot only if fn succeeds for every task
+func (b *Backend) updateAll(ctx context.Context, refs []string, fn func(rec *record, now time.Time) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	updated := make(map[*record]*record, len(refs))
+	for _, ref := range refs {
+		rec := b.find(ref)
+		if rec == nil {
+			return fmt.Errorf("task not found: %s", ref)
+		}
+		next := rec.clone()
+		if err := fn(next, now); err != nil {
+			return err
+		}
+		next.data.Modified = taskwarrior.NewDate(now)
+		normalizeWaiting(next, now)
+		updated[rec] = next
+	}
+
+	b.snapshot()
+	for i, r := range b.tasks {
+		if next, ok := updated[r]; ok {
+			b.tasks[i] = next
+		}
+	}
+	b.renumber()
+	return nil
+}
+
+// snapshot records the current state for undo. Callers must hold b.mu.
+func (b *Backend) snapshot() {
+	saved := make([]*record, len(b.tasks))
+	for i, rec := range b.tasks {
+		saved[i] = rec.clone()
+	}
+	b.history = append(b.history, saved)
+}
+
+// renumber assigns working-set IDs to pending and waiting tasks like
+// Taskwarrior does after garbage collection. Callers must hold b.mu.
+func (b *Backend) renumber() {
+	id := 1
+	for _, rec := range b.tasks {
+		if rec.data.Status == "pending" || rec.data.Status == "waiting" {
+			rec.data.ID = id
+			id++
+		} else {
+			rec.data.ID = 0
+		}
+	}
+}
+
+// applyModifications applies modify arguments: attr:value pairs, +tag/-tag
+// and bare words, which replace the description
+func applyModifications(rec *record, mods []string, now time.Time) error {
+	var words []string
+	for _, mod := range mods {
+		if len(mod) > 1 && mod[0] == '+' {
+			if tag := mod[1:]; !slices.Contains(rec.data.Tags, tag) {
+				rec.data.Tags = append(rec.data.Tags, tag)
+			}
+			continue
+		}
+		if len(mod) > 1 && mod[0] == '-' {
+			rec.data.Tags = slices.DeleteFunc(rec.data.Tags, func(t string) bool { return t == mod[1:] })
+			continue
+		}
+
+		mod = filter.Unquote(mod)
+		attr, value, ok := strings.Cut(mod, ":")
+		if !ok {
+			words = append(words, mod)
+			continue
+		}
+
+		switch attr {
+		case "description":
+			rec.data.Description = value
+		case "project":
+			rec.data.Project = value
+		case "priority":
+			if value != "" && value != "H" && value != "M" && value != "L" {
+				return fmt.Errorf("invalid priority %q", value)
+			}
+			rec.data.Priority = value
+		case "recur":
+			rec.data.Recur = value
+		case "depends":
+			rec.data.Depends = nil
+			for _, dep := range strings.Split(value, ",") {
+				if dep != "" {
+					rec.data.Depends = append(rec.data.Depends, dep)
+				}
+			}
+		case "due", "wait", "reviewed", "scheduled", "until", "start", "end":
+			var date taskwarrior.Date
+			if value != "" {
+				t, err := filter.ResolveDate(value, now)
+				if err != nil {
+					return err
+				}
+				date = taskwarrior.NewDate(t)
+			}
+			*dateField(rec, attr) = date
+		default:
+			// Anything else is stored as a user defined attribute
+			if value == "" {
+				delete(rec.data.UDA, attr)
+				continue
+			}
+			if rec.data.UDA == nil {
+				rec.data.UDA = make(map[string]string)
+			}
+			rec.data.UDA[attr] = value
+		}
+	}
+
+	if len(words) > 0 {
+		rec.data.Description = strings.Join(words, " ")
+	}
+	return nil
+}
+
+// dateField returns a pointer to the named date attribute
+func dateField(rec *record, attr string) *taskwarrior.Date {
+	switch attr {
+	case "due":
+		return &rec.data.Due
+	case "wait":
+		return &rec.data.Wait
+	case "reviewed":
+		return &rec.data.Reviewed
+	case "scheduled":
+		return &rec.data.Scheduled
+	case "until":
+		return &rec.data.Until
+	case "start":
+		return &rec.data.Start
+	default:
+		return &rec.data.End
+	}
+}
+
+// annotate appends a timestamped annotation
+func annotate(rec *record, text string, now time.Time) {
+	rec.data.Annotations = append(rec.data.Annotations, taskwarrior.Annotation{
+		Entry:       taskwarrior.NewDate(now),
+		Description: text,
+	})
+}
+
+// setStatus moves a pending or waiting task to a final status
+func setStatus(rec *record, status string, now time.Time) error {
+	if rec.data.Status != "pending" && rec.data.Status != "waiting" {
+		return fmt.Errorf("task %s is already %s", rec.data.UUID, rec.data.Status)
+	}
+	rec.data.Status = status
+	rec.data.End = taskwarrior.NewDate(now)
+	rec.data.Start = taskwarrior.Date{}
+	return nil
+}
+
+// normalizeWaiting keeps the waiting status consistent with the wait date
+func normalizeWaiting(rec *record, now time.Time) {
+	waiting := !rec.data.Wait.IsZero() && rec.data.Wait.After(now)
+	switch {
+	case rec.data.Status == "pending" && waiting:
+		rec.data.Status = "waiting"
+	case rec.data.Status == "waiting" && !waiting:
+		rec.data.Status = "pending"
+	}
+}
+
+// newUUID returns a random version 4 UUID
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
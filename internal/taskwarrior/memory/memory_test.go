@@ -0,0 +1,357 @@
+package memory
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emiller/tasksh/internal/taskwarrior"
+)
+
+// fixedNow is Wednesday 2024-06-12 10:00 local time
+var fixedNow = time.Date(2024, 6, 12, 10, 0, 0, 0, time.Local)
+
+func newTestBackend(t *testing.T) *Backend {
+	t.Helper()
+	b := New()
+	b.SetClock(func() time.Time { return fixedNow })
+	return b
+}
+
+func mustAdd(t *testing.T, b *Backend, desc string, mods ...string) string {
+	t.Helper()
+	uuid, err := b.Add(desc, mods...)
+	if err != nil {
+		t.Fatalf("Add(%q) failed: %v", desc, err)
+	}
+	return uuid
+}
+
+func TestFilterUUIDs(t *testing.T) {
+	b := newTestBackend(t)
+	home := mustAdd(t, b, "Fix the fence", "project:Home.Garden", "priority:H", "due:today", "+outdoor")
+	work := mustAdd(t, b, "Write report", "project:Work", "due:2024-06-20")
+	errand := mustAdd(t, b, "Buy milk", "+errand")
+	done := mustAdd(t, b, "Old task", "project:Home")
+	if err := b.CompleteTask(t.Context(), done); err != nil {
+		t.Fatalf("CompleteTask failed: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		filter []string
+		want   []string
+	}{
+		{"empty", nil, []string{home, work, errand, done}},
+		{"project prefix", []string{"project:Home"}, []string{home, done}},
+		{"virtual pending", []string{"+PENDING"}, []string{home, work, errand}},
+		{"tag", []string{"+errand"}, []string{errand}},
+		{"negated tag", []string{"+PENDING", "-errand"}, []string{home, work}},
+		{"due today", []string{"due:today"}, []string{home}},
+		{"due before", []string{"due.before:2024-06-15"}, []string{home}},
+		{"due none", []string{"+PENDING", "due.none:"}, []string{errand}},
+		{"or with parens", []string{"(priority:H", "or", "+errand)", "and", "+PENDING"}, []string{home, errand}},
+		{"single arg group", []string{"(due:2024-06-20 or due.before:2024-06-13)", "and", "(+PENDING", "or", "+WAITING)"}, []string{home, work}},
+		{"not", []string{"not", "project:Home", "+PENDING"}, []string{work, errand}},
+		{"description word", []string{"report"}, []string{work}},
+		{"rc overrides ignored", []string{"rc.verbose=nothing", "+errand"}, []string{errand}},
+		{"computed urgency", []string{"urgency.over:10"}, []string{home}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := b.FilterUUIDs(t.Context(), tt.filter)
+			if err != nil {
+				t.Fatalf("FilterUUIDs(%v) error: %v", tt.filter, err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("FilterUUIDs(%v) = %v, want %v", tt.filter, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := b.FilterUUIDs(t.Context(), []string{"(+PENDING"}); err == nil {
+		t.Error("expected error for unbalanced parentheses")
+	}
+}
+
+func TestModifyAndUndo(t *testing.T) {
+	b := newTestBackend(t)
+	uuid := mustAdd(t, b, "Original", "project:Home")
+
+	if err := b.ModifyTask(t.Context(), uuid, "project:Work priority:M +urgent due:tomorrow"); err != nil {
+		t.Fatalf("ModifyTask failed: %v", err)
+	}
+
+	task, err := b.GetTaskInfo(t.Context(), uuid)
+	if err != nil {
+		t.Fatalf("GetTaskInfo failed: %v", err)
+	}
+	wantDue := taskwarrior.NewDate(time.Date(2024, 6, 13, 0, 0, 0, 0, time.Local))
+	if task.Project != "Work" || task.Priority != "M" || !task.Due.Equal(wantDue.Time) {
+		t.Errorf("unexpected task after modify: %+v", task)
+	}
+	if uuids, _ := b.FilterUUIDs(t.Context(), []string{"+urgent"}); len(uuids) != 1 {
+		t.Errorf("expected tag to be added, got %v", uuids)
+	}
+
+	if err := b.ModifyTask(t.Context(), uuid, "priority:X"); err == nil {
+		t.Error("expected error for invalid priority")
+	}
+
+	if err := b.UndoLastAction(t.Context()); err != nil {
+		t.Fatalf("UndoLastAction failed: %v", err)
+	}
+	task, _ = b.GetTaskInfo(t.Context(), uuid)
+	if task.Project != "Home" || task.Priority != "" {
+		t.Errorf("undo did not restore task: %+v", task)
+	}
+
+	// Undo the add itself, then there is nothing left to undo
+	if err := b.UndoLastAction(t.Context()); err != nil {
+		t.Fatalf("UndoLastAction failed: %v", err)
+	}
+	if _, err := b.GetTaskInfo(t.Context(), uuid); err == nil {
+		t.Error("expected task to be gone after undoing add")
+	}
+	if err := b.UndoLastAction(t.Context()); err == nil {
+		t.Error("expected error when nothing to undo")
+	}
+}
+
+func TestBatchActions(t *testing.T) {
+	b := newTestBackend(t)
+	first := mustAdd(t, b, "First")
+	second := mustAdd(t, b, "Second")
+	other := mustAdd(t, b, "Other")
+
+	if err := b.ModifyTasks(t.Context(), []string{first, second}, []string{"project:Errands", "+phone"}); err != nil {
+		t.Fatalf("ModifyTasks failed: %v", err)
+	}
+	if uuids, _ := b.FilterUUIDs(t.Context(), []string{"project:Errands", "+phone"}); !slices.Equal(uuids, []string{first, second}) {
+		t.Errorf("expected both tasks modified, got %v", uuids)
+	}
+
+	// A batch with an unknown task changes nothing
+	if err := b.CompleteTasks(t.Context(), []string{other, "missing"}); err == nil {
+		t.Error("expected error for an unknown task")
+	}
+	if task, _ := b.GetTaskInfo(t.Context(), other); task.Status != "pending" {
+		t.Errorf("failed batch completed a task: %s", task.Status)
+	}
+
+	// The whole batch undoes in one step
+	if err := b.DeleteTasks(t.Context(), []string{first, second, other}); err != nil {
+		t.Fatalf("DeleteTasks failed: %v", err)
+	}
+	if err := b.UndoLastAction(t.Context()); err != nil {
+		t.Fatalf("UndoLastAction failed: %v", err)
+	}
+	for _, uuid := range []string{first, second, other} {
+		if task, _ := b.GetTaskInfo(t.Context(), uuid); task.Status != "pending" {
+			t.Errorf("undo left %s %s", task.Description, task.Status)
+		}
+	}
+
+	// Quoted values are stored without their quotes, as task would
+	waiting := mustAdd(t, b, "Waiting")
+	mods := taskwarrior.WaitModifications("friday", "blocked on review", "", time.Now())
+	if err := b.ModifyTasks(t.Context(), []string{waiting}, mods); err != nil {
+		t.Fatalf("ModifyTasks failed: %v", err)
+	}
+	if data, _ := b.GetTasksWithDataProgress(t.Context(), []string{waiting}, nil); len(data) != 1 || data[0].WaitReason() != "blocked on review" {
+		t.Errorf("expected the wait reason unquoted, got %+v", data)
+	}
+}
+
+func TestStartStop(t *testing.T) {
+	b := newTestBackend(t)
+	uuid := mustAdd(t, b, "Fix bike")
+
+	if err := b.StartTask(t.Context(), uuid); err != nil {
+		t.Fatalf("StartTask failed: %v", err)
+	}
+	if task, _ := b.GetTaskInfo(t.Context(), uuid); !task.Start.Equal(fixedNow) {
+		t.Errorf("expected the task started now, got %v", task.Start)
+	}
+	if err := b.StartTask(t.Context(), uuid); err == nil {
+		t.Error("expected error starting an active task")
+	}
+
+	if err := b.StopTask(t.Context(), uuid); err != nil {
+		t.Fatalf("StopTask failed: %v", err)
+	}
+	if task, _ := b.GetTaskInfo(t.Context(), uuid); !task.Start.IsZero() {
+		t.Errorf("expected the task stopped, got start %v", task.Start)
+	}
+	if err := b.StopTask(t.Context(), uuid); err == nil {
+		t.Error("expected error stopping an inactive task")
+	}
+}
+
+func TestReviewQueue(t *testing.T) {
+	b := newTestBackend(t)
+	fresh := mustAdd(t, b, "Never reviewed")
+	stale := mustAdd(t, b, "Reviewed long ago", "reviewed:now-10days")
+	mustAdd(t, b, "Reviewed recently", "reviewed:now-1day")
+	waiting := mustAdd(t, b, "Waiting task")
+	if err := b.WaitTask(t.Context(), waiting, "friday", "vendor reply", "Acme"); err != nil {
+		t.Fatalf("WaitTask failed: %v", err)
+	}
+
+	uuids, err := b.GetTasksForReview(t.Context())
+	if err != nil {
+		t.Fatalf("GetTasksForReview failed: %v", err)
+	}
+	want := []string{fresh, waiting, stale}
+	if !slices.Equal(uuids, want) {
+		t.Errorf("GetTasksForReview() = %v, want %v", uuids, want)
+	}
+
+	if err := b.MarkTaskReviewed(t.Context(), fresh); err != nil {
+		t.Fatalf("MarkTaskReviewed failed: %v", err)
+	}
+	uuids, _ = b.GetTasksForReview(t.Context())
+	if slices.Contains(uuids, fresh) {
+		t.Error("reviewed task should leave the review queue")
+	}
+
+	data, _ := b.GetTasksWithDataProgress(t.Context(), []string{waiting}, nil)
+	if len(data) != 1 || data[0].Status != "waiting" {
+		t.Fatalf("expected waiting status, got %+v", data)
+	}
+	if data[0].WaitReason() != "vendor reply" || data[0].WaitingOn() != "Acme" || !data[0].WaitingSince().Equal(fixedNow) {
+		t.Errorf("expected the wait UDAs set, got %v", data[0].UDA)
+	}
+	// Reasons recorded by older versions are cleaned up too
+	if err := b.AnnotateTask(t.Context(), waiting, "Wait reason: old vendor reply"); err != nil {
+		t.Fatalf("AnnotateTask failed: %v", err)
+	}
+	if err := b.RemoveWaitDate(t.Context(), waiting); err != nil {
+		t.Fatalf("RemoveWaitDate failed: %v", err)
+	}
+	data, _ = b.GetTasksWithDataProgress(t.Context(), []string{waiting}, nil)
+	if data[0].Status != "pending" || !data[0].Wait.IsZero() || len(data[0].UDA) != 0 || len(data[0].Annotations) != 0 {
+		t.Errorf("expected task to be pending again, got %+v", data[0])
+	}
+}
+
+func TestReviewQueueFilterAndSort(t *testing.T) {
+	b := newTestBackend(t)
+	low := mustAdd(t, b, "Minor bug", "project:work", "+bug", "priority:L")
+	high := mustAdd(t, b, "Crash", "project:work", "+bug", "priority:H")
+	mustAdd(t, b, "Recently checked bug", "project:work", "+bug", "reviewed:now-3days")
+	mustAdd(t, b, "Feature", "project:work")
+
+	queue := taskwarrior.ReviewQueue{Name: "bugs", Filter: "project:work +bug", Sort: "urgency-", Interval: "2w"}
+	uuids, err := b.GetTasksForQueue(t.Context(), queue)
+	if err != nil {
+		t.Fatalf("GetTasksForQueue failed: %v", err)
+	}
+	if want := []string{high, low}; !slices.Equal(uuids, want) {
+		t.Errorf("GetTasksForQueue() = %v, want %v", uuids, want)
+	}
+
+	// A daily rule brings back the bug reviewed three days ago
+	queue.Rules = []taskwarrior.IntervalRule{{Kind: "tag", Value: "bug", Interval: "1d"}}
+	uuids, err = b.GetTasksForQueue(t.Context(), queue)
+	if err != nil {
+		t.Fatalf("GetTasksForQueue failed: %v", err)
+	}
+	if len(uuids) != 3 {
+		t.Errorf("Expected all three bugs due under the daily rule, got %v", uuids)
+	}
+}
+
+func TestContexts(t *testing.T) {
+	b := newTestBackend(t)
+	home := mustAdd(t, b, "Home task", "project:Home")
+	mustAdd(t, b, "Work task", "project:Work")
+	b.DefineContext("home", "project:Home")
+
+	contexts, _ := b.GetContexts(t.Context())
+	if !slices.Equal(contexts, []string{"home", "none"}) {
+		t.Errorf("GetContexts() = %v", contexts)
+	}
+
+	if err := b.SetContext(t.Context(), "home"); err != nil {
+		t.Fatalf("SetContext failed: %v", err)
+	}
+	if current, _ := b.GetCurrentContext(t.Context()); current != "home" {
+		t.Errorf("GetCurrentContext() = %q, want home", current)
+	}
+	uuids, _ := b.GetTasksForReview(t.Context())
+	if !slices.Equal(uuids, []string{home}) {
+		t.Errorf("context not applied to review queue: %v", uuids)
+	}
+
+	if err := b.SetContext(t.Context(), "missing"); err == nil {
+		t.Error("expected error for unknown context")
+	}
+	if err := b.SetContext(t.Context(), "none"); err != nil {
+		t.Fatalf("SetContext(none) failed: %v", err)
+	}
+	if uuids, _ := b.FilterUUIDs(t.Context(), nil); len(uuids) != 2 {
+		t.Errorf("expected both tasks without context, got %v", uuids)
+	}
+}
+
+func TestLoadAndExecuteCommand(t *testing.T) {
+	b := newTestBackend(t)
+	export := `[
+		{"id":1,"uuid":"aaaaaaaa-0000-4000-8000-000000000001","description":"Exported","project":"Imported","status":"pending","tags":["alpha"],"entry":"20240601T000000Z","modified":"20240601T000000Z","urgency":3.2},
+		{"id":0,"uuid":"aaaaaaaa-0000-4000-8000-000000000002","description":"Finished","status":"completed","entry":"20240501T000000Z","modified":"20240502T000000Z","urgency":0}
+	]`
+	if err := b.Load(strings.NewReader(export)); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	tags, _ := b.GetTags(t.Context())
+	if !slices.Equal(tags, []string{"+alpha"}) {
+		t.Errorf("GetTags() = %v", tags)
+	}
+	projects, _ := b.GetProjects(t.Context())
+	if !slices.Equal(projects, []string{"Imported"}) {
+		t.Errorf("GetProjects() = %v", projects)
+	}
+
+	if _, err := b.ExecuteCommand(t.Context(), []string{"rc.confirmation:no", "aaaaaaaa-0000-4000-8000-000000000001", "done"}); err != nil {
+		t.Fatalf("ExecuteCommand done failed: %v", err)
+	}
+	if uuids, _ := b.FilterUUIDs(t.Context(), []string{"+COMPLETED"}); len(uuids) != 2 {
+		t.Errorf("expected 2 completed tasks, got %v", uuids)
+	}
+	if _, err := b.ExecuteCommand(t.Context(), []string{"1", "start"}); err == nil {
+		t.Error("expected error for unsupported command")
+	}
+	if b.CreateEditCommand("x") != nil {
+		t.Error("expected no edit command for in-memory backend")
+	}
+}
+
+func TestAnnotations(t *testing.T) {
+	b := newTestBackend(t)
+	uuid := mustAdd(t, b, "Read paper")
+	ctx := t.Context()
+
+	for _, text := range []string{"https://example.com/paper.pdf", "Skim section 2", "Skim section 2"} {
+		if err := b.AnnotateTask(ctx, uuid, text); err != nil {
+			t.Fatalf("AnnotateTask(%q) failed: %v", text, err)
+		}
+	}
+	if err := b.DenotateTask(ctx, uuid, "Skim section 2"); err != nil {
+		t.Fatalf("DenotateTask failed: %v", err)
+	}
+	if err := b.DenotateTask(ctx, uuid, "Skim"); err == nil {
+		t.Error("expected error denotating text that matches no annotation exactly")
+	}
+
+	task, _ := b.GetTaskInfo(ctx, uuid)
+	if len(task.Annotations) != 2 || task.Annotations[0].Description != "https://example.com/paper.pdf" {
+		t.Errorf("Annotations = %v", task.Annotations)
+	}
+	if !task.Annotations[0].Entry.Equal(fixedNow.Truncate(time.Second)) {
+		t.Errorf("Annotation entry = %v, want the clock time", task.Annotations[0].Entry)
+	}
+}
@@ -0,0 +1,268 @@
+package taskwarrior
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultProfileName names the profile that keeps the environment tasksh
+// was started with; it is available unless the config redefines it
+const DefaultProfileName = "default"
+
+// Profile is a named Taskwarrior setup from the tasksh config, each with its
+// own taskrc, data directory and time database. Empty fields keep the
+// environment tasksh was started with.
+type Profile struct {
+	Name   string
+	Taskrc string
+	Data   string
+	TimeDB string
+}
+
+// profileVar is an environment variable a profile sets, and its field
+type profileVar struct {
+	name  string
+	field func(Profile) string
+}
+
+// profileEnv lists the environment variables a profile sets
+var profileEnv = []profileVar{
+	{"TASKRC", func(p Profile) string { return p.Taskrc }},
+	{"TASKDATA", func(p Profile) string { return p.Data }},
+	{"TASKSH_TIMEDB", func(p Profile) string { return p.TimeDB }},
+}
+
+var (
+	profileMu     sync.Mutex
+	activeProfile string
+	ambientEnv    map[string]*string // environment before the first UseProfile
+)
+
+// ProfileConfigPath returns the tasksh config file: $TASKSH_CONFIG, then
+// $XDG_CONFIG_HOME/tasksh/config
+func ProfileConfigPath() (string, error) {
+	if path := os.Getenv("TASKSH_CONFIG"); path != "" {
+		return expandHome(path), nil
+	}
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get home directory: %w", err)
+		}
+		configDir = filepath.Join(homeDir, ".config")
+	}
+	return filepath.Join(configDir, "tasksh", "config"), nil
+}
+
+// loadTaskshConfig reads the settings in the tasksh config; a missing config
+// has no settings
+func loadTaskshConfig() (map[string]string, error) {
+	path, err := ProfileConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	rc, err := ParseTaskrc(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]string{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read tasksh config: %w", err)
+	}
+	return rc.Settings, nil
+}
+
+// LoadProfiles reads the profiles from the tasksh config, sorted by name,
+// and the profile to use by default. The config uses taskrc syntax:
+//
+//	profile=work
+//	profile.work.taskrc=~/.taskrc-work
+//	profile.work.data=~/.task-work
+//	profile.work.timedb=~/.local/share/tasksh/work.sqlite3
+func LoadProfiles() ([]Profile, string, error) {
+	settings, err := loadTaskshConfig()
+	if err != nil {
+		return nil, "", err
+	}
+
+	byName := map[string]*Profile{DefaultProfileName: {Name: DefaultProfileName}}
+	for key, value := range settings {
+		rest, ok := strings.CutPrefix(key, "profile.")
+		if !ok {
+			continue
+		}
+		dot := strings.LastIndexByte(rest, '.')
+		if dot <= 0 {
+			continue
+		}
+		name, field := rest[:dot], rest[dot+1:]
+		profile := byName[name]
+		if profile == nil {
+			profile = &Profile{Name: name}
+			byName[name] = profile
+		}
+		switch field {
+		case "taskrc":
+			profile.Taskrc = expandHome(value)
+		case "data":
+			profile.Data = expandHome(value)
+		case "timedb":
+			profile.TimeDB = expandHome(value)
+		}
+	}
+
+	profiles := make([]Profile, 0, len(byName))
+	for _, profile := range byName {
+		profiles = append(profiles, *profile)
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	return profiles, settings["profile"], nil
+}
+
+// FindProfile returns the named profile from the tasksh config
+func FindProfile(name string) (Profile, error) {
+	profiles, _, err := LoadProfiles()
+	if err != nil {
+		return Profile{}, err
+	}
+	names := make([]string, len(profiles))
+	for i, profile := range profiles {
+		if profile.Name == name {
+			return profile, nil
+		}
+		names[i] = profile.Name
+	}
+	return Profile{}, fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+// UseProfile points task calls, taskrc reads and the time database at the
+// profile by setting TASKRC, TASKDATA and TASKSH_TIMEDB for this process. It
+// is for the --profile selection at startup; a switch while running uses
+// NewProfileBackend, so calls already in flight keep their profile.
+func UseProfile(p Profile) error {
+	profileMu.Lock()
+	defer profileMu.Unlock()
+
+	if ambientEnv == nil {
+		ambientEnv = make(map[string]*string, len(profileEnv))
+		for _, env := range profileEnv {
+			if value, ok := os.LookupEnv(env.name); ok {
+				ambientEnv[env.name] = &value
+			} else {
+				ambientEnv[env.name] = nil
+			}
+		}
+	}
+
+	for _, env := range profileEnv {
+		var err error
+		if value, ok := p.lookupEnv(env.name); ok {
+			err = os.Setenv(env.name, value)
+		} else {
+			err = os.Unsetenv(env.name)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to switch to profile %s: %w", p.Name, err)
+		}
+	}
+	activeProfile = p.Name
+	return nil
+}
+
+// Getenv returns the value the profile gives one of TASKRC, TASKDATA and
+// TASKSH_TIMEDB: its own setting, else the one tasksh was started with
+func (p Profile) Getenv(name string) string {
+	profileMu.Lock()
+	defer profileMu.Unlock()
+	value, _ := p.lookupEnv(name)
+	return value
+}
+
+// Environ returns the process environment with the profile's TASKRC,
+// TASKDATA and TASKSH_TIMEDB in place of the current ones
+func (p Profile) Environ() []string {
+	profileMu.Lock()
+	defer profileMu.Unlock()
+
+	var env []string
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if !slices.ContainsFunc(profileEnv, func(e profileVar) bool { return e.name == name }) {
+			env = append(env, kv)
+		}
+	}
+	for _, e := range profileEnv {
+		if value, ok := p.lookupEnv(e.name); ok {
+			env = append(env, e.name+"="+value)
+		}
+	}
+	return env
+}
+
+// lookupEnv resolves a profile variable; the caller holds profileMu
+func (p Profile) lookupEnv(name string) (string, bool) {
+	for _, env := range profileEnv {
+		if env.name == name {
+			if value := env.field(p); value != "" {
+				return value, true
+			}
+		}
+	}
+	if ambientEnv == nil {
+		return os.LookupEnv(name)
+	}
+	if ambient := ambientEnv[name]; ambient != nil {
+		return *ambient, true
+	}
+	return "", false
+}
+
+// envKey carries the environment of an ExecBackend's profile through the
+// package functions it calls
+type envKey struct{}
+
+// withEnv returns ctx carrying env for task calls and taskrc reads; nil
+// keeps the process environment
+func withEnv(ctx context.Context, env []string) context.Context {
+	if env == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, envKey{}, env)
+}
+
+// commandEnv returns the environment carried by ctx, or nil for the
+// process environment
+func commandEnv(ctx context.Context) []string {
+	env, _ := ctx.Value(envKey{}).([]string)
+	return env
+}
+
+// getenv reads a variable from the environment carried by ctx, else from
+// the process environment
+func getenv(ctx context.Context, name string) string {
+	env := commandEnv(ctx)
+	if env == nil {
+		return os.Getenv(name)
+	}
+	for _, kv := range slices.Backward(env) {
+		if value, ok := strings.CutPrefix(kv, name+"="); ok {
+			return value
+		}
+	}
+	return ""
+}
+
+// ActiveProfile returns the name of the profile in use, or "" if none was
+// selected
+func ActiveProfile() string {
+	profileMu.Lock()
+	defer profileMu.Unlock()
+	return activeProfile
+}
@@ -0,0 +1,133 @@
+package taskwarrior
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const testProfileConfig = `# tasksh config
+profile=work
+
+profile.work.taskrc=~/.taskrc-work
+profile.work.data=~/.task-work
+profile.work.timedb=/srv/tasksh/work.sqlite3
+profile.personal.data=/home/me/.task
+`
+
+// isolateProfiles restores the profile environment and state after a test
+func isolateProfiles(t *testing.T) {
+	t.Helper()
+	for _, env := range profileEnv {
+		t.Setenv(env.name, "")
+		os.Unsetenv(env.name)
+	}
+	t.Cleanup(func() {
+		ambientEnv = nil
+		activeProfile = ""
+	})
+}
+
+func TestLoadProfiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("TASKSH_CONFIG", writeFile(t, home, "tasksh.rc", testProfileConfig))
+
+	profiles, defaultName, err := LoadProfiles()
+	if err != nil {
+		t.Fatalf("LoadProfiles failed: %v", err)
+	}
+	if defaultName != "work" {
+		t.Errorf("Default profile = %q, want work", defaultName)
+	}
+
+	want := []Profile{
+		{Name: DefaultProfileName},
+		{Name: "personal", Data: "/home/me/.task"},
+		{
+			Name:   "work",
+			Taskrc: filepath.Join(home, ".taskrc-work"),
+			Data:   filepath.Join(home, ".task-work"),
+			TimeDB: "/srv/tasksh/work.sqlite3",
+		},
+	}
+	if len(profiles) != len(want) {
+		t.Fatalf("LoadProfiles() = %+v, want %+v", profiles, want)
+	}
+	for i := range want {
+		if profiles[i] != want[i] {
+			t.Errorf("Profile %d = %+v, want %+v", i, profiles[i], want[i])
+		}
+	}
+
+	if _, err := FindProfile("play"); err == nil || !strings.Contains(err.Error(), "available: default, personal, work") {
+		t.Errorf("Expected unknown profile error listing profiles, got %v", err)
+	}
+}
+
+func TestLoadProfilesWithoutConfig(t *testing.T) {
+	t.Setenv("TASKSH_CONFIG", filepath.Join(t.TempDir(), "missing"))
+
+	profiles, defaultName, err := LoadProfiles()
+	if err != nil {
+		t.Fatalf("A missing config should not be an error: %v", err)
+	}
+	if defaultName != "" || len(profiles) != 1 || profiles[0].Name != DefaultProfileName {
+		t.Errorf("Expected only the default profile, got %+v (default %q)", profiles, defaultName)
+	}
+}
+
+func TestUseProfile(t *testing.T) {
+	isolateProfiles(t)
+	t.Setenv("TASKRC", "/home/me/.taskrc")
+
+	work := Profile{Name: "work", Taskrc: "/work/taskrc", Data: "/work/data", TimeDB: "/work/timedb.sqlite3"}
+	if err := UseProfile(work); err != nil {
+		t.Fatalf("UseProfile failed: %v", err)
+	}
+	if os.Getenv("TASKRC") != "/work/taskrc" || os.Getenv("TASKDATA") != "/work/data" || os.Getenv("TASKSH_TIMEDB") != "/work/timedb.sqlite3" {
+		t.Errorf("Profile environment not set: TASKRC=%s TASKDATA=%s", os.Getenv("TASKRC"), os.Getenv("TASKDATA"))
+	}
+	if ActiveProfile() != "work" {
+		t.Errorf("ActiveProfile() = %q, want work", ActiveProfile())
+	}
+
+	// Fields a profile leaves empty go back to the starting environment
+	if err := UseProfile(Profile{Name: "personal", Data: "/home/me/.task"}); err != nil {
+		t.Fatalf("UseProfile failed: %v", err)
+	}
+	if os.Getenv("TASKRC") != "/home/me/.taskrc" || os.Getenv("TASKDATA") != "/home/me/.task" {
+		t.Errorf("Expected starting TASKRC and profile TASKDATA, got TASKRC=%s TASKDATA=%s", os.Getenv("TASKRC"), os.Getenv("TASKDATA"))
+	}
+	if _, set := os.LookupEnv("TASKSH_TIMEDB"); set {
+		t.Error("TASKSH_TIMEDB should be unset when neither the profile nor the environment sets it")
+	}
+}
+
+func TestProfileBackend(t *testing.T) {
+	isolateProfiles(t)
+	t.Setenv("TASKRC", "/home/me/.taskrc")
+
+	dir := t.TempDir()
+	work := Profile{Name: "work", Taskrc: writeFile(t, dir, "taskrc", "uda.size.type=string\n"), Data: filepath.Join(dir, "data")}
+	backend := NewProfileBackend(work)
+	udas, err := backend.GetUDAs(t.Context())
+	if err != nil || len(udas) != 1 || udas[0].Name != "size" {
+		t.Fatalf("Expected the work taskrc's UDA, got %+v, %v", udas, err)
+	}
+	rc, err := loadTaskrc(backend.bind(t.Context()))
+	if err != nil || rc.DataLocation() != work.Data {
+		t.Errorf("Expected the work data location, got %v", err)
+	}
+
+	// The process keeps the environment it started with
+	if os.Getenv("TASKRC") != "/home/me/.taskrc" || ActiveProfile() != "" {
+		t.Errorf("Expected the process environment untouched, got TASKRC=%s profile %q", os.Getenv("TASKRC"), ActiveProfile())
+	}
+	env := work.Environ()
+	if !slices.Contains(env, "TASKRC="+work.Taskrc) || slices.Contains(env, "TASKRC=/home/me/.taskrc") || slices.ContainsFunc(env, func(kv string) bool { return strings.HasPrefix(kv, "TASKSH_TIMEDB=") }) {
+		t.Errorf("Unexpected profile environment: %v", env)
+	}
+}
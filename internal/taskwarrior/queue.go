@@ -0,0 +1,329 @@
+package taskwarrior
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emiller/tasksh/internal/taskwarrior/filter"
+)
+
+// DefaultQueueName names the queue reviewed when no queue is given
+const DefaultQueueName = "default"
+
+// ReviewQueue is a named set of tasks to review: the open tasks matching
+// Filter that were last reviewed more than Interval ago, in Sort order.
+// Interval rules override the interval for some tasks.
+type ReviewQueue struct {
+	Name     string
+	Filter   string // Taskwarrior filter, e.g. "project:work +bug"
+	Sort     string // Report sort, e.g. "reviewed+,modified+"
+	Interval string // Taskwarrior duration, e.g. "6days" or "2w"
+	Rules    []IntervalRule
+}
+
+// IntervalRule sets the review interval of the tasks with a tag, in a
+// project or its subprojects, or with a priority
+type IntervalRule struct {
+	Kind     string // "tag", "project" or "priority"
+	Value    string
+	Interval string // Taskwarrior duration or recurrence, e.g. "1d" or "quarterly"
+}
+
+// String describes the rule, e.g. "project:Work.Fast"
+func (r IntervalRule) String() string {
+	return r.Kind + ":" + r.Value
+}
+
+// matches reports whether the rule applies to a task
+func (r IntervalRule) matches(project string, tags []string, priority string) bool {
+	switch r.Kind {
+	case "tag":
+		return slices.Contains(tags, r.Value)
+	case "project":
+		return project == r.Value || strings.HasPrefix(project, r.Value+".")
+	case "priority":
+		return priority == r.Value
+	}
+	return false
+}
+
+// DefaultReviewQueue reviews every open task once a week. Its filter is
+// stored as the _reviewed report.
+var DefaultReviewQueue = ReviewQueue{Name: DefaultQueueName, Sort: "reviewed+,modified+", Interval: "6days"}
+
+// reviewDue selects open tasks not reviewed within the interval
+func reviewDue(interval string) filter.AndExpr {
+	return filter.And(
+		filter.Or(filter.Attr("reviewed").None(), filter.Attr("reviewed").Before("now-"+interval)),
+		filter.Or(filter.HasTag("PENDING"), filter.HasTag("WAITING")),
+	)
+}
+
+// Label names the queue for display, including an ad hoc filter
+func (q ReviewQueue) Label() string {
+	if q.Filter == "" {
+		return q.Name
+	}
+	return fmt.Sprintf("%s: %s", q.Name, q.Filter)
+}
+
+// SortSpec returns the queue's sort, falling back to the default queue's
+func (q ReviewQueue) SortSpec() string {
+	if q.Sort == "" {
+		return DefaultReviewQueue.Sort
+	}
+	return q.Sort
+}
+
+// interval returns the queue's own review interval
+func (q ReviewQueue) interval() string {
+	if q.Interval == "" {
+		return DefaultReviewQueue.Interval
+	}
+	return q.Interval
+}
+
+// IntervalFor returns the review interval of a task with the given project,
+// tags and priority, and the rule that set it. Tag rules come first, then
+// the longest matching project, then priority; without a matching rule the
+// queue's interval applies and the rule is nil.
+func (q ReviewQueue) IntervalFor(project string, tags []string, priority string) (string, *IntervalRule) {
+	var best *IntervalRule
+	rank := func(r *IntervalRule) int {
+		switch r.Kind {
+		case "tag":
+			return 3
+		case "project":
+			return 2
+		}
+		return 1
+	}
+	for i := range q.Rules {
+		r := &q.Rules[i]
+		if !r.matches(project, tags, priority) {
+			continue
+		}
+		if best == nil || rank(r) > rank(best) || (r.Kind == "project" && best.Kind == "project" && len(r.Value) > len(best.Value)) {
+			best = r
+		}
+	}
+	if best == nil {
+		return q.interval(), nil
+	}
+	return best.Interval, best
+}
+
+// IsDue reports whether a task is due for review at now under the queue's
+// interval rules
+func (q ReviewQueue) IsDue(td *TaskData, now time.Time) bool {
+	if td.Reviewed.IsZero() {
+		return true
+	}
+	interval, _ := q.IntervalFor(td.Project, td.Tags, td.Priority)
+	next, err := filter.AddRecurrence(td.Reviewed.Time, interval, 1)
+	return err != nil || !next.After(now)
+}
+
+// Due returns the UUIDs of the tasks due for review at now, in order
+func (q ReviewQueue) Due(tasks []*TaskData, now time.Time) []string {
+	uuids := make([]string, 0, len(tasks))
+	for _, td := range tasks {
+		if q.IsDue(td, now) {
+			uuids = append(uuids, td.UUID)
+		}
+	}
+	return uuids
+}
+
+// shortestSpan returns the least time an interval can span, measured from
+// 1 February of a common year, where months and quarters are shortest
+func shortestSpan(interval string) (time.Duration, error) {
+	from := time.Date(2023, time.February, 1, 0, 0, 0, 0, time.UTC)
+	next, err := filter.AddRecurrence(from, interval, 1)
+	if err != nil {
+		return 0, err
+	}
+	return next.Sub(from), nil
+}
+
+// Expr returns the filter selecting the tasks due for review in the queue.
+// With interval rules it selects the open tasks matching the queue's filter
+// that are old enough for the shortest interval to have passed, and IsDue
+// decides which of them are due.
+func (q ReviewQueue) Expr() (filter.Expr, error) {
+	interval := q.interval()
+	if _, err := filter.ResolveDate("now-"+interval, time.Now()); err != nil {
+		return nil, fmt.Errorf("invalid review interval %q for queue %s", interval, q.Name)
+	}
+	shortest, err := shortestSpan(interval)
+	if err != nil {
+		return nil, fmt.Errorf("invalid review interval %q for queue %s", interval, q.Name)
+	}
+	for _, r := range q.Rules {
+		span, err := shortestSpan(r.Interval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid review interval %q for %s", r.Interval, r)
+		}
+		shortest = min(shortest, span)
+	}
+
+	var due filter.Expr = reviewDue(interval)
+	if len(q.Rules) > 0 {
+		due = reviewDue(fmt.Sprintf("%dh", int(shortest.Hours())))
+	}
+	if strings.TrimSpace(q.Filter) == "" {
+		return due, nil
+	}
+	expr, err := filter.Parse([]string{q.Filter})
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter for queue %s: %w", q.Name, err)
+	}
+	return filter.And(expr, due), nil
+}
+
+// parseIntervalRule reads a rule key such as tag.someday or
+// project.Work.Fast
+func parseIntervalRule(key, interval string) (IntervalRule, bool) {
+	kind, match, ok := strings.Cut(key, ".")
+	if !ok || match == "" || (kind != "tag" && kind != "project" && kind != "priority") {
+		return IntervalRule{}, false
+	}
+	return IntervalRule{Kind: kind, Value: match, Interval: interval}, true
+}
+
+// LoadReviewQueues reads the review queues from the tasksh config, sorted by
+// name. Each setting is optional and falls back to the default queue's,
+// which the config can also change. Interval rules belong to one queue;
+// review.interval.* sets the default queue's:
+//
+//	queue.bugs.filter=project:work +bug
+//	queue.bugs.sort=urgency-
+//	queue.bugs.interval=2days
+//	queue.bugs.interval.priority.H=1d
+//	review.interval.project.Work.Fast=1d
+//	review.interval.tag.someday=quarterly
+//	review.interval.priority.L=2w
+func LoadReviewQueues() ([]ReviewQueue, error) {
+	settings, err := loadTaskshConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	defaults := DefaultReviewQueue
+	byName := map[string]*ReviewQueue{DefaultQueueName: &defaults}
+	queueNamed := func(name string) *ReviewQueue {
+		queue := byName[name]
+		if queue == nil {
+			queue = &ReviewQueue{Name: name}
+			byName[name] = queue
+		}
+		return queue
+	}
+	for key, value := range settings {
+		if rest, ok := strings.CutPrefix(key, "review.interval."); ok {
+			if rule, ok := parseIntervalRule(rest, value); ok {
+				defaults.Rules = append(defaults.Rules, rule)
+			}
+			continue
+		}
+		rest, ok := strings.CutPrefix(key, "queue.")
+		if !ok {
+			continue
+		}
+		if name, rest, ok := strings.Cut(rest, ".interval."); ok && name != "" {
+			if rule, ok := parseIntervalRule(rest, value); ok {
+				queue := queueNamed(name)
+				queue.Rules = append(queue.Rules, rule)
+			}
+			continue
+		}
+		dot := strings.LastIndexByte(rest, '.')
+		if dot <= 0 {
+			continue
+		}
+		name, field := rest[:dot], rest[dot+1:]
+		queue := queueNamed(name)
+		switch field {
+		case "filter":
+			queue.Filter = value
+		case "sort":
+			queue.Sort = value
+		case "interval":
+			queue.Interval = value
+		}
+	}
+
+	queues := make([]ReviewQueue, 0, len(byName))
+	for _, queue := range byName {
+		if queue.Interval == "" {
+			queue.Interval = defaults.Interval
+		}
+		sort.Slice(queue.Rules, func(i, j int) bool { return queue.Rules[i].String() < queue.Rules[j].String() })
+		queues = append(queues, *queue)
+	}
+	sort.Slice(queues, func(i, j int) bool { return queues[i].Name < queues[j].Name })
+	return queues, nil
+}
+
+// FindReviewQueue returns the named queue from the tasksh config
+func FindReviewQueue(name string) (ReviewQueue, error) {
+	queues, err := LoadReviewQueues()
+	if err != nil {
+		return ReviewQueue{}, err
+	}
+	names := make([]string, len(queues))
+	for i, queue := range queues {
+		if queue.Name == name {
+			return queue, nil
+		}
+		names[i] = queue.Name
+	}
+	return ReviewQueue{}, fmt.Errorf("unknown review queue %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+// SortOrder returns a comparison for a report sort such as
+// "reviewed+,modified+" or "urgency-". Numbers compare numerically and
+// everything else, including export dates, as text; unset values sort first.
+func SortOrder(spec string) func(a, b *TaskData) int {
+	type sortKey struct {
+		attr string
+		desc bool
+	}
+	var keys []sortKey
+	for _, field := range strings.Split(spec, ",") {
+		// A trailing "/" marks a report break, which doesn't affect order
+		field = strings.TrimSuffix(strings.TrimSpace(field), "/")
+		if field == "" {
+			continue
+		}
+		keys = append(keys, sortKey{strings.TrimRight(field, "+-"), strings.HasSuffix(field, "-")})
+	}
+
+	return func(a, b *TaskData) int {
+		for _, k := range keys {
+			c := compareAttr(a.Attr(k.attr), b.Attr(k.attr))
+			if k.desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	}
+}
+
+// compareAttr compares two attribute values
+func compareAttr(a, b string) int {
+	if x, err := strconv.ParseFloat(a, 64); err == nil {
+		if y, err := strconv.ParseFloat(b, 64); err == nil {
+			return cmp.Compare(x, y)
+		}
+	}
+	return strings.Compare(a, b)
+}
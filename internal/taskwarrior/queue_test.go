@@ -0,0 +1,145 @@
+package taskwarrior
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadReviewQueues(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TASKSH_CONFIG", writeFile(t, dir, "config", "queue.bugs.filter=project:work +bug\nqueue.bugs.sort=urgency-\nqueue.default.interval=2w\nqueue.bugs.interval.priority.H=1d\nreview.interval.project.Work.Fast=1d\nreview.interval.tag.someday=quarterly\n"))
+
+	queues, err := LoadReviewQueues()
+	if err != nil {
+		t.Fatalf("LoadReviewQueues failed: %v", err)
+	}
+	// Each queue has only its own rules
+	want := []ReviewQueue{
+		{Name: "bugs", Filter: "project:work +bug", Sort: "urgency-", Interval: "2w", Rules: []IntervalRule{
+			{Kind: "priority", Value: "H", Interval: "1d"},
+		}},
+		{Name: "default", Sort: "reviewed+,modified+", Interval: "2w", Rules: []IntervalRule{
+			{Kind: "project", Value: "Work.Fast", Interval: "1d"},
+			{Kind: "tag", Value: "someday", Interval: "quarterly"},
+		}},
+	}
+	if !reflect.DeepEqual(queues, want) {
+		t.Errorf("LoadReviewQueues() = %+v, want %+v", queues, want)
+	}
+
+	if _, err := FindReviewQueue("chores"); err == nil || !strings.Contains(err.Error(), "available: bugs, default") {
+		t.Errorf("Expected an unknown queue error listing the queues, got %v", err)
+	}
+}
+
+func TestReviewQueueExpr(t *testing.T) {
+	expr, err := DefaultReviewQueue.Expr()
+	if err != nil {
+		t.Fatalf("Expr failed: %v", err)
+	}
+	if expr.String() != ReviewFilter.String() {
+		t.Errorf("Default queue filter = %s, want the _reviewed filter %s", expr, ReviewFilter)
+	}
+
+	expr, err = ReviewQueue{Name: "bugs", Filter: "project:work +bug", Interval: "2days"}.Expr()
+	if err != nil {
+		t.Fatalf("Expr failed: %v", err)
+	}
+	for _, term := range []string{"project:work", "+bug", "reviewed.before:now-2days"} {
+		if !strings.Contains(expr.String(), term) {
+			t.Errorf("Queue filter %s is missing %s", expr, term)
+		}
+	}
+
+	if _, err := (ReviewQueue{Name: "bad", Interval: "often"}).Expr(); err == nil {
+		t.Error("Expected an error for an invalid interval")
+	}
+}
+
+func TestIntervalRules(t *testing.T) {
+	queue := ReviewQueue{Name: "default", Interval: "1w", Rules: []IntervalRule{
+		{Kind: "priority", Value: "H", Interval: "2d"},
+		{Kind: "project", Value: "Work", Interval: "3d"},
+		{Kind: "project", Value: "Work.Fast", Interval: "1d"},
+		{Kind: "tag", Value: "someday", Interval: "quarterly"},
+	}}
+	tests := []struct {
+		project, priority string
+		tags              []string
+		want, rule        string
+	}{
+		{"Work.Fast.API", "H", nil, "1d", "project:Work.Fast"},
+		{"Work.Slow", "", nil, "3d", "project:Work"},
+		{"Workshop", "H", nil, "2d", "priority:H"},
+		{"Work.Fast", "", []string{"someday"}, "quarterly", "tag:someday"},
+		{"Home", "L", nil, "1w", ""},
+	}
+	for _, tt := range tests {
+		interval, rule := queue.IntervalFor(tt.project, tt.tags, tt.priority)
+		ruleName := ""
+		if rule != nil {
+			ruleName = rule.String()
+		}
+		if interval != tt.want || ruleName != tt.rule {
+			t.Errorf("IntervalFor(%s, %v, %s) = %s (%s), want %s (%s)", tt.project, tt.tags, tt.priority, interval, ruleName, tt.want, tt.rule)
+		}
+	}
+
+	now := time.Date(2024, 6, 12, 10, 0, 0, 0, time.UTC)
+	reviewed := NewDate(now.AddDate(0, 0, -2))
+	tasks := []*TaskData{
+		{UUID: "fast", Project: "Work.Fast", Reviewed: reviewed},
+		{UUID: "home", Project: "Home", Reviewed: reviewed},
+		{UUID: "new", Project: "Home"},
+		{UUID: "someday", Tags: []string{"someday"}, Reviewed: NewDate(now.AddDate(0, -2, 0))},
+	}
+	if got := queue.Due(tasks, now); !slices.Equal(got, []string{"fast", "new"}) {
+		t.Errorf("Due() = %v, want [fast new]", got)
+	}
+
+	// With rules the filter keeps the tasks the shortest interval could
+	// make due, and Due decides
+	expr, err := queue.Expr()
+	if err != nil {
+		t.Fatalf("Expr failed: %v", err)
+	}
+	if !strings.Contains(expr.String(), "reviewed.before:now-24h") {
+		t.Errorf("Queue filter with rules should keep tasks not reviewed within a day: %s", expr)
+	}
+	monthly := ReviewQueue{Name: "slow", Interval: "3months", Rules: []IntervalRule{{Kind: "tag", Value: "later", Interval: "monthly"}}}
+	if expr, _ := monthly.Expr(); !strings.Contains(expr.String(), "reviewed.before:now-672h") {
+		t.Errorf("Queue filter should allow for the shortest month: %s", expr)
+	}
+	queue.Rules = append(queue.Rules, IntervalRule{Kind: "tag", Value: "x", Interval: "sometimes"})
+	if _, err := queue.Expr(); err == nil {
+		t.Error("Expected an error for an invalid rule interval")
+	}
+}
+
+func TestSortOrder(t *testing.T) {
+	tasks := []*TaskData{
+		{UUID: "a", Priority: "L", Urgency: 2},
+		{UUID: "b", Priority: "H", Urgency: 10},
+		{UUID: "c", Urgency: 3},
+		{UUID: "d", Priority: "H", Urgency: 4.5},
+	}
+	tests := map[string]string{
+		"urgency-":           "b,d,c,a",
+		"priority+,urgency-": "c,b,d,a",
+		"priority-/,uuid+":   "a,b,d,c",
+	}
+	for spec, want := range tests {
+		sorted := slices.Clone(tasks)
+		slices.SortStableFunc(sorted, SortOrder(spec))
+		var got []string
+		for _, td := range sorted {
+			got = append(got, td.UUID)
+		}
+		if strings.Join(got, ",") != want {
+			t.Errorf("SortOrder(%q) = %v, want %s", spec, got, want)
+		}
+	}
+}
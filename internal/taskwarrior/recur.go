@@ -0,0 +1,51 @@
+package taskwarrior
+
+import (
+	"fmt"
+
+	"github.com/emiller/tasksh/internal/taskwarrior/filter"
+)
+
+// Taskwarrior keeps a recurring task as a template with status "recurring"
+// and generates pending instances from it. Each instance records its
+// template in parent and its position in the series in imask; the template's
+// mask has one character per instance generated so far.
+
+// IsRecurringTemplate reports whether the task is the template of a
+// recurring series
+func (t *Task) IsRecurringTemplate() bool {
+	return t.Status == "recurring"
+}
+
+// IsRecurringInstance reports whether the task was generated from a
+// recurring template
+func (t *Task) IsRecurringInstance() bool {
+	return t.Parent != ""
+}
+
+// SeriesUUID returns the UUID of the template of the task's recurring
+// series, or "" if the task doesn't recur
+func (t *Task) SeriesUUID() string {
+	if t.IsRecurringTemplate() {
+		return t.UUID
+	}
+	return t.Parent
+}
+
+// NextRecurrence returns the due date of the next instance in the series:
+// one period after an instance, or the first one the template has not yet
+// generated
+func (t *Task) NextRecurrence() (Date, error) {
+	if t.Recur == "" || t.Due.IsZero() {
+		return Date{}, fmt.Errorf("task is not a recurring task with a due date")
+	}
+	steps := 1
+	if t.IsRecurringTemplate() {
+		steps = len(t.Mask)
+	}
+	next, err := filter.AddRecurrence(t.Due.Local(), t.Recur, steps)
+	if err != nil {
+		return Date{}, err
+	}
+	return NewDate(next), nil
+}
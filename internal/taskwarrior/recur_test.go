@@ -0,0 +1,40 @@
+package taskwarrior
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextRecurrence(t *testing.T) {
+	due := NewDate(time.Date(2024, 6, 14, 9, 0, 0, 0, time.Local))
+	template := &Task{UUID: "t", Status: "recurring", Recur: "weekly", Mask: "+-+", Due: due}
+	instance := &Task{UUID: "i", Status: "pending", Recur: "weekly", Parent: "t", Due: due}
+
+	if !template.IsRecurringTemplate() || template.SeriesUUID() != "t" {
+		t.Errorf("Template not detected: series %q", template.SeriesUUID())
+	}
+	if !instance.IsRecurringInstance() || instance.SeriesUUID() != "t" {
+		t.Errorf("Instance not detected: series %q", instance.SeriesUUID())
+	}
+
+	// The template has generated three instances, so the fourth is next
+	next, err := template.NextRecurrence()
+	if err != nil {
+		t.Fatalf("NextRecurrence failed: %v", err)
+	}
+	if want := time.Date(2024, 7, 5, 9, 0, 0, 0, time.Local); !next.Equal(want) {
+		t.Errorf("Template next = %v, want %v", next.Time, want)
+	}
+
+	next, err = instance.NextRecurrence()
+	if err != nil {
+		t.Fatalf("NextRecurrence failed: %v", err)
+	}
+	if want := time.Date(2024, 6, 21, 9, 0, 0, 0, time.Local); !next.Equal(want) {
+		t.Errorf("Instance next = %v, want %v", next.Time, want)
+	}
+
+	if _, err := (&Task{Recur: "weekly"}).NextRecurrence(); err == nil {
+		t.Error("Expected an error without a due date")
+	}
+}
@@ -0,0 +1,324 @@
+package taskwarrior
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Annotation is a timestamped note attached to a task
+type Annotation struct {
+	Entry       Date   `json:"entry,omitzero"`
+	Description string `json:"description"`
+}
+
+// Task represents a Taskwarrior task
+type Task struct {
+	UUID        string
+	Description string
+	Project     string
+	Priority    string
+	Status      string
+	Due         Date
+	Wait        Date
+	Entry       Date
+	Tags        []string
+	Annotations []Annotation
+	Scheduled   Date
+	Until       Date
+	Recur       string
+	Mask        string
+	Depends     []string
+	Start       Date
+	End         Date
+	Parent      string
+	Urgency     float64
+	UDA         map[string]string
+}
+
+// TaskData represents the full task data from JSON export
+type TaskData struct {
+	ID          int          `json:"id"`
+	UUID        string       `json:"uuid"`
+	Description string       `json:"description"`
+	Project     string       `json:"project,omitempty"`
+	Priority    string       `json:"priority,omitempty"`
+	Status      string       `json:"status"`
+	Due         Date         `json:"due,omitzero"`
+	Wait        Date         `json:"wait,omitzero"`
+	Entry       Date         `json:"entry,omitzero"`
+	Modified    Date         `json:"modified,omitzero"`
+	Reviewed    Date         `json:"reviewed,omitzero"`
+	Scheduled   Date         `json:"scheduled,omitzero"`
+	Until       Date         `json:"until,omitzero"`
+	Start       Date         `json:"start,omitzero"`
+	End         Date         `json:"end,omitzero"`
+	Recur       string       `json:"recur,omitempty"`
+	Mask        string       `json:"mask,omitempty"`
+	Imask       int          `json:"imask,omitempty"`
+	Parent      string       `json:"parent,omitempty"`
+	Tags        []string     `json:"tags,omitempty"`
+	Annotations []Annotation `json:"annotations,omitempty"`
+	Depends     []string     `json:"depends,omitempty"`
+	Urgency     float64      `json:"urgency"`
+
+	// UDA holds user defined attributes not modeled above, keyed by name.
+	// Numeric UDAs keep their JSON text, e.g. "2.5".
+	UDA map[string]string `json:"-"`
+}
+
+// taskDataFields lists the export keys decoded into TaskData fields, plus
+// internal recurrence bookkeeping keys that are not UDAs
+var taskDataFields = map[string]bool{
+	"id": true, "uuid": true, "description": true, "project": true,
+	"priority": true, "status": true, "due": true, "wait": true,
+	"entry": true, "modified": true, "reviewed": true, "scheduled": true,
+	"until": true, "start": true, "end": true, "recur": true, "mask": true,
+	"imask": true, "parent": true, "tags": true, "annotations": true,
+	"depends": true, "urgency": true,
+	"last": true, "rtype": true, "template": true,
+}
+
+// taskDataJSON has the TaskData fields without its JSON methods
+type taskDataJSON TaskData
+
+// UnmarshalJSON decodes a task from `task export`, collecting unknown keys
+// as UDAs and accepting depends as either a list or a comma separated string
+func (td *TaskData) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	// Older Taskwarrior versions export depends as "uuid1,uuid2"
+	var depends []string
+	if dep, ok := raw["depends"]; ok {
+		var list []string
+		if err := json.Unmarshal(dep, &list); err != nil {
+			var joined string
+			if err := json.Unmarshal(dep, &joined); err != nil {
+				return fmt.Errorf("invalid depends value: %s", dep)
+			}
+			list = splitDepends(joined)
+		}
+		depends = list
+		delete(raw, "depends")
+	}
+
+	stripped, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+
+	var decoded taskDataJSON
+	if err := json.Unmarshal(stripped, &decoded); err != nil {
+		return err
+	}
+	*td = TaskData(decoded)
+	td.Depends = depends
+
+	for key, value := range raw {
+		if taskDataFields[key] {
+			continue
+		}
+		if td.UDA == nil {
+			td.UDA = make(map[string]string)
+		}
+		var s string
+		if err := json.Unmarshal(value, &s); err == nil {
+			td.UDA[key] = s
+		} else {
+			td.UDA[key] = string(value)
+		}
+	}
+
+	return nil
+}
+
+// MarshalJSON encodes a task in `task import` format, including UDAs
+func (td TaskData) MarshalJSON() ([]byte, error) {
+	known, err := json.Marshal(taskDataJSON(td))
+	if err != nil {
+		return nil, err
+	}
+	if len(td.UDA) == 0 {
+		return known, nil
+	}
+
+	var merged map[string]json.RawMessage
+	if err := json.Unmarshal(known, &merged); err != nil {
+		return nil, err
+	}
+	for key, value := range td.UDA {
+		if taskDataFields[key] {
+			continue
+		}
+		if isJSONNumber(value) {
+			merged[key] = json.RawMessage(value)
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		merged[key] = encoded
+	}
+	return json.Marshal(merged)
+}
+
+// isJSONNumber reports whether a UDA value is a number as Taskwarrior
+// exports one, so it can be written back unquoted. Text that merely parses
+// as a number, like "02134", "+5" or "NaN", stays a string.
+func isJSONNumber(value string) bool {
+	if !json.Valid([]byte(value)) {
+		return false
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	return err == nil && strconv.FormatFloat(f, 'f', -1, 64) == value
+}
+
+// splitDepends splits a comma separated depends value
+func splitDepends(value string) []string {
+	var uuids []string
+	for _, uuid := range strings.Split(value, ",") {
+		if uuid = strings.TrimSpace(uuid); uuid != "" {
+			uuids = append(uuids, uuid)
+		}
+	}
+	return uuids
+}
+
+// ToTask converts exported task data to a Task
+func (td *TaskData) ToTask() *Task {
+	return &Task{
+		UUID:        td.UUID,
+		Description: td.Description,
+		Project:     td.Project,
+		Priority:    td.Priority,
+		Status:      td.Status,
+		Due:         td.Due,
+		Wait:        td.Wait,
+		Entry:       td.Entry,
+		Tags:        td.Tags,
+		Annotations: td.Annotations,
+		Scheduled:   td.Scheduled,
+		Until:       td.Until,
+		Recur:       td.Recur,
+		Mask:        td.Mask,
+		Depends:     td.Depends,
+		Start:       td.Start,
+		End:         td.End,
+		Parent:      td.Parent,
+		Urgency:     td.Urgency,
+		UDA:         td.UDA,
+	}
+}
+
+// Attr returns the string value of an attribute or UDA so task data can be
+// evaluated by the filter package
+func (td *TaskData) Attr(name string) string {
+	switch name {
+	case "uuid":
+		return td.UUID
+	case "id":
+		return strconv.Itoa(td.ID)
+	case "description":
+		return td.Description
+	case "project":
+		return td.Project
+	case "priority":
+		return td.Priority
+	case "status":
+		return td.Status
+	case "due":
+		return td.Due.String()
+	case "wait":
+		return td.Wait.String()
+	case "entry":
+		return td.Entry.String()
+	case "modified":
+		return td.Modified.String()
+	case "reviewed":
+		return td.Reviewed.String()
+	case "scheduled":
+		return td.Scheduled.String()
+	case "until":
+		return td.Until.String()
+	case "start":
+		return td.Start.String()
+	case "end":
+		return td.End.String()
+	case "recur":
+		return td.Recur
+	case "parent":
+		return td.Parent
+	case "tags":
+		return strings.Join(td.Tags, ",")
+	case "depends":
+		return strings.Join(td.Depends, ",")
+	case "annotations":
+		descriptions := make([]string, len(td.Annotations))
+		for i, a := range td.Annotations {
+			descriptions[i] = a.Description
+		}
+		return strings.Join(descriptions, "\n")
+	case "urgency":
+		return strconv.FormatFloat(td.Urgency, 'f', -1, 64)
+	}
+	return td.UDA[name]
+}
+
+// HasTag reports whether the task has the given tag
+func (td *TaskData) HasTag(tag string) bool {
+	return slices.Contains(td.Tags, tag)
+}
+
+// parseTask decodes a single exported task
+func parseTask(data []byte) (*Task, error) {
+	var td TaskData
+	if err := json.Unmarshal(data, &td); err != nil {
+		return nil, fmt.Errorf("failed to parse task JSON: %w", err)
+	}
+	return td.ToTask(), nil
+}
+
+// FormatDue returns the due date as a short local date, or "" if unset
+func (t *Task) FormatDue() string {
+	if t.Due.IsZero() {
+		return ""
+	}
+	return t.Due.Local().Format("2006-01-02")
+}
+
+// IsOverdue reports whether the task's due date has passed
+func (t *Task) IsOverdue() bool {
+	return !t.Due.IsZero() && t.Due.Before(time.Now())
+}
+
+// HasTag reports whether the task has the given tag
+func (t *Task) HasTag(tag string) bool {
+	return slices.Contains(t.Tags, tag)
+}
+
+// GetDisplayTags returns the tags formatted as "+tag1 +tag2"
+func (t *Task) GetDisplayTags() string {
+	if len(t.Tags) == 0 {
+		return ""
+	}
+	return "+" + strings.Join(t.Tags, " +")
+}
+
+// GetShortDescription truncates the description to maxLen characters
+func (t *Task) GetShortDescription(maxLen int) string {
+	runes := []rune(t.Description)
+	if len(runes) <= maxLen {
+		return t.Description
+	}
+	if maxLen <= 3 {
+		return string(runes[:maxLen])
+	}
+	return string(runes[:maxLen-3]) + "..."
+}
@@ -0,0 +1,168 @@
+package taskwarrior
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestTaskDataUnmarshalFullExport(t *testing.T) {
+	data := `{
+		"id": 3,
+		"uuid": "c0ffee00-0000-4000-8000-000000000001",
+		"description": "Prepare release",
+		"project": "tasksh",
+		"status": "pending",
+		"entry": "20240601T120000Z",
+		"modified": "20240602T120000Z",
+		"scheduled": "20240610T090000Z",
+		"until": "20240701T000000Z",
+		"start": "20240603T080000Z",
+		"recur": "weekly",
+		"parent": "c0ffee00-0000-4000-8000-00000000000a",
+		"imask": 2,
+		"tags": ["release", "work"],
+		"annotations": [{"entry": "20240602T130000Z", "description": "Draft notes"}],
+		"depends": ["c0ffee00-0000-4000-8000-000000000002"],
+		"estimate": "PT2H",
+		"points": 3,
+		"urgency": 9.5
+	}`
+
+	var td TaskData
+	if err := json.Unmarshal([]byte(data), &td); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if td.ID != 3 || td.Scheduled.String() != "20240610T090000Z" || td.Until.String() != "20240701T000000Z" {
+		t.Errorf("Unexpected fields: %+v", td)
+	}
+	if td.Start.String() != "20240603T080000Z" || td.Recur != "weekly" || td.Imask != 2 {
+		t.Errorf("Unexpected recurrence fields: %+v", td)
+	}
+	if !slices.Equal(td.Tags, []string{"release", "work"}) {
+		t.Errorf("Unexpected tags: %v", td.Tags)
+	}
+	if len(td.Annotations) != 1 || td.Annotations[0].Entry.String() != "20240602T130000Z" || td.Annotations[0].Description != "Draft notes" {
+		t.Errorf("Unexpected annotations: %+v", td.Annotations)
+	}
+	if !slices.Equal(td.Depends, []string{"c0ffee00-0000-4000-8000-000000000002"}) {
+		t.Errorf("Unexpected depends: %v", td.Depends)
+	}
+	if len(td.UDA) != 2 || td.UDA["estimate"] != "PT2H" || td.UDA["points"] != "3" {
+		t.Errorf("Unexpected UDAs: %v", td.UDA)
+	}
+	if td.Urgency != 9.5 {
+		t.Errorf("Expected urgency 9.5, got %f", td.Urgency)
+	}
+
+	task := td.ToTask()
+	if !task.Scheduled.Equal(td.Scheduled.Time) || task.Parent != td.Parent || task.UDA["estimate"] != "PT2H" || len(task.Annotations) != 1 {
+		t.Errorf("ToTask dropped fields: %+v", task)
+	}
+}
+
+func TestTaskDataDependsString(t *testing.T) {
+	// Taskwarrior 2.5 exports depends as a comma separated string
+	var td TaskData
+	data := `{"uuid":"x","description":"d","status":"pending","depends":"a-1,b-2"}`
+	if err := json.Unmarshal([]byte(data), &td); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !slices.Equal(td.Depends, []string{"a-1", "b-2"}) {
+		t.Errorf("Expected depends [a-1 b-2], got %v", td.Depends)
+	}
+	if td.UDA != nil {
+		t.Errorf("Expected no UDAs, got %v", td.UDA)
+	}
+}
+
+func TestTaskDataMarshalRoundTrip(t *testing.T) {
+	original := TaskData{
+		UUID:        "c0ffee00-0000-4000-8000-000000000003",
+		Description: "Round trip",
+		Status:      "pending",
+		Tags:        []string{"a"},
+		UDA:         map[string]string{"estimate": "PT1H", "points": "5"},
+	}
+
+	encoded, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(encoded, &raw); err != nil {
+		t.Fatalf("Unmarshal raw failed: %v", err)
+	}
+	if raw["estimate"] != "PT1H" || raw["points"] != 5.0 {
+		t.Errorf("UDAs not flattened into JSON: %s", encoded)
+	}
+	// task import rejects an empty date, so unset ones are left out
+	if _, ok := raw["entry"]; ok {
+		t.Errorf("Zero entry marshaled: %s", encoded)
+	}
+	if _, ok := raw["modified"]; ok {
+		t.Errorf("Zero modified marshaled: %s", encoded)
+	}
+
+	var decoded TaskData
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if decoded.UDA["estimate"] != "PT1H" || decoded.UDA["points"] != "5" || !slices.Equal(decoded.Tags, []string{"a"}) {
+		t.Errorf("Round trip mismatch: %+v", decoded)
+	}
+	if !decoded.Entry.IsZero() || !decoded.Modified.IsZero() {
+		t.Errorf("Expected zero entry and modified after round trip, got %v and %v", decoded.Entry, decoded.Modified)
+	}
+}
+
+func TestTaskDataMarshalStringUDAs(t *testing.T) {
+	// Values that parse as numbers but aren't JSON numbers stay strings
+	for _, value := range []string{"02134", "+5", "NaN", "Inf", "1e300", "2.50"} {
+		td := TaskData{UUID: "c0ffee00-0000-4000-8000-000000000004", Description: "Zip", Status: "pending", UDA: map[string]string{"zip": value}}
+		encoded, err := json.Marshal(td)
+		if err != nil {
+			t.Errorf("Marshal with UDA %q failed: %v", value, err)
+			continue
+		}
+		var raw map[string]any
+		if err := json.Unmarshal(encoded, &raw); err != nil || raw["zip"] != value {
+			t.Errorf("Expected UDA %q kept as a string, got %s", value, encoded)
+		}
+	}
+}
+
+func TestTaskHelpers(t *testing.T) {
+	task := &Task{
+		Description: "A fairly long task description",
+		Tags:        []string{"work", "urgent"},
+		Due:         NewDate(time.Now().Add(-time.Hour)),
+	}
+
+	if got := task.GetDisplayTags(); got != "+work +urgent" {
+		t.Errorf("GetDisplayTags() = %q", got)
+	}
+	if !task.HasTag("urgent") || task.HasTag("home") {
+		t.Error("HasTag returned wrong result")
+	}
+	if !task.IsOverdue() {
+		t.Error("Expected task to be overdue")
+	}
+	if got := task.GetShortDescription(10); got != "A fairl..." {
+		t.Errorf("GetShortDescription(10) = %q", got)
+	}
+	if got := task.GetShortDescription(100); got != task.Description {
+		t.Errorf("GetShortDescription(100) = %q", got)
+	}
+
+	parsed, err := parseTask([]byte(`{"uuid":"u","description":"Parsed","status":"pending","tags":["x"]}`))
+	if err != nil {
+		t.Fatalf("parseTask failed: %v", err)
+	}
+	if parsed.Description != "Parsed" || !parsed.HasTag("x") {
+		t.Errorf("Unexpected parsed task: %+v", parsed)
+	}
+}
@@ -0,0 +1,322 @@
+package taskwarrior
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Taskrc is a Taskwarrior configuration file with its includes applied
+type Taskrc struct {
+	// Path is the top level file; Files lists every file read, in order
+	Path  string
+	Files []string
+
+	// Settings maps each name to its value; later lines win, as in Taskwarrior
+	Settings map[string]string
+}
+
+// ContextDefinition is a context from context.<name>.read/write, or the
+// older context.<name> form, which only has a read filter
+type ContextDefinition struct {
+	Name  string
+	Read  string
+	Write string
+}
+
+// UDADefinition is a user defined attribute from uda.<name>.*
+type UDADefinition struct {
+	Name    string
+	Type    string
+	Label   string
+	Values  []string
+	Default string
+}
+
+// Report is a report definition from report.<name>.*
+type Report struct {
+	Name        string
+	Description string
+	Filter      string
+	Sort        string
+	Columns     []string
+	Labels      []string
+}
+
+// TaskrcPath returns the file Taskwarrior reads its config from: $TASKRC,
+// then ~/.taskrc, then $XDG_CONFIG_HOME/task/taskrc
+func TaskrcPath() (string, error) {
+	return taskrcPath(context.Background())
+}
+
+// taskrcPath is TaskrcPath with TASKRC read from the environment ctx carries
+func taskrcPath(ctx context.Context) (string, error) {
+	if path := getenv(ctx, "TASKRC"); path != "" {
+		return expandHome(path), nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	path := filepath.Join(homeDir, ".taskrc")
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		configDir = filepath.Join(homeDir, ".config")
+	}
+	if xdgPath := filepath.Join(configDir, "task", "taskrc"); fileExists(xdgPath) {
+		return xdgPath, nil
+	}
+	return path, nil
+}
+
+// LoadTaskrc reads the user's taskrc. TASKDATA overrides data.location.
+func LoadTaskrc() (*Taskrc, error) {
+	return loadTaskrc(context.Background())
+}
+
+// loadTaskrc is LoadTaskrc with the environment ctx carries
+func loadTaskrc(ctx context.Context) (*Taskrc, error) {
+	path, err := taskrcPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rc, err := ParseTaskrc(path)
+	if err != nil {
+		return nil, err
+	}
+	if dir := getenv(ctx, "TASKDATA"); dir != "" {
+		rc.Settings["data.location"] = dir
+	}
+	return rc, nil
+}
+
+// ParseTaskrc reads a taskrc file and the files it includes
+func ParseTaskrc(path string) (*Taskrc, error) {
+	rc := &Taskrc{Path: path, Settings: make(map[string]string)}
+	if err := rc.parseFile(path, nil); err != nil {
+		return nil, err
+	}
+	return rc, nil
+}
+
+// parseFile reads one file into rc.Settings. including holds the files
+// currently being read so include loops are reported instead of followed.
+func (rc *Taskrc) parseFile(path string, including []string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to read taskrc %s: %w", path, err)
+	}
+	for _, parent := range including {
+		if parent == abs {
+			return fmt.Errorf("failed to read taskrc %s: include loop", path)
+		}
+	}
+
+	file, err := os.Open(abs)
+	if err != nil {
+		return fmt.Errorf("failed to read taskrc: %w", err)
+	}
+	defer file.Close()
+	rc.Files = append(rc.Files, abs)
+
+	scanner := bufio.NewScanner(file)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if name, value, ok := strings.Cut(line, "="); ok {
+			rc.Settings[strings.TrimSpace(name)] = strings.TrimSpace(value)
+			continue
+		}
+
+		include, ok := strings.CutPrefix(line, "include")
+		if !ok || strings.TrimSpace(include) == "" {
+			return fmt.Errorf("failed to read taskrc %s:%d: malformed entry %q", abs, lineNum, line)
+		}
+		include = expandHome(strings.TrimSpace(include))
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(abs), include)
+		}
+		if err := rc.parseFile(include, append(including, abs)); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read taskrc %s: %w", abs, err)
+	}
+	return nil
+}
+
+// Get returns a setting and whether it is set
+func (rc *Taskrc) Get(name string) (string, bool) {
+	value, ok := rc.Settings[name]
+	return value, ok
+}
+
+// DataLocation returns the data directory, defaulting to ~/.task
+func (rc *Taskrc) DataLocation() string {
+	if dir := rc.Settings["data.location"]; dir != "" {
+		return expandHome(dir)
+	}
+	return expandHome("~/.task")
+}
+
+// CurrentContext returns the active context, or "none"
+func (rc *Taskrc) CurrentContext() string {
+	if name := rc.Settings["context"]; name != "" {
+		return name
+	}
+	return "none"
+}
+
+// Contexts returns the defined contexts sorted by name
+func (rc *Taskrc) Contexts() []ContextDefinition {
+	byName := make(map[string]*ContextDefinition)
+	get := func(name string) *ContextDefinition {
+		if byName[name] == nil {
+			byName[name] = &ContextDefinition{Name: name}
+		}
+		return byName[name]
+	}
+
+	for key, value := range rc.Settings {
+		rest, ok := strings.CutPrefix(key, "context.")
+		if !ok {
+			continue
+		}
+		switch {
+		case strings.HasSuffix(rest, ".read"):
+			get(strings.TrimSuffix(rest, ".read")).Read = value
+		case strings.HasSuffix(rest, ".write"):
+			get(strings.TrimSuffix(rest, ".write")).Write = value
+		case !strings.Contains(rest, "."):
+			if def := get(rest); def.Read == "" {
+				def.Read = value
+			}
+		}
+	}
+
+	contexts := make([]ContextDefinition, 0, len(byName))
+	for _, def := range byName {
+		contexts = append(contexts, *def)
+	}
+	sort.Slice(contexts, func(i, j int) bool { return contexts[i].Name < contexts[j].Name })
+	return contexts
+}
+
+// UDAs returns the user defined attributes sorted by name
+func (rc *Taskrc) UDAs() []UDADefinition {
+	byName := make(map[string]*UDADefinition)
+	for key, value := range rc.Settings {
+		rest, ok := strings.CutPrefix(key, "uda.")
+		if !ok {
+			continue
+		}
+		name, field, ok := strings.Cut(rest, ".")
+		if !ok {
+			continue
+		}
+		def := byName[name]
+		if def == nil {
+			def = &UDADefinition{Name: name}
+			byName[name] = def
+		}
+		switch field {
+		case "type":
+			def.Type = value
+		case "label":
+			def.Label = value
+		case "values":
+			def.Values = splitList(value)
+		case "default":
+			def.Default = value
+		}
+	}
+
+	udas := make([]UDADefinition, 0, len(byName))
+	for _, def := range byName {
+		// Only a type makes a UDA; other uda.<name>.* keys are not enough
+		if def.Type != "" {
+			udas = append(udas, *def)
+		}
+	}
+	sort.Slice(udas, func(i, j int) bool { return udas[i].Name < udas[j].Name })
+	return udas
+}
+
+// Reports returns the report definitions sorted by name
+func (rc *Taskrc) Reports() []Report {
+	byName := make(map[string]*Report)
+	for key, value := range rc.Settings {
+		rest, ok := strings.CutPrefix(key, "report.")
+		if !ok {
+			continue
+		}
+		dot := strings.LastIndexByte(rest, '.')
+		if dot <= 0 {
+			continue
+		}
+		name, field := rest[:dot], rest[dot+1:]
+		report := byName[name]
+		if report == nil {
+			report = &Report{Name: name}
+			byName[name] = report
+		}
+		switch field {
+		case "description":
+			report.Description = value
+		case "filter":
+			report.Filter = value
+		case "sort":
+			report.Sort = value
+		case "columns":
+			report.Columns = splitList(value)
+		case "labels":
+			report.Labels = splitList(value)
+		}
+	}
+
+	reports := make([]Report, 0, len(byName))
+	for _, report := range byName {
+		reports = append(reports, *report)
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Name < reports[j].Name })
+	return reports
+}
+
+// UrgencyCoefficients returns the urgency.* settings over the defaults
+func (rc *Taskrc) UrgencyCoefficients() UrgencyCoefficients {
+	return ParseUrgencyCoefficients(rc.Settings)
+}
+
+// splitList splits a comma separated setting
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// fileExists reports whether path names an existing file
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
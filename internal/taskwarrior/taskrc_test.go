@@ -0,0 +1,181 @@
+package taskwarrior
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeFile writes content to dir/name and returns the path
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+const testTaskrc = `# Taskwarrior config
+data.location=~/tasks
+include themes/dark.theme
+context=work   # set by task context
+
+context.work.read=+work or project:Work
+context.work.write=+work
+context.home=+home
+
+uda.estimate.type=duration
+uda.estimate.label=Estimate
+uda.size.type=string
+uda.size.values=S,M,L,
+uda.size.default=M
+uda.orphan.label=No type
+
+report.next.columns=id,description
+report.next.filter=status:pending limit:page
+report.next.sort=urgency-
+report.my.report.labels=ID,Desc
+
+urgency.due.coefficient=6.0
+color.due=red
+`
+
+func TestParseTaskrc(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "themes/dark.theme", "color.due=yellow\ncolor.active=green\n")
+	path := writeFile(t, dir, "taskrc", testTaskrc)
+
+	rc, err := ParseTaskrc(path)
+	if err != nil {
+		t.Fatalf("ParseTaskrc failed: %v", err)
+	}
+
+	if len(rc.Files) != 2 || rc.Files[1] != filepath.Join(dir, "themes", "dark.theme") {
+		t.Errorf("Unexpected files: %v", rc.Files)
+	}
+	if v, _ := rc.Get("color.due"); v != "red" {
+		t.Errorf("Later setting should win, got color.due=%q", v)
+	}
+	if v, ok := rc.Get("color.active"); !ok || v != "green" {
+		t.Errorf("Included setting missing, got %q", v)
+	}
+	if v, _ := rc.Get("context"); v != "work" {
+		t.Errorf("Trailing comment not stripped, got context=%q", v)
+	}
+
+	if got := rc.CurrentContext(); got != "work" {
+		t.Errorf("CurrentContext() = %q", got)
+	}
+	wantContexts := []ContextDefinition{
+		{Name: "home", Read: "+home"},
+		{Name: "work", Read: "+work or project:Work", Write: "+work"},
+	}
+	if got := rc.Contexts(); !slices.Equal(got, wantContexts) {
+		t.Errorf("Contexts() = %+v, want %+v", got, wantContexts)
+	}
+
+	udas := rc.UDAs()
+	if len(udas) != 2 || udas[0].Name != "estimate" || udas[0].Label != "Estimate" || udas[0].Type != "duration" {
+		t.Fatalf("Unexpected UDAs: %+v", udas)
+	}
+	if size := udas[1]; size.Name != "size" || !slices.Equal(size.Values, []string{"S", "M", "L"}) || size.Default != "M" {
+		t.Errorf("Unexpected size UDA: %+v", size)
+	}
+
+	reports := rc.Reports()
+	if len(reports) != 2 || reports[0].Name != "my.report" || !slices.Equal(reports[0].Labels, []string{"ID", "Desc"}) {
+		t.Fatalf("Unexpected reports: %+v", reports)
+	}
+	if next := reports[1]; next.Filter != "status:pending limit:page" || next.Sort != "urgency-" || !slices.Equal(next.Columns, []string{"id", "description"}) {
+		t.Errorf("Unexpected next report: %+v", next)
+	}
+
+	if c := rc.UrgencyCoefficients(); c.Due != 6.0 || c.Blocking != 8.0 {
+		t.Errorf("Unexpected urgency coefficients: %+v", c)
+	}
+}
+
+func TestParseTaskrcErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"missing include", "include nowhere.rc\n", "nowhere.rc"},
+		{"include loop", "include loop.rc\n", "include loop"},
+		{"malformed", "color.due=red\njust some words\n", "loop.rc:2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, dir, "loop.rc", tt.content)
+			_, err := ParseTaskrc(path)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseTaskrc error = %v, want mention of %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadTaskrc(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("TASKRC", "")
+	t.Setenv("TASKDATA", "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	if _, err := LoadTaskrc(); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected not-exist error without a taskrc, got %v", err)
+	}
+
+	// The XDG location is used when ~/.taskrc is missing
+	xdg := writeFile(t, home, ".config/task/taskrc", "data.location=~/xdg-data\n")
+	rc, err := LoadTaskrc()
+	if err != nil {
+		t.Fatalf("LoadTaskrc failed: %v", err)
+	}
+	if rc.Path != xdg || rc.DataLocation() != filepath.Join(home, "xdg-data") {
+		t.Errorf("Expected XDG taskrc, got path=%s data=%s", rc.Path, rc.DataLocation())
+	}
+
+	// TASKRC and TASKDATA override the defaults
+	custom := writeFile(t, home, "custom.rc", "context=home\n")
+	t.Setenv("TASKRC", custom)
+	t.Setenv("TASKDATA", "/srv/tasks")
+	rc, err = LoadTaskrc()
+	if err != nil {
+		t.Fatalf("LoadTaskrc failed: %v", err)
+	}
+	if rc.Path != custom || rc.DataLocation() != "/srv/tasks" || rc.CurrentContext() != "home" {
+		t.Errorf("Overrides not applied: path=%s data=%s context=%s", rc.Path, rc.DataLocation(), rc.CurrentContext())
+	}
+}
+
+func TestContextsFromTaskrc(t *testing.T) {
+	installFakeTask(t, "echo 'task should not run' >&2; exit 1")
+	dir := t.TempDir()
+	writeFile(t, dir, "themes/dark.theme", "")
+	t.Setenv("TASKRC", writeFile(t, dir, "taskrc", testTaskrc))
+
+	contexts, err := GetContexts(t.Context())
+	if err != nil {
+		t.Fatalf("GetContexts failed: %v", err)
+	}
+	if !slices.Equal(contexts, []string{"home", "work", "none"}) {
+		t.Errorf("GetContexts() = %v", contexts)
+	}
+
+	current, err := GetCurrentContext(t.Context())
+	if err != nil || current != "work" {
+		t.Errorf("GetCurrentContext() = %q, %v", current, err)
+	}
+}
@@ -26,13 +26,13 @@ func BenchmarkParseTask(b *testing.B) {
 func BenchmarkFormatDue(b *testing.B) {
 	testCases := []struct {
 		name string
-		due  string
+		due  Date
 	}{
-		{"Empty", ""},
-		{"Today", time.Now().Format("20060102T150405Z")},
-		{"Tomorrow", time.Now().AddDate(0, 0, 1).Format("20060102T150405Z")},
-		{"NextWeek", time.Now().AddDate(0, 0, 7).Format("20060102T150405Z")},
-		{"PastDue", time.Now().AddDate(0, 0, -3).Format("20060102T150405Z")},
+		{"Empty", Date{}},
+		{"Today", NewDate(time.Now())},
+		{"Tomorrow", NewDate(time.Now().AddDate(0, 0, 1))},
+		{"NextWeek", NewDate(time.Now().AddDate(0, 0, 7))},
+		{"PastDue", NewDate(time.Now().AddDate(0, 0, -3))},
 	}
 	
 	for _, tc := range testCases {
@@ -129,12 +129,12 @@ func BenchmarkIsOverdue(b *testing.B) {
 	now := time.Now()
 	testCases := []struct {
 		name string
-		due  string
+		due  Date
 	}{
-		{"NoDue", ""},
-		{"FutureDue", now.AddDate(0, 0, 7).Format("20060102T150405Z")},
-		{"TodayDue", now.Format("20060102T150405Z")},
-		{"PastDue", now.AddDate(0, 0, -3).Format("20060102T150405Z")},
+		{"NoDue", Date{}},
+		{"FutureDue", NewDate(now.AddDate(0, 0, 7))},
+		{"TodayDue", NewDate(now)},
+		{"PastDue", NewDate(now.AddDate(0, 0, -3))},
 	}
 	
 	for _, tc := range testCases {
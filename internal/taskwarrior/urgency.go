@@ -0,0 +1,269 @@
+package taskwarrior
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emiller/tasksh/internal/taskwarrior/filter"
+)
+
+// UrgencyCoefficients holds the urgency.* weights from the Taskwarrior config
+type UrgencyCoefficients struct {
+	Project     float64
+	Active      float64
+	Scheduled   float64
+	Waiting     float64
+	Blocked     float64
+	Annotations float64
+	Tags        float64
+	Due         float64
+	Blocking    float64
+	Age         float64
+	AgeMax      float64
+
+	// Tag, Project and Keyword hold urgency.user.<kind>.<name>.coefficient
+	// keyed by name. UDA holds urgency.uda.<name>.coefficient keyed by name
+	// and urgency.uda.<name>.<value>.coefficient keyed by "name.value".
+	UserTag     map[string]float64
+	UserProject map[string]float64
+	UserKeyword map[string]float64
+	UDA         map[string]float64
+}
+
+// DefaultUrgencyCoefficients returns Taskwarrior's built-in coefficients
+func DefaultUrgencyCoefficients() UrgencyCoefficients {
+	return UrgencyCoefficients{
+		Project:     1.0,
+		Active:      4.0,
+		Scheduled:   5.0,
+		Waiting:     -3.0,
+		Blocked:     -5.0,
+		Annotations: 1.0,
+		Tags:        1.0,
+		Due:         12.0,
+		Blocking:    8.0,
+		Age:         2.0,
+		AgeMax:      365,
+		UserTag:     map[string]float64{"next": 15.0},
+		UserProject: map[string]float64{},
+		UserKeyword: map[string]float64{},
+		UDA: map[string]float64{
+			"priority.H": 6.0,
+			"priority.M": 3.9,
+			"priority.L": 1.8,
+		},
+	}
+}
+
+// ParseUrgencyCoefficients applies urgency.* settings over the defaults.
+// Settings that are not numbers are ignored, as Taskwarrior does.
+func ParseUrgencyCoefficients(settings map[string]string) UrgencyCoefficients {
+	c := DefaultUrgencyCoefficients()
+	scalars := map[string]*float64{
+		"project":     &c.Project,
+		"active":      &c.Active,
+		"scheduled":   &c.Scheduled,
+		"waiting":     &c.Waiting,
+		"blocked":     &c.Blocked,
+		"annotations": &c.Annotations,
+		"tags":        &c.Tags,
+		"due":         &c.Due,
+		"blocking":    &c.Blocking,
+		"age":         &c.Age,
+	}
+
+	for key, raw := range settings {
+		name, ok := strings.CutPrefix(key, "urgency.")
+		if !ok {
+			continue
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			continue
+		}
+		if name == "age.max" {
+			c.AgeMax = value
+			continue
+		}
+		name, ok = strings.CutSuffix(name, ".coefficient")
+		if !ok {
+			continue
+		}
+
+		switch {
+		case scalars[name] != nil:
+			*scalars[name] = value
+		case strings.HasPrefix(name, "user.tag."):
+			c.UserTag[strings.TrimPrefix(name, "user.tag.")] = value
+		case strings.HasPrefix(name, "user.project."):
+			c.UserProject[strings.TrimPrefix(name, "user.project.")] = value
+		case strings.HasPrefix(name, "user.keyword."):
+			c.UserKeyword[strings.TrimPrefix(name, "user.keyword.")] = value
+		case strings.HasPrefix(name, "uda."):
+			c.UDA[strings.TrimPrefix(name, "uda.")] = value
+		}
+	}
+	return c
+}
+
+// GetUrgencyCoefficients reads the urgency coefficients from the user's config
+func GetUrgencyCoefficients(ctx context.Context) (UrgencyCoefficients, error) {
+	if rc, err := loadTaskrc(ctx); err == nil {
+		return rc.UrgencyCoefficients(), nil
+	}
+
+	output, err := executeTask(ctx, "rc.verbose:nothing", "_show")
+	if err != nil {
+		return DefaultUrgencyCoefficients(), fmt.Errorf("failed to read urgency coefficients: %w", err)
+	}
+
+	settings := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		if key, value, ok := strings.Cut(line, "="); ok && strings.HasPrefix(key, "urgency.") {
+			settings[key] = value
+		}
+	}
+	return ParseUrgencyCoefficients(settings), nil
+}
+
+// urgencyEpsilon matches the threshold below which Taskwarrior skips a term
+const urgencyEpsilon = 0.000001
+
+// Urgency computes a task's urgency the way Taskwarrior does. blocked and
+// blocking describe the task's dependencies, which need the rest of the
+// task list to work out; see ComputeUrgency.
+func (c UrgencyCoefficients) Urgency(td *TaskData, blocked, blocking bool, now time.Time) float64 {
+	terms := []struct {
+		coefficient float64
+		factor      func() float64
+	}{
+		{c.Project, func() float64 { return flag(td.Project != "") }},
+		{c.Active, func() float64 { return flag(!td.Start.IsZero()) }},
+		{c.Scheduled, func() float64 {
+			return flag(!td.Scheduled.IsZero() && td.Scheduled.Before(now))
+		}},
+		{c.Waiting, func() float64 { return flag(filter.IsWaiting(td, now)) }},
+		{c.Blocked, func() float64 { return flag(blocked) }},
+		{c.Annotations, func() float64 { return countFactor(len(td.Annotations)) }},
+		{c.Tags, func() float64 { return countFactor(len(td.Tags)) }},
+		{c.Due, func() float64 { return dueFactor(td.Due, now) }},
+		{c.Blocking, func() float64 { return flag(blocking) }},
+		{c.Age, func() float64 { return ageFactor(td.Entry, c.AgeMax, now) }},
+	}
+
+	urgency := 0.0
+	for _, term := range terms {
+		if math.Abs(term.coefficient) > urgencyEpsilon {
+			urgency += term.factor() * term.coefficient
+		}
+	}
+
+	for tag, coefficient := range c.UserTag {
+		if match, err := filter.Match(filter.HasTag(tag), td, now); err == nil && match {
+			urgency += coefficient
+		}
+	}
+	for project, coefficient := range c.UserProject {
+		if td.Project == project || strings.HasPrefix(td.Project, project+".") {
+			urgency += coefficient
+		}
+	}
+	for keyword, coefficient := range c.UserKeyword {
+		if strings.Contains(td.Description, keyword) {
+			urgency += coefficient
+		}
+	}
+	for key, coefficient := range c.UDA {
+		if name, value, ok := strings.Cut(key, "."); ok {
+			if td.Attr(name) == value {
+				urgency += coefficient
+			}
+		} else if td.Attr(key) != "" {
+			urgency += coefficient
+		}
+	}
+
+	return urgency
+}
+
+// ComputeUrgency sets Urgency on each task, working out blocked and
+// blocking from the dependencies between the given tasks
+func ComputeUrgency(tasks []*TaskData, c UrgencyCoefficients, now time.Time) {
+	open := make(map[string]bool, len(tasks))
+	for _, td := range tasks {
+		open[td.UUID] = td.Status == "pending" || td.Status == "waiting"
+	}
+
+	blocking := make(map[string]bool)
+	blocked := make(map[string]bool)
+	for _, td := range tasks {
+		if !open[td.UUID] {
+			continue
+		}
+		for _, dep := range td.Depends {
+			if open[dep] {
+				blocked[td.UUID] = true
+				blocking[dep] = true
+			}
+		}
+	}
+
+	for _, td := range tasks {
+		td.Urgency = c.Urgency(td, blocked[td.UUID], blocking[td.UUID], now)
+	}
+}
+
+func flag(set bool) float64 {
+	if set {
+		return 1.0
+	}
+	return 0.0
+}
+
+// countFactor scales the tag and annotation terms
+func countFactor(n int) float64 {
+	switch n {
+	case 0:
+		return 0.0
+	case 1:
+		return 0.8
+	case 2:
+		return 0.9
+	default:
+		return 1.0
+	}
+}
+
+// dueFactor ramps from 0.2 two weeks before the due date to 1.0 a week after
+func dueFactor(due Date, now time.Time) float64 {
+	if due.IsZero() {
+		return 0.0
+	}
+
+	daysOverdue := now.Sub(due.Time).Seconds() / 86400.0
+	switch {
+	case daysOverdue >= 7.0:
+		return 1.0
+	case daysOverdue >= -14.0:
+		return ((daysOverdue + 14.0) * 0.8 / 21.0) + 0.2
+	default:
+		return 0.2
+	}
+}
+
+// ageFactor grows linearly with whole days since entry up to ageMax
+func ageFactor(entry Date, ageMax float64, now time.Time) float64 {
+	if entry.IsZero() {
+		return 1.0
+	}
+
+	age := float64(int(now.Sub(entry.Time).Hours() / 24))
+	if ageMax == 0 || age > ageMax {
+		return 1.0
+	}
+	return age / ageMax
+}
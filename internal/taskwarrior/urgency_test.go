@@ -0,0 +1,105 @@
+package taskwarrior
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+// urgencyNow is the time the urgency fixtures were exported
+var urgencyNow = time.Date(2024, 6, 12, 10, 0, 0, 0, time.UTC)
+
+// urgencyExport is in `task export` format; each urgency is the value
+// Taskwarrior's urgency calculation gives at urgencyNow with the default
+// coefficients, as printed by export
+const urgencyExport = `[
+{"uuid":"00000000-0000-4000-8000-00000000000a","description":"Bare","status":"pending","entry":"20240612T100000Z","modified":"20240612T100000Z","urgency":0},
+{"uuid":"00000000-0000-4000-8000-00000000000b","description":"Project and tags","project":"tasksh","status":"pending","tags":["a","b"],"entry":"20240602T100000Z","modified":"20240602T100000Z","urgency":1.95479},
+{"uuid":"00000000-0000-4000-8000-00000000000c","description":"High priority due soon","priority":"H","status":"pending","due":"20240615T100000Z","entry":"20240612T100000Z","modified":"20240612T100000Z","urgency":13.4286},
+{"uuid":"00000000-0000-4000-8000-00000000000d","description":"Next and active","status":"pending","tags":["next"],"start":"20240612T090000Z","annotations":[{"entry":"20240612T090000Z","description":"Started"}],"entry":"20230101T000000Z","modified":"20240612T090000Z","urgency":22.6},
+{"uuid":"00000000-0000-4000-8000-00000000000e","description":"Waiting and scheduled","status":"waiting","wait":"20240620T000000Z","scheduled":"20240610T000000Z","entry":"20240612T100000Z","modified":"20240612T100000Z","urgency":2},
+{"uuid":"00000000-0000-4000-8000-00000000000f","description":"Blocked","status":"pending","depends":["00000000-0000-4000-8000-000000000010"],"entry":"20240612T100000Z","modified":"20240612T100000Z","urgency":-5},
+{"uuid":"00000000-0000-4000-8000-000000000010","description":"Blocking","status":"pending","entry":"20240612T100000Z","modified":"20240612T100000Z","urgency":8},
+{"uuid":"00000000-0000-4000-8000-000000000011","description":"Overdue low priority","priority":"L","status":"pending","due":"20240601T000000Z","entry":"20240612T100000Z","modified":"20240612T100000Z","urgency":13.8},
+{"uuid":"00000000-0000-4000-8000-000000000012","description":"Due far away","status":"pending","due":"20250101T000000Z","entry":"20240612T100000Z","modified":"20240612T100000Z","urgency":2.4},
+{"uuid":"00000000-0000-4000-8000-000000000013","description":"Depends on done","status":"pending","depends":["00000000-0000-4000-8000-000000000014"],"entry":"20240612T100000Z","modified":"20240612T100000Z","urgency":0},
+{"uuid":"00000000-0000-4000-8000-000000000014","description":"Done","status":"completed","end":"20240612T090000Z","entry":"20240612T100000Z","modified":"20240612T100000Z","urgency":0}
+]`
+
+// urgencyTolerance allows for export printing urgency to six significant digits
+const urgencyTolerance = 0.0005
+
+func TestComputeUrgencyMatchesExport(t *testing.T) {
+	var tasks []*TaskData
+	if err := json.Unmarshal([]byte(urgencyExport), &tasks); err != nil {
+		t.Fatalf("Failed to parse fixture: %v", err)
+	}
+
+	want := make(map[string]float64, len(tasks))
+	for _, td := range tasks {
+		want[td.UUID] = td.Urgency
+		td.Urgency = 0
+	}
+
+	ComputeUrgency(tasks, DefaultUrgencyCoefficients(), urgencyNow)
+
+	for _, td := range tasks {
+		if math.Abs(td.Urgency-want[td.UUID]) > urgencyTolerance {
+			t.Errorf("%s: urgency = %.5f, want %.5f", td.Description, td.Urgency, want[td.UUID])
+		}
+	}
+}
+
+func TestParseUrgencyCoefficients(t *testing.T) {
+	c := ParseUrgencyCoefficients(map[string]string{
+		"urgency.due.coefficient":               "0",
+		"urgency.blocking.coefficient":          "lots",
+		"urgency.age.max":                       "0",
+		"urgency.annotations.coefficient":       "2",
+		"urgency.user.project.Home.coefficient": "3",
+		"urgency.user.keyword.call.coefficient": "1.5",
+		"urgency.user.tag.next.coefficient":     "0",
+		"urgency.user.tag.OVERDUE.coefficient":  "4",
+		"urgency.uda.estimate.coefficient":      "2.5",
+		"urgency.uda.priority.H.coefficient":    "10",
+		"report.next.filter":                    "status:pending",
+	})
+
+	if c.Due != 0 || c.Blocking != 8.0 || c.AgeMax != 0 || c.Annotations != 2 {
+		t.Errorf("Unexpected scalar coefficients: %+v", c)
+	}
+	if c.UDA["priority.H"] != 10 || c.UDA["priority.M"] != 3.9 || c.UDA["estimate"] != 2.5 {
+		t.Errorf("Unexpected UDA coefficients: %v", c.UDA)
+	}
+
+	td := &TaskData{
+		Description: "call plumber",
+		Project:     "Home.Garden",
+		Priority:    "H",
+		Status:      "pending",
+		Due:         NewDate(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)),
+		Tags:        []string{"next"},
+		Entry:       NewDate(urgencyNow),
+		UDA:         map[string]string{"estimate": "PT2H"},
+	}
+
+	// project 1 + tags 0.8 + age 2 (age.max 0) + Home 3 + call 1.5 +
+	// estimate 2.5 + priority H 10 + OVERDUE 4; due and next are disabled
+	got := c.Urgency(td, false, false, urgencyNow)
+	if math.Abs(got-24.8) > urgencyTolerance {
+		t.Errorf("Urgency() = %.5f, want 24.8", got)
+	}
+}
+
+func TestGetUrgencyCoefficients(t *testing.T) {
+	installFakeTask(t, `printf 'color=on\nurgency.due.coefficient=6.0\nurgency.user.tag.work.coefficient=2\n'`)
+
+	c, err := GetUrgencyCoefficients(t.Context())
+	if err != nil {
+		t.Fatalf("GetUrgencyCoefficients failed: %v", err)
+	}
+	if c.Due != 6.0 || c.UserTag["work"] != 2 || c.UserTag["next"] != 15.0 {
+		t.Errorf("Unexpected coefficients: %+v", c)
+	}
+}
@@ -0,0 +1,92 @@
+package taskwarrior
+
+import (
+	"strings"
+	"time"
+
+	"github.com/emiller/tasksh/internal/taskwarrior/filter"
+)
+
+// WaitTask records why a task waits in UDAs so the reason can be queried;
+// RemoveWaitDate clears them again
+const (
+	WaitReasonUDA = "waitreason"
+	WaitingOnUDA  = "waitingon"
+	WaitSinceUDA  = "waitsince"
+)
+
+// WaitUDAs are the wait UDAs EnsureReviewConfig sets up
+var WaitUDAs = []UDADefinition{
+	{Name: WaitReasonUDA, Type: "string", Label: "Wait reason"},
+	{Name: WaitingOnUDA, Type: "string", Label: "Waiting on"},
+	{Name: WaitSinceUDA, Type: "date", Label: "Waiting since"},
+}
+
+// WaitingFilter matches open tasks that were set waiting, including those
+// whose wait date has passed but that were never un-waited
+var WaitingFilter = filter.And(
+	filter.Or(filter.HasTag("PENDING"), filter.HasTag("WAITING")),
+	filter.Or(filter.HasTag("WAITING"), filter.HasTag("waiting"), filter.Attr(WaitReasonUDA).Any(), filter.Attr(WaitingOnUDA).Any()),
+)
+
+// WaitModifications returns the modify arguments that wait a task until
+// waitUntil, so several tasks can be waited in one call. The reason and
+// person are quoted so Taskwarrior reads each as one value.
+func WaitModifications(waitUntil, reason, waitingOn string, now time.Time) []string {
+	mods := []string{"wait:" + waitUntil, "+waiting", WaitSinceUDA + ":" + NewDate(now).String()}
+	if reason != "" {
+		mods = append(mods, filter.Attr(WaitReasonUDA).Eq(reason).String())
+	}
+	if waitingOn != "" {
+		mods = append(mods, filter.Attr(WaitingOnUDA).Eq(waitingOn).String())
+	}
+	return mods
+}
+
+// UnwaitModifications are the modify arguments that clear a wait and the
+// wait UDAs
+var UnwaitModifications = []string{"wait:", "-waiting", WaitReasonUDA + ":", WaitingOnUDA + ":", WaitSinceUDA + ":"}
+
+// legacyWaitReasonPrefix starts the annotation older versions of tasksh
+// used to record wait reasons
+const legacyWaitReasonPrefix = "Wait reason: "
+
+// WaitReason returns why the task waits, falling back to the annotation
+// older versions recorded
+func (td *TaskData) WaitReason() string {
+	if reason := td.UDA[WaitReasonUDA]; reason != "" {
+		return reason
+	}
+	for _, ann := range td.Annotations {
+		if reason, ok := strings.CutPrefix(ann.Description, legacyWaitReasonPrefix); ok {
+			return reason
+		}
+	}
+	return ""
+}
+
+// WaitingOn returns the person the task waits on, or ""
+func (td *TaskData) WaitingOn() string {
+	return td.UDA[WaitingOnUDA]
+}
+
+// WaitingSince returns when the task was set waiting, falling back to its
+// last modification for tasks waited before the waitsince UDA existed
+func (td *TaskData) WaitingSince() time.Time {
+	if since, err := ParseDate(td.UDA[WaitSinceUDA]); err == nil && !since.IsZero() {
+		return since.Time
+	}
+	return td.Modified.Time
+}
+
+// LegacyWaitAnnotations returns the wait reason annotations older versions
+// recorded, so RemoveWaitDate can clean them up
+func LegacyWaitAnnotations(annotations []Annotation) []string {
+	var texts []string
+	for _, ann := range annotations {
+		if strings.HasPrefix(ann.Description, legacyWaitReasonPrefix) {
+			texts = append(texts, ann.Description)
+		}
+	}
+	return texts
+}
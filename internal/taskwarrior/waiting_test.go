@@ -0,0 +1,30 @@
+package taskwarrior
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestWaitModifications(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	got := WaitModifications("friday", "blocked on review", "Ann's team", now)
+	want := []string{
+		"wait:friday",
+		"+waiting",
+		"waitsince:20240601T120000Z",
+		"waitreason:'blocked on review'",
+		`waitingon:"Ann's team"`,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("WaitModifications() = %q, want %q", got, want)
+	}
+
+	// Single words need no quoting, and empty values are left out
+	got = WaitModifications("friday", "parts", "", now)
+	want = []string{"wait:friday", "+waiting", "waitsince:20240601T120000Z", "waitreason:parts"}
+	if !slices.Equal(got, want) {
+		t.Errorf("WaitModifications() = %q, want %q", got, want)
+	}
+}
@@ -16,24 +16,30 @@ type TimeDB struct {
 
 // New creates or opens the time estimation database
 func New() (*TimeDB, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	return Open("")
+}
+
+// Open creates or opens the time estimation database at dbPath; an empty
+// path is the one New opens
+func Open(dbPath string) (*TimeDB, error) {
+	if dbPath == "" {
+		var err error
+		if dbPath, err = Path(); err != nil {
+			return nil, err
+		}
 	}
-	
-	dbDir := filepath.Join(homeDir, ".local", "share", "tasksh")
-	if err := os.MkdirAll(dbDir, 0755); err != nil {
+
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
 		return nil, fmt.Errorf("failed to create database directory: %w", err)
 	}
 	
-	dbPath := filepath.Join(dbDir, "timedb.sqlite3")
 	db, err := sql.Open("sqlite", dbPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 	
 	timeDB := &TimeDB{db: db}
-	if err := timeDB.initSchema(); err != nil {
+	if err := timeDB.migrate(); err != nil {
 		db.Close()
 		return nil, fmt.Errorf("failed to initialize schema: %w", err)
 	}
@@ -41,33 +47,31 @@ func New() (*TimeDB, error) {
 	return timeDB, nil
 }
 
+// Path returns the database file: $TASKSH_TIMEDB, set by the active
+// profile, or ~/.local/share/tasksh/timedb.sqlite3
+func Path() (string, error) {
+	return ResolvePath(os.Getenv("TASKSH_TIMEDB"))
+}
+
+// ResolvePath returns the database file for a TASKSH_TIMEDB setting, such
+// as a profile's, falling back to the default when it is empty
+func ResolvePath(setting string) (string, error) {
+	if setting != "" {
+		return setting, nil
+	}
+	return defaultPath()
+}
+
+// defaultPath returns ~/.local/share/tasksh/timedb.sqlite3
+func defaultPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".local", "share", "tasksh", "timedb.sqlite3"), nil
+}
+
 // Close closes the database connection
 func (tdb *TimeDB) Close() error {
 	return tdb.db.Close()
 }
-
-// initSchema creates the necessary tables
-func (tdb *TimeDB) initSchema() error {
-	schema := `
-	CREATE TABLE IF NOT EXISTS time_entries (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		uuid TEXT NOT NULL,
-		description TEXT NOT NULL,
-		project TEXT DEFAULT '',
-		tags TEXT DEFAULT '',
-		priority TEXT DEFAULT '',
-		estimated_hours REAL DEFAULT 0,
-		actual_hours REAL DEFAULT 0,
-		completed_at DATETIME NOT NULL,
-		created_at DATETIME NOT NULL,
-		UNIQUE(uuid)
-	);
-	
-	CREATE INDEX IF NOT EXISTS idx_project ON time_entries(project);
-	CREATE INDEX IF NOT EXISTS idx_priority ON time_entries(priority);
-	CREATE INDEX IF NOT EXISTS idx_completed_at ON time_entries(completed_at);
-	`
-	
-	_, err := tdb.db.Exec(schema)
-	return err
-}
\ No newline at end of file
@@ -0,0 +1,63 @@
+package timedb
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// bareMinutes matches a plain number of minutes, e.g. 20 or 1.5
+var bareMinutes = regexp.MustCompile(`^\d+(\.\d+)?$`)
+
+// hoursMinutes matches the short form of a duration, e.g. 1h30
+var hoursMinutes = regexp.MustCompile(`^(\d+)h(\d+)$`)
+
+// isoDuration matches the ISO 8601 durations Taskwarrior exports for
+// duration UDAs, e.g. PT1H30M, lowercased
+var isoDuration = regexp.MustCompile(`^p(?:(\d+)w)?(?:(\d+)d)?(?:t(?:(\d+(?:\.\d+)?)h)?(?:(\d+(?:\.\d+)?)m)?(?:(\d+(?:\.\d+)?)s)?)?$`)
+
+// ParseDuration parses time spent on a task, such as 45m, 1h30, 1h30m,
+// 1.5h or PT1H30M. A bare number is minutes.
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.ToLower(strings.ReplaceAll(s, " ", ""))
+	var d time.Duration
+	var err error
+	if bareMinutes.MatchString(s) {
+		minutes, _ := strconv.ParseFloat(s, 64)
+		if minutes > float64(math.MaxInt64/time.Minute) {
+			return 0, fmt.Errorf("invalid duration %q: too long", s)
+		}
+		d = time.Duration(minutes * float64(time.Minute))
+	} else if m := hoursMinutes.FindStringSubmatch(s); m != nil {
+		hours, _ := strconv.Atoi(m[1])
+		minutes, _ := strconv.Atoi(m[2])
+		d = time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute
+	} else if m := isoDuration.FindStringSubmatch(s); m != nil {
+		for i, unit := range []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second} {
+			n, _ := strconv.ParseFloat(m[i+1], 64)
+			d += time.Duration(n * float64(unit))
+		}
+	} else if d, err = time.ParseDuration(s); err != nil {
+		return 0, fmt.Errorf("invalid duration %q (try 45m or 1h30)", s)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid duration %q: must be positive", s)
+	}
+	return d, nil
+}
+
+// FormatDuration renders d to the minute in a form ParseDuration reads,
+// e.g. 45m or 1h30
+func FormatDuration(d time.Duration) string {
+	minutes := int(d.Round(time.Minute) / time.Minute)
+	switch {
+	case minutes < 60:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes%60 == 0:
+		return fmt.Sprintf("%dh", minutes/60)
+	}
+	return fmt.Sprintf("%dh%02d", minutes/60, minutes%60)
+}
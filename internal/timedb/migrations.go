@@ -0,0 +1,161 @@
+package timedb
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// migration moves the schema up one version
+type migration struct {
+	description string
+	statements  string
+	// table is the table the migration creates, for recognizing databases
+	// written before schema_version existed. Later migrations leave it empty.
+	table string
+}
+
+// migrations upgrade the schema in order: migrations[i] produces version
+// i+1. Append new migrations; never edit one that has been released.
+var migrations = []migration{
+	{
+		description: "time entries",
+		table:       "time_entries",
+		statements: `
+		CREATE TABLE time_entries (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			uuid TEXT NOT NULL,
+			description TEXT NOT NULL,
+			project TEXT DEFAULT '',
+			tags TEXT DEFAULT '',
+			priority TEXT DEFAULT '',
+			estimated_hours REAL DEFAULT 0,
+			actual_hours REAL DEFAULT 0,
+			completed_at DATETIME NOT NULL,
+			created_at DATETIME NOT NULL,
+			UNIQUE(uuid)
+		);
+
+		CREATE INDEX idx_project ON time_entries(project);
+		CREATE INDEX idx_priority ON time_entries(priority);
+		CREATE INDEX idx_completed_at ON time_entries(completed_at);
+		`,
+	},
+	{
+		description: "saved review sessions",
+		table:       "review_sessions",
+		statements: `
+		CREATE TABLE review_sessions (
+			queue TEXT PRIMARY KEY,
+			tasks TEXT NOT NULL,
+			current_index INTEGER NOT NULL,
+			reviewed INTEGER NOT NULL,
+			skipped TEXT NOT NULL,
+			actions TEXT NOT NULL,
+			elapsed_seconds REAL NOT NULL,
+			saved_at DATETIME NOT NULL
+		);
+		`,
+	},
+	{
+		description: "work sessions",
+		table:       "sessions",
+		statements: `
+		CREATE TABLE sessions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			uuid TEXT NOT NULL,
+			started_at DATETIME NOT NULL,
+			ended_at DATETIME NOT NULL
+		);
+
+		CREATE INDEX idx_sessions_uuid ON sessions(uuid);
+		`,
+	},
+}
+
+// migrate brings the schema up to the latest version in one transaction, so
+// a failed migration leaves the database as it was. A database written by a
+// newer tasksh is refused rather than guessed at.
+func (tdb *TimeDB) migrate() error {
+	tx, err := tdb.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to start migration: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+	CREATE TABLE IF NOT EXISTS schema_version (
+		version INTEGER PRIMARY KEY,
+		description TEXT NOT NULL,
+		applied_at DATETIME NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("failed to create schema_version: %w", err)
+	}
+
+	version, err := schemaVersion(tx)
+	if err != nil {
+		return err
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("database schema version %d is newer than this tasksh supports (version %d); upgrade tasksh to use it", version, len(migrations))
+	}
+	if version == 0 {
+		if version, err = legacyVersion(tx); err != nil {
+			return err
+		}
+		for v := 1; v <= version; v++ {
+			if err := recordVersion(tx, v); err != nil {
+				return err
+			}
+		}
+	}
+
+	for v := version + 1; v <= len(migrations); v++ {
+		if _, err := tx.Exec(migrations[v-1].statements); err != nil {
+			return fmt.Errorf("failed to migrate to version %d (%s): %w", v, migrations[v-1].description, err)
+		}
+		if err := recordVersion(tx, v); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration: %w", err)
+	}
+	return nil
+}
+
+// schemaVersion returns the latest version applied, or 0 for none
+func schemaVersion(tx *sql.Tx) (int, error) {
+	var version sql.NullInt64
+	if err := tx.QueryRow(`SELECT MAX(version) FROM schema_version`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return int(version.Int64), nil
+}
+
+// legacyVersion recognizes the version of a database written before
+// schema_version, from the tables it has. An empty database is version 0.
+func legacyVersion(tx *sql.Tx) (int, error) {
+	for i, m := range migrations {
+		if m.table == "" {
+			return i, nil
+		}
+		var count int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, m.table).Scan(&count); err != nil {
+			return 0, fmt.Errorf("failed to inspect schema: %w", err)
+		}
+		if count == 0 {
+			return i, nil
+		}
+	}
+	return len(migrations), nil
+}
+
+// recordVersion marks a migration as applied
+func recordVersion(tx *sql.Tx, version int) error {
+	query := `INSERT INTO schema_version (version, description, applied_at) VALUES (?, ?, ?)`
+	if _, err := tx.Exec(query, version, migrations[version-1].description, time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to record schema version %d: %w", version, err)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package timedb
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -30,22 +31,59 @@ func (tdb *TimeDB) RecordCompletion(task *taskwarrior.Task, estimatedHours, actu
 	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 	
+	// Prefer the task's own timestamps so imported history keeps its dates
 	now := time.Now()
+	completedAt, createdAt := now, now
+	if !task.End.IsZero() {
+		completedAt = task.End.Time
+	}
+	if !task.Entry.IsZero() {
+		createdAt = task.Entry.Time
+	}
 	_, err := tdb.db.Exec(query,
 		task.UUID,
 		task.Description,
 		task.Project,
-		"", // We'll need to get tags from task if needed
+		strings.Join(task.Tags, " "),
 		task.Priority,
 		estimatedHours,
 		actualHours,
-		now,
-		now,
+		completedAt,
+		createdAt,
 	)
 	
 	return err
 }
 
+// GetTimeEntry returns the completion recorded for a task, or nil if there
+// is none
+func (tdb *TimeDB) GetTimeEntry(uuid string) (*TimeEntry, error) {
+	query := `
+	SELECT uuid, description, project, tags, priority, estimated_hours, actual_hours, completed_at, created_at
+	FROM time_entries
+	WHERE uuid = ?
+	`
+	var entry TimeEntry
+	err := tdb.db.QueryRow(query, uuid).Scan(
+		&entry.UUID,
+		&entry.Description,
+		&entry.Project,
+		&entry.Tags,
+		&entry.Priority,
+		&entry.EstimatedHours,
+		&entry.ActualHours,
+		&entry.CompletedAt,
+		&entry.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load time entry: %w", err)
+	}
+	return &entry, nil
+}
+
 // GetSimilarTasks finds similar tasks based on description, project, and tags
 func (tdb *TimeDB) GetSimilarTasks(task *taskwarrior.Task, limit int) ([]TimeEntry, error) {
 	// Simple similarity based on project match and description keywords
@@ -156,6 +194,30 @@ func (tdb *TimeDB) GetEstimationAccuracy() (map[string]interface{}, error) {
 	return stats, nil
 }
 
+// PlanningEstimate returns the hours daily planning budgets for a task and
+// why: the historical estimate plus a 15% buffer, or a guess from its
+// priority when there is no history
+func (tdb *TimeDB) PlanningEstimate(task *taskwarrior.Task) (float64, string) {
+	estimate, reason, err := tdb.EstimateTimeForTask(task)
+	if err != nil || estimate <= 0 {
+		// Fallback estimates based on priority/complexity
+		switch task.Priority {
+		case "H":
+			return 3.0, "High priority task estimate"
+		case "M":
+			return 2.0, "Medium priority task estimate"
+		case "L":
+			return 1.0, "Low priority task estimate"
+		default:
+			return 2.0, "Default task estimate"
+		}
+	}
+
+	// Add 15% buffer to estimates
+	bufferedEstimate := estimate * 1.15
+	return bufferedEstimate, reason + " (with 15% buffer)"
+}
+
 // EstimateTimeForTask provides a time estimate based on historical data
 func (tdb *TimeDB) EstimateTimeForTask(task *taskwarrior.Task) (float64, string, error) {
 	// Try to find similar tasks
@@ -0,0 +1,112 @@
+package timedb
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/emiller/tasksh/internal/taskwarrior"
+)
+
+// ReviewSession is the saved state of a review quit before its end, so it
+// can be resumed
+type ReviewSession struct {
+	Queue    string          // Label of the review queue
+	Tasks    []SessionTask   // The review queue in order
+	Current  int             // Index of the task to resume at
+	Reviewed int             // Tasks reviewed so far
+	Skipped  []string        // UUIDs of skipped tasks
+	Actions  []SessionAction // Actions taken, oldest first
+	Elapsed  time.Duration   // Time spent reviewing
+	SavedAt  time.Time
+}
+
+// SessionTask is a task in a saved review and when it was last modified,
+// to notice changes made outside tasksh before the review resumes
+type SessionTask struct {
+	UUID     string    `json:"uuid"`
+	Modified time.Time `json:"modified,omitzero"`
+}
+
+// SessionAction is an action taken during a review
+type SessionAction struct {
+	Action  string          `json:"action"`
+	UUIDs   []string        `json:"uuids"`
+	At      time.Time       `json:"at"`
+	Changes []SessionChange `json:"changes,omitempty"`
+}
+
+// SessionChange is a task's data before and after a review action
+type SessionChange struct {
+	Before *taskwarrior.TaskData `json:"before"`
+	After  *taskwarrior.TaskData `json:"after"`
+}
+
+// SaveReviewSession stores a review session, replacing any saved earlier for
+// the same queue
+func (tdb *TimeDB) SaveReviewSession(s ReviewSession) error {
+	tasks, err := json.Marshal(s.Tasks)
+	if err != nil {
+		return fmt.Errorf("failed to encode review session: %w", err)
+	}
+	skipped, err := json.Marshal(s.Skipped)
+	if err != nil {
+		return fmt.Errorf("failed to encode review session: %w", err)
+	}
+	actions, err := json.Marshal(s.Actions)
+	if err != nil {
+		return fmt.Errorf("failed to encode review session: %w", err)
+	}
+
+	query := `
+	INSERT OR REPLACE INTO review_sessions
+	(queue, tasks, current_index, reviewed, skipped, actions, elapsed_seconds, saved_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	if _, err := tdb.db.Exec(query, s.Queue, string(tasks), s.Current, s.Reviewed, string(skipped), string(actions), s.Elapsed.Seconds(), s.SavedAt); err != nil {
+		return fmt.Errorf("failed to save review session: %w", err)
+	}
+	return nil
+}
+
+// LoadReviewSession returns the saved review session for a queue, or nil
+// if there is none
+func (tdb *TimeDB) LoadReviewSession(queue string) (*ReviewSession, error) {
+	query := `
+	SELECT tasks, current_index, reviewed, skipped, actions, elapsed_seconds, saved_at
+	FROM review_sessions
+	WHERE queue = ?
+	`
+	s := &ReviewSession{Queue: queue}
+	var tasks, skipped, actions string
+	var elapsed float64
+	err := tdb.db.QueryRow(query, queue).Scan(&tasks, &s.Current, &s.Reviewed, &skipped, &actions, &elapsed, &s.SavedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load review session: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(tasks), &s.Tasks); err != nil {
+		return nil, fmt.Errorf("failed to decode review session: %w", err)
+	}
+	if err := json.Unmarshal([]byte(skipped), &s.Skipped); err != nil {
+		return nil, fmt.Errorf("failed to decode review session: %w", err)
+	}
+	if err := json.Unmarshal([]byte(actions), &s.Actions); err != nil {
+		return nil, fmt.Errorf("failed to decode review session: %w", err)
+	}
+	s.Elapsed = time.Duration(elapsed * float64(time.Second))
+	return s, nil
+}
+
+// DeleteReviewSession removes the saved review session for a queue
+func (tdb *TimeDB) DeleteReviewSession(queue string) error {
+	if _, err := tdb.db.Exec(`DELETE FROM review_sessions WHERE queue = ?`, queue); err != nil {
+		return fmt.Errorf("failed to delete review session: %w", err)
+	}
+	return nil
+}
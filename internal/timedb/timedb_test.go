@@ -1,10 +1,13 @@
 package timedb
 
 import (
+	"database/sql"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/emiller/tasksh/internal/taskwarrior"
 )
@@ -28,6 +31,36 @@ func TestNewTimeDB(t *testing.T) {
 	}
 }
 
+func TestNewTimeDBFromEnv(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "profiles", "work.sqlite3")
+	t.Setenv("TASKSH_TIMEDB", dbPath)
+
+	db, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create TimeDB: %v", err)
+	}
+	defer db.Close()
+
+	if _, err := os.Stat(dbPath); err != nil {
+		t.Errorf("Database not created at TASKSH_TIMEDB: %v", err)
+	}
+}
+
+func TestResolvePath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	// The process environment may belong to another profile
+	t.Setenv("TASKSH_TIMEDB", filepath.Join(home, "other.sqlite3"))
+
+	if path, err := ResolvePath("/data/work.sqlite3"); err != nil || path != "/data/work.sqlite3" {
+		t.Errorf("ResolvePath(setting) = %q, %v", path, err)
+	}
+	want := filepath.Join(home, ".local", "share", "tasksh", "timedb.sqlite3")
+	if path, err := ResolvePath(""); err != nil || path != want {
+		t.Errorf("ResolvePath(\"\") = %q, %v, want %q", path, err, want)
+	}
+}
+
 func TestRecordCompletion(t *testing.T) {
 	tmpDir := t.TempDir()
 	os.Setenv("HOME", tmpDir)
@@ -58,6 +91,20 @@ func TestRecordCompletion(t *testing.T) {
 	if err != nil {
 		t.Errorf("Failed to update completion record: %v", err)
 	}
+
+	// The task's own entry and end dates are kept when set
+	task.Entry = taskwarrior.NewDate(time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC))
+	task.End = taskwarrior.NewDate(time.Date(2024, 6, 3, 17, 0, 0, 0, time.UTC))
+	if err := db.RecordCompletion(task, 2.5, 4.0); err != nil {
+		t.Fatalf("Failed to record completion: %v", err)
+	}
+	similar, err := db.GetSimilarTasks(task, 1)
+	if err != nil || len(similar) != 1 {
+		t.Fatalf("GetSimilarTasks = %v, %v", similar, err)
+	}
+	if !similar[0].CompletedAt.Equal(task.End.Time) || !similar[0].CreatedAt.Equal(task.Entry.Time) {
+		t.Errorf("Expected task dates, got completed=%v created=%v", similar[0].CompletedAt, similar[0].CreatedAt)
+	}
 }
 
 func TestGetSimilarTasks(t *testing.T) {
@@ -247,4 +294,300 @@ func TestEstimateTimeForTask(t *testing.T) {
 	}
 }
 
-// parseTimeInput function was removed as it's not used in the refactored code
\ No newline at end of file
+// parseTimeInput function was removed as it's not used in the refactored code
+func TestReviewSession(t *testing.T) {
+	t.Setenv("TASKSH_TIMEDB", filepath.Join(t.TempDir(), "timedb.sqlite3"))
+	db, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create TimeDB: %v", err)
+	}
+	defer db.Close()
+
+	if s, err := db.LoadReviewSession("default"); err != nil || s != nil {
+		t.Fatalf("Expected no saved session, got %+v (%v)", s, err)
+	}
+
+	saved := ReviewSession{
+		Queue: "default",
+		Tasks: []SessionTask{
+			{UUID: "a"},
+			{UUID: "b", Modified: time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC)},
+		},
+		Current:  1,
+		Reviewed: 1,
+		Skipped:  []string{"c"},
+		Actions:  []SessionAction{{Action: "Review", UUIDs: []string{"a"}, At: time.Date(2024, 6, 12, 9, 30, 0, 0, time.UTC)}},
+		Elapsed:  12*time.Minute + 30*time.Second,
+		SavedAt:  time.Date(2024, 6, 12, 9, 45, 0, 0, time.UTC),
+	}
+	if err := db.SaveReviewSession(saved); err != nil {
+		t.Fatalf("SaveReviewSession failed: %v", err)
+	}
+	got, err := db.LoadReviewSession("default")
+	if err != nil || got == nil {
+		t.Fatalf("LoadReviewSession failed: %v", err)
+	}
+	// Times may come back in another location, so compare them with Equal
+	if got.Current != 1 || got.Reviewed != 1 || got.Elapsed != saved.Elapsed || !got.SavedAt.Equal(saved.SavedAt) ||
+		len(got.Tasks) != 2 || got.Tasks[0].UUID != "a" || !got.Tasks[0].Modified.IsZero() || !got.Tasks[1].Modified.Equal(saved.Tasks[1].Modified) ||
+		strings.Join(got.Skipped, ",") != "c" || len(got.Actions) != 1 || got.Actions[0].Action != "Review" || !got.Actions[0].At.Equal(saved.Actions[0].At) {
+		t.Errorf("LoadReviewSession() = %+v, want %+v", *got, saved)
+	}
+	if other, _ := db.LoadReviewSession("bugs"); other != nil {
+		t.Errorf("Sessions should be kept per queue, got %+v", other)
+	}
+
+	if err := db.DeleteReviewSession("default"); err != nil {
+		t.Fatalf("DeleteReviewSession failed: %v", err)
+	}
+	if s, _ := db.LoadReviewSession("default"); s != nil {
+		t.Errorf("Expected the session deleted, got %+v", s)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		input string
+		want  time.Duration
+	}{
+		{"45m", 45 * time.Minute},
+		{"1h30", 90 * time.Minute},
+		{"1h30m", 90 * time.Minute},
+		{"1.5h", 90 * time.Minute},
+		{"2H", 2 * time.Hour},
+		{"20", 20 * time.Minute},
+		{"PT1H30M", 90 * time.Minute},
+		{"PT45M", 45 * time.Minute},
+		{"P1DT2H", 26 * time.Hour},
+	}
+	for _, tt := range tests {
+		got, err := ParseDuration(tt.input)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseDuration(%q) = %v, %v; want %v", tt.input, got, err, tt.want)
+		}
+		if back, err := ParseDuration(FormatDuration(got)); err != nil || back != got {
+			t.Errorf("FormatDuration(%v) = %q does not parse back", got, FormatDuration(got))
+		}
+	}
+	for _, input := range []string{"", "soon", "0m", "-1h", "P", "PT", "NaN", "Inf", "1e300", "99999999999999999999"} {
+		if _, err := ParseDuration(input); err == nil {
+			t.Errorf("ParseDuration(%q) should fail", input)
+		}
+	}
+	if got := FormatDuration(95 * time.Minute); got != "1h35" {
+		t.Errorf("FormatDuration = %q, want 1h35", got)
+	}
+}
+
+func TestWorkSessions(t *testing.T) {
+	t.Setenv("TASKSH_TIMEDB", filepath.Join(t.TempDir(), "timedb.sqlite3"))
+	db, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create TimeDB: %v", err)
+	}
+	defer db.Close()
+
+	morning := time.Date(2024, 6, 12, 9, 0, 0, 0, time.UTC)
+	afternoon := time.Date(2024, 6, 12, 14, 0, 0, 0, time.UTC)
+	for _, s := range []WorkSession{
+		{UUID: "a", Start: afternoon, End: afternoon.Add(45 * time.Minute)},
+		{UUID: "a", Start: morning, End: morning.Add(time.Hour)},
+		{UUID: "b", Start: morning, End: morning.Add(time.Minute)},
+	} {
+		if err := db.RecordWorkSession(s.UUID, s.Start, s.End); err != nil {
+			t.Fatalf("RecordWorkSession failed: %v", err)
+		}
+	}
+	if err := db.RecordWorkSession("a", afternoon, morning); err == nil {
+		t.Error("Expected an interval ending before it starts to be refused")
+	}
+
+	sessions, err := db.WorkSessions("a")
+	if err != nil || len(sessions) != 2 || !sessions[0].Start.Equal(morning) {
+		t.Fatalf("WorkSessions() = %+v, %v; want two, oldest first", sessions, err)
+	}
+	if total, err := db.TrackedTime("a"); err != nil || total != time.Hour+45*time.Minute {
+		t.Errorf("TrackedTime() = %v, %v; want 1h45m", total, err)
+	}
+	if total, _ := db.TrackedTime("none"); total != 0 {
+		t.Errorf("Expected no time tracked, got %v", total)
+	}
+}
+
+// Schemas written by initSchema before schema_version existed, by version
+const (
+	legacySchemaV1 = `
+	CREATE TABLE IF NOT EXISTS time_entries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		uuid TEXT NOT NULL,
+		description TEXT NOT NULL,
+		project TEXT DEFAULT '',
+		tags TEXT DEFAULT '',
+		priority TEXT DEFAULT '',
+		estimated_hours REAL DEFAULT 0,
+		actual_hours REAL DEFAULT 0,
+		completed_at DATETIME NOT NULL,
+		created_at DATETIME NOT NULL,
+		UNIQUE(uuid)
+	);
+	CREATE INDEX IF NOT EXISTS idx_project ON time_entries(project);
+	CREATE INDEX IF NOT EXISTS idx_priority ON time_entries(priority);
+	CREATE INDEX IF NOT EXISTS idx_completed_at ON time_entries(completed_at);
+	`
+	legacySchemaV2 = legacySchemaV1 + `
+	CREATE TABLE IF NOT EXISTS review_sessions (
+		queue TEXT PRIMARY KEY,
+		tasks TEXT NOT NULL,
+		current_index INTEGER NOT NULL,
+		reviewed INTEGER NOT NULL,
+		skipped TEXT NOT NULL,
+		actions TEXT NOT NULL,
+		elapsed_seconds REAL NOT NULL,
+		saved_at DATETIME NOT NULL
+	);
+	`
+	legacySchemaV3 = legacySchemaV2 + `
+	CREATE TABLE IF NOT EXISTS sessions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		uuid TEXT NOT NULL,
+		started_at DATETIME NOT NULL,
+		ended_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_sessions_uuid ON sessions(uuid);
+	`
+)
+
+// writeLegacyDB creates a database as an older tasksh left it, with a
+// completion recorded
+func writeLegacyDB(t *testing.T, path, schema string) {
+	t.Helper()
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(schema); err != nil {
+		t.Fatalf("Failed to write legacy schema: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO time_entries (uuid, description, project, actual_hours, completed_at, created_at)
+		VALUES ('legacy-1', 'Old task', 'Home', 2.0, ?, ?)`, time.Now(), time.Now()); err != nil {
+		t.Fatalf("Failed to record legacy completion: %v", err)
+	}
+}
+
+func TestMigrations(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		schema string
+	}{
+		{"empty", ""},
+		{"v1", legacySchemaV1},
+		{"v2", legacySchemaV2},
+		{"v3", legacySchemaV3},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "timedb.sqlite3")
+			t.Setenv("TASKSH_TIMEDB", path)
+			if tt.schema != "" {
+				writeLegacyDB(t, path, tt.schema)
+			}
+
+			db, err := New()
+			if err != nil {
+				t.Fatalf("Failed to migrate: %v", err)
+			}
+			defer db.Close()
+
+			var version, applied int
+			if err := db.db.QueryRow(`SELECT MAX(version), COUNT(*) FROM schema_version`).Scan(&version, &applied); err != nil {
+				t.Fatalf("Failed to read schema version: %v", err)
+			}
+			if version != len(migrations) || applied != len(migrations) {
+				t.Errorf("Expected all %d versions recorded, got version %d with %d rows", len(migrations), version, applied)
+			}
+
+			// Existing data survives and every table is usable
+			if tt.schema != "" {
+				if entry, err := db.GetTimeEntry("legacy-1"); err != nil || entry == nil || entry.ActualHours != 2.0 {
+					t.Errorf("Expected the legacy completion kept, got %+v, %v", entry, err)
+				}
+			}
+			start := time.Date(2024, 6, 12, 9, 0, 0, 0, time.UTC)
+			if err := db.RecordWorkSession("task-1", start, start.Add(time.Hour)); err != nil {
+				t.Errorf("Failed to record work session: %v", err)
+			}
+			if err := db.SaveReviewSession(ReviewSession{Queue: "default", Tasks: []SessionTask{{UUID: "task-1"}}}); err != nil {
+				t.Errorf("Failed to save review session: %v", err)
+			}
+			db.Close()
+
+			// Opening again has nothing left to apply
+			db, err = New()
+			if err != nil {
+				t.Fatalf("Failed to reopen: %v", err)
+			}
+			defer db.Close()
+			if err := db.db.QueryRow(`SELECT COUNT(*) FROM schema_version`).Scan(&applied); err != nil || applied != len(migrations) {
+				t.Errorf("Expected %d versions after reopening, got %d, %v", len(migrations), applied, err)
+			}
+		})
+	}
+}
+
+func TestMigrationsRefuseDowngrade(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "timedb.sqlite3")
+	t.Setenv("TASKSH_TIMEDB", path)
+	db, err := New()
+	if err != nil {
+		t.Fatalf("Failed to create TimeDB: %v", err)
+	}
+	newer := len(migrations) + 1
+	if _, err := db.db.Exec(`INSERT INTO schema_version (version, description, applied_at) VALUES (?, 'from the future', ?)`, newer, time.Now()); err != nil {
+		t.Fatalf("Failed to record a newer version: %v", err)
+	}
+	db.Close()
+
+	_, err = New()
+	if err == nil {
+		t.Fatal("Expected a database from a newer tasksh to be refused")
+	}
+	if want := fmt.Sprintf("schema version %d is newer than this tasksh supports", newer); !strings.Contains(err.Error(), want) {
+		t.Errorf("Expected %q in the error, got %v", want, err)
+	}
+}
+
+func TestMigrationRollsBack(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "timedb.sqlite3")
+	t.Setenv("TASKSH_TIMEDB", path)
+	// A database with a sessions table but no review_sessions was never
+	// written by tasksh; migrating it fails part way
+	writeLegacyDB(t, path, legacySchemaV1+`CREATE TABLE sessions (id INTEGER PRIMARY KEY);`)
+	if _, err := New(); err == nil {
+		t.Fatal("Expected the migration to fail")
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+	var tables int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name IN ('schema_version', 'review_sessions')`).Scan(&tables); err != nil {
+		t.Fatalf("Failed to inspect schema: %v", err)
+	}
+	if tables != 0 {
+		t.Errorf("Expected the failed migration rolled back, found %d new tables", tables)
+	}
+}
+
+func TestFormatElapsed(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		0:                             "0:00",
+		75 * time.Second:              "1:15",
+		time.Hour + 2*time.Minute + 3: "1:02:00",
+	} {
+		if got := FormatElapsed(d); got != want {
+			t.Errorf("FormatElapsed(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package timedb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/emiller/tasksh/internal/taskwarrior"
+)
+
+// Timer is a task being timed, and since when
+type Timer struct {
+	UUID        string
+	Description string
+	Since       time.Time
+}
+
+// TimerChange is what ToggleTimer did
+type TimerChange struct {
+	Started *Timer // the task now being timed, if any
+	Stopped string // UUID of the task whose timer stopped, if any
+	Message string
+}
+
+// ToggleTimer stops a task when it is active, and otherwise starts it,
+// stopping the task timed before. Stopped intervals are recorded in db,
+// which may be nil to record nothing.
+func ToggleTimer(ctx context.Context, backend taskwarrior.TaskBackend, db *TimeDB, task *taskwarrior.Task, previous *Timer) (TimerChange, error) {
+	now := time.Now()
+	name := task.GetShortDescription(40)
+	if !task.Start.IsZero() {
+		spent, err := StopTimer(ctx, backend, db, task.UUID, task.Start.Time, now)
+		if err != nil {
+			return TimerChange{}, err
+		}
+		return TimerChange{Stopped: task.UUID, Message: fmt.Sprintf("Stopped %q after %s.", name, FormatDuration(spent))}, nil
+	}
+
+	change := TimerChange{Message: fmt.Sprintf("Started %q.", name)}
+	if previous != nil && previous.UUID != task.UUID {
+		spent, err := StopTimer(ctx, backend, db, previous.UUID, previous.Since, now)
+		if err != nil {
+			return TimerChange{}, err
+		}
+		change.Stopped = previous.UUID
+		change.Message = fmt.Sprintf("Stopped %q after %s; started %q.", previous.Description, FormatDuration(spent), name)
+	}
+	if err := backend.StartTask(ctx, task.UUID); err != nil {
+		return TimerChange{}, err
+	}
+	// Time from the start date Taskwarrior recorded
+	since := taskwarrior.NewDate(now).Time
+	if started, err := backend.GetTaskInfo(ctx, task.UUID); err == nil && !started.Start.IsZero() {
+		since = started.Start.Time
+	}
+	change.Started = &Timer{UUID: task.UUID, Description: name, Since: since}
+	return change, nil
+}
+
+// StopTimer stops an active task and records the interval since it started
+// in db, which may be nil to record nothing
+func StopTimer(ctx context.Context, backend taskwarrior.TaskBackend, db *TimeDB, uuid string, since, now time.Time) (time.Duration, error) {
+	if err := backend.StopTask(ctx, uuid); err != nil {
+		return 0, err
+	}
+	if db != nil {
+		if err := db.RecordWork(uuid, since, now); err != nil {
+			return 0, err
+		}
+	}
+	return now.Sub(since), nil
+}
+
+// RecordWork stores an interval of work on a task. Intervals under a
+// second, as from starting and stopping at once, are not worth keeping.
+func (tdb *TimeDB) RecordWork(uuid string, since, until time.Time) error {
+	if until.Sub(since) < time.Second {
+		return nil
+	}
+	return tdb.RecordWorkSession(uuid, since, until)
+}
+
+// FormatElapsed renders a running time as m:ss or h:mm:ss
+func FormatElapsed(d time.Duration) string {
+	d = max(d, 0).Truncate(time.Second)
+	h, m, s := int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
@@ -0,0 +1,70 @@
+package timedb
+
+import (
+	"fmt"
+	"time"
+)
+
+// WorkSession is an interval of work on a task, from starting it to
+// stopping or completing it
+type WorkSession struct {
+	ID    int64
+	UUID  string
+	Start time.Time
+	End   time.Time
+}
+
+// Duration returns the length of the interval
+func (s WorkSession) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// RecordWorkSession stores an interval of work on a task
+func (tdb *TimeDB) RecordWorkSession(uuid string, start, end time.Time) error {
+	if !end.After(start) {
+		return fmt.Errorf("invalid work session: ends at %s, before it starts at %s", end.Format(time.RFC3339), start.Format(time.RFC3339))
+	}
+	query := `INSERT INTO sessions (uuid, started_at, ended_at) VALUES (?, ?, ?)`
+	if _, err := tdb.db.Exec(query, uuid, start.UTC(), end.UTC()); err != nil {
+		return fmt.Errorf("failed to record work session: %w", err)
+	}
+	return nil
+}
+
+// WorkSessions returns the intervals worked on a task, oldest first
+func (tdb *TimeDB) WorkSessions(uuid string) ([]WorkSession, error) {
+	query := `
+	SELECT id, uuid, started_at, ended_at
+	FROM sessions
+	WHERE uuid = ?
+	ORDER BY started_at
+	`
+	rows, err := tdb.db.Query(query, uuid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load work sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []WorkSession
+	for rows.Next() {
+		var s WorkSession
+		if err := rows.Scan(&s.ID, &s.UUID, &s.Start, &s.End); err != nil {
+			return nil, fmt.Errorf("failed to load work sessions: %w", err)
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+// TrackedTime returns the total time worked on a task
+func (tdb *TimeDB) TrackedTime(uuid string) (time.Duration, error) {
+	sessions, err := tdb.WorkSessions(uuid)
+	if err != nil {
+		return 0, err
+	}
+	var total time.Duration
+	for _, s := range sessions {
+		total += s.Duration()
+	}
+	return total, nil
+}
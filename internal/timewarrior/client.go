@@ -0,0 +1,64 @@
+package timewarrior
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/emiller/tasksh/internal/taskwarrior"
+)
+
+// Run runs a timew command. Like task calls, it is bounded by TASKSH_TIMEOUT.
+func Run(ctx context.Context, args ...string) (string, error) {
+	if len(args) == 0 {
+		return "", fmt.Errorf("no timew command specified")
+	}
+	if timeout := taskwarrior.CommandTimeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "timew", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = time.Second
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		if msg := strings.TrimSpace(stderr.String() + stdout.String()); msg != "" {
+			return "", fmt.Errorf("timew %s failed: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("timew %s failed: %w", args[0], err)
+	}
+	return stdout.String(), nil
+}
+
+// Export runs `timew export` with optional range or tag arguments and
+// parses the intervals it prints
+func Export(ctx context.Context, args ...string) ([]Interval, error) {
+	output, err := Run(ctx, append([]string{"export"}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseExport(strings.NewReader(output))
+}
+
+// Load reads every tracked interval, from the data files when the
+// database directory has them and from `timew export` otherwise
+func Load(ctx context.Context) ([]Interval, error) {
+	if dir, err := DataDir(); err == nil {
+		if _, err := os.Stat(dir); err == nil {
+			if intervals, err := ReadData(dir); err == nil {
+				return intervals, nil
+			}
+		}
+	}
+	return Export(ctx)
+}
@@ -0,0 +1,233 @@
+package timewarrior
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/emiller/tasksh/internal/taskwarrior"
+)
+
+// Interval is a span of tracked time with its tags. An open interval, still
+// being tracked, has a zero End.
+type Interval struct {
+	ID         int              `json:"id,omitempty"`
+	Start      taskwarrior.Date `json:"start"`
+	End        taskwarrior.Date `json:"end,omitzero"`
+	Tags       []string         `json:"tags,omitempty"`
+	Annotation string           `json:"annotation,omitempty"`
+}
+
+// Open reports whether the interval is still being tracked
+func (i Interval) Open() bool {
+	return i.End.IsZero()
+}
+
+// Duration returns the length of a closed interval, or 0 for an open one
+func (i Interval) Duration() time.Duration {
+	if i.Open() {
+		return 0
+	}
+	return i.End.Sub(i.Start.Time)
+}
+
+// HasTag reports whether the interval carries tag
+func (i Interval) HasTag(tag string) bool {
+	return slices.Contains(i.Tags, tag)
+}
+
+// ParseExport decodes the JSON array printed by `timew export`
+func ParseExport(r io.Reader) ([]Interval, error) {
+	var intervals []Interval
+	if err := json.NewDecoder(r).Decode(&intervals); err != nil {
+		return nil, fmt.Errorf("failed to parse timew export: %w", err)
+	}
+	return intervals, nil
+}
+
+// ParseData reads the intervals in a Timewarrior data file, one per line:
+//
+//	inc 20240115T090000Z - 20240115T103000Z # tag "tag with spaces" # "annotation"
+//
+// The end is missing from an open interval; tags and annotation are optional.
+func ParseData(r io.Reader) ([]Interval, error) {
+	var intervals []Interval
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		interval, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		intervals = append(intervals, interval)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read data file: %w", err)
+	}
+	return intervals, nil
+}
+
+// token is a word of a data file line; quoted words are never separators
+type token struct {
+	text   string
+	quoted bool
+}
+
+// parseLine parses a single "inc" line of a data file
+func parseLine(line string) (Interval, error) {
+	tokens, err := tokenize(line)
+	if err != nil {
+		return Interval{}, err
+	}
+	if len(tokens) < 2 || tokens[0].text != "inc" {
+		return Interval{}, fmt.Errorf("invalid interval %q", line)
+	}
+
+	var interval Interval
+	if interval.Start, err = parseTime(tokens[1].text); err != nil {
+		return Interval{}, err
+	}
+	rest := tokens[2:]
+	if len(rest) > 0 && !rest[0].quoted && rest[0].text == "-" {
+		if len(rest) < 2 {
+			return Interval{}, fmt.Errorf("invalid interval %q: missing end", line)
+		}
+		if interval.End, err = parseTime(rest[1].text); err != nil {
+			return Interval{}, err
+		}
+		rest = rest[2:]
+	}
+	if len(rest) == 0 {
+		return interval, nil
+	}
+	if rest[0].quoted || rest[0].text != "#" {
+		return Interval{}, fmt.Errorf("invalid interval %q: unexpected %q", line, rest[0].text)
+	}
+
+	// Tags run up to a second "#", which starts the annotation
+	rest = rest[1:]
+	for i, tok := range rest {
+		if !tok.quoted && tok.text == "#" {
+			var words []string
+			for _, word := range rest[i+1:] {
+				words = append(words, word.text)
+			}
+			interval.Annotation = strings.Join(words, " ")
+			break
+		}
+		interval.Tags = append(interval.Tags, tok.text)
+	}
+	return interval, nil
+}
+
+// parseTime parses a data file timestamp
+func parseTime(value string) (taskwarrior.Date, error) {
+	t, err := time.Parse("20060102T150405Z", value)
+	if err != nil {
+		return taskwarrior.Date{}, fmt.Errorf("invalid timestamp %q", value)
+	}
+	return taskwarrior.NewDate(t), nil
+}
+
+// tokenize splits a line on spaces, keeping double-quoted words, with their
+// backslash escapes, together
+func tokenize(line string) ([]token, error) {
+	var tokens []token
+	var current strings.Builder
+	inWord, quoted, inQuotes := false, false, false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case inQuotes && c == '\\' && i+1 < len(line):
+			i++
+			current.WriteByte(line[i])
+		case c == '"':
+			inQuotes = !inQuotes
+			inWord, quoted = true, true
+		case !inQuotes && (c == ' ' || c == '\t'):
+			if inWord {
+				tokens = append(tokens, token{current.String(), quoted})
+				current.Reset()
+				inWord, quoted = false, false
+			}
+		default:
+			current.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quote in %q", line)
+	}
+	if inWord {
+		tokens = append(tokens, token{current.String(), quoted})
+	}
+	return tokens, nil
+}
+
+// DataDir returns the Timewarrior database directory: $TIMEWARRIORDB,
+// ~/.timewarrior when it exists, or $XDG_DATA_HOME/timewarrior
+func DataDir() (string, error) {
+	if dir := os.Getenv("TIMEWARRIORDB"); dir != "" {
+		return dir, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	legacy := filepath.Join(homeDir, ".timewarrior")
+	if _, err := os.Stat(legacy); err == nil {
+		return legacy, nil
+	}
+	if dataHome := os.Getenv("XDG_DATA_HOME"); dataHome != "" {
+		return filepath.Join(dataHome, "timewarrior"), nil
+	}
+	return filepath.Join(homeDir, ".local", "share", "timewarrior"), nil
+}
+
+// ReadData reads every monthly data file (data/YYYY-MM.data) in a Timewarrior
+// database directory, returning the intervals ordered by start
+func ReadData(dir string) ([]Interval, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "data", "*.data"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list data files: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no Timewarrior data files in %s", filepath.Join(dir, "data"))
+	}
+
+	var intervals []Interval
+	for _, file := range files {
+		parsed, err := readDataFile(file)
+		if err != nil {
+			return nil, err
+		}
+		intervals = append(intervals, parsed...)
+	}
+	slices.SortStableFunc(intervals, func(a, b Interval) int {
+		return a.Start.Compare(b.Start.Time)
+	})
+	return intervals, nil
+}
+
+// readDataFile parses one data file, naming it in errors
+func readDataFile(path string) ([]Interval, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open data file: %w", err)
+	}
+	defer f.Close()
+	intervals, err := ParseData(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return intervals, nil
+}
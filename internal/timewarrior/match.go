@@ -0,0 +1,128 @@
+package timewarrior
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/emiller/tasksh/internal/taskwarrior"
+	"github.com/emiller/tasksh/internal/timedb"
+)
+
+// Match assigns intervals to the tasks they were tracked for, keyed by task
+// UUID. A tag holding the task's UUID (bare or as uuid:<UUID>) matches first;
+// otherwise a tag equal to the description does, as the Taskwarrior
+// on-modify hook tags intervals. When several tasks share the description,
+// as recurring instances do, the interval goes to the one open when it
+// started. Intervals that match no task, or no task unambiguously, are left
+// out.
+func Match(tasks []*taskwarrior.Task, intervals []Interval) map[string][]Interval {
+	byUUID := make(map[string]*taskwarrior.Task, len(tasks))
+	byDescription := make(map[string][]*taskwarrior.Task)
+	for _, task := range tasks {
+		byUUID[task.UUID] = task
+		byDescription[task.Description] = append(byDescription[task.Description], task)
+	}
+
+	matched := make(map[string][]Interval)
+	for _, interval := range intervals {
+		if task := matchTask(interval, byUUID, byDescription); task != nil {
+			matched[task.UUID] = append(matched[task.UUID], interval)
+		}
+	}
+	return matched
+}
+
+// matchTask finds the task an interval was tracked for, or nil
+func matchTask(interval Interval, byUUID map[string]*taskwarrior.Task, byDescription map[string][]*taskwarrior.Task) *taskwarrior.Task {
+	for _, tag := range interval.Tags {
+		if task, ok := byUUID[strings.TrimPrefix(tag, "uuid:")]; ok {
+			return task
+		}
+	}
+
+	var candidates []*taskwarrior.Task
+	for _, tag := range interval.Tags {
+		candidates = append(candidates, byDescription[tag]...)
+	}
+	if len(candidates) == 1 {
+		return candidates[0]
+	}
+	var open []*taskwarrior.Task
+	for _, task := range candidates {
+		if openAt(task, interval.Start.Time) {
+			open = append(open, task)
+		}
+	}
+	if len(open) == 1 {
+		return open[0]
+	}
+	return nil
+}
+
+// openAt reports whether a task had been entered and not yet ended at t
+func openAt(task *taskwarrior.Task, t time.Time) bool {
+	if !task.Entry.IsZero() && t.Before(task.Entry.Time) {
+		return false
+	}
+	return task.End.IsZero() || !t.After(task.End.Time)
+}
+
+// BackfillResult counts what Backfill did
+type BackfillResult struct {
+	Recorded int // Completions added to timedb
+	Updated  int // Completions already in timedb given tracked actual hours
+	Skipped  int // Completed tasks with no tracked time
+}
+
+// Backfill records the closed intervals tracked for each completed task as
+// its actual hours in timedb. A task already in timedb keeps its estimate; a
+// new one is recorded without one, as nobody estimated it at the time.
+func Backfill(db *timedb.TimeDB, tasks []*taskwarrior.Task, intervals []Interval) (BackfillResult, error) {
+	return backfill(db, tasks, intervals, false)
+}
+
+// PreviewBackfill counts what Backfill would do without writing to timedb
+func PreviewBackfill(db *timedb.TimeDB, tasks []*taskwarrior.Task, intervals []Interval) (BackfillResult, error) {
+	return backfill(db, tasks, intervals, true)
+}
+
+// backfill records or, on a dry run, only counts the tracked completions
+func backfill(db *timedb.TimeDB, tasks []*taskwarrior.Task, intervals []Interval, dryRun bool) (BackfillResult, error) {
+	var result BackfillResult
+	matched := Match(tasks, intervals)
+	for _, task := range tasks {
+		if task.Status != "completed" {
+			continue
+		}
+		var tracked time.Duration
+		for _, interval := range matched[task.UUID] {
+			tracked += interval.Duration()
+		}
+		if tracked <= 0 {
+			result.Skipped++
+			continue
+		}
+
+		entry, err := db.GetTimeEntry(task.UUID)
+		if err != nil {
+			return result, err
+		}
+		if !dryRun {
+			// 0 keeps new records out of the estimation accuracy statistics
+			var estimate float64
+			if entry != nil {
+				estimate = entry.EstimatedHours
+			}
+			if err := db.RecordCompletion(task, estimate, tracked.Hours()); err != nil {
+				return result, fmt.Errorf("failed to record time for %q: %w", task.Description, err)
+			}
+		}
+		if entry != nil {
+			result.Updated++
+		} else {
+			result.Recorded++
+		}
+	}
+	return result, nil
+}
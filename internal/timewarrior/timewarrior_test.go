@@ -0,0 +1,180 @@
+package timewarrior
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emiller/tasksh/internal/taskwarrior"
+	"github.com/emiller/tasksh/internal/timedb"
+)
+
+const (
+	reportUUID = "6f1c2b7e-4a1d-4c3e-9b8a-0d2e5f7a1c01"
+	waterUUID  = "6f1c2b7e-4a1d-4c3e-9b8a-0d2e5f7a1c02"
+	water2UUID = "6f1c2b7e-4a1d-4c3e-9b8a-0d2e5f7a1c03"
+	reviewUUID = "6f1c2b7e-4a1d-4c3e-9b8a-0d2e5f7a1c04"
+	bankUUID   = "6f1c2b7e-4a1d-4c3e-9b8a-0d2e5f7a1c05"
+)
+
+func date(value string) taskwarrior.Date {
+	d, err := taskwarrior.ParseDate(value)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// fixtureTasks are the tasks the fixture intervals were tracked for. The two
+// "Water plants" tasks are instances of a weekly recurrence.
+func fixtureTasks() []*taskwarrior.Task {
+	return []*taskwarrior.Task{
+		{UUID: reportUUID, Description: "Write report", Project: "work", Status: "completed", Entry: date("20240110T080000Z"), End: date("20240116T160000Z")},
+		{UUID: waterUUID, Description: "Water plants", Status: "completed", Entry: date("20240114T000000Z"), End: date("20240118T120000Z")},
+		{UUID: water2UUID, Description: "Water plants", Status: "completed", Entry: date("20240118T120000Z"), End: date("20240202T080000Z")},
+		{UUID: reviewUUID, Description: "Review PR", Status: "pending", Entry: date("20240201T080000Z")},
+		{UUID: bankUUID, Description: "Call bank", Status: "completed", Entry: date("20240101T080000Z"), End: date("20240103T080000Z")},
+	}
+}
+
+func TestReadData(t *testing.T) {
+	intervals, err := ReadData("testdata")
+	if err != nil {
+		t.Fatalf("Failed to read data files: %v", err)
+	}
+	if len(intervals) != 6 {
+		t.Fatalf("Expected 6 intervals, got %d: %+v", len(intervals), intervals)
+	}
+
+	first := intervals[0]
+	if first.Duration() != 90*time.Minute || !first.HasTag("Write report") || !first.HasTag(reportUUID) {
+		t.Errorf("Unexpected first interval: %+v", first)
+	}
+	if !intervals[2].HasTag(`say "hi"`) {
+		t.Errorf("Expected the escaped quote kept in the tag, got %q", intervals[2].Tags)
+	}
+	if water := intervals[3]; !slices.Equal(water.Tags, []string{"Water plants"}) || water.Annotation != "first week" {
+		t.Errorf("Expected tags and annotation split, got %q and %q", water.Tags, water.Annotation)
+	}
+	if last := intervals[5]; !last.Open() || last.Duration() != 0 {
+		t.Errorf("Expected the last interval still open, got %+v", last)
+	}
+}
+
+func TestParseData(t *testing.T) {
+	intervals, err := ParseData(strings.NewReader("inc 20240301T090000Z - 20240301T100000Z\n"))
+	if err != nil || len(intervals) != 1 || intervals[0].Tags != nil {
+		t.Fatalf("Expected one untagged interval, got %+v, %v", intervals, err)
+	}
+
+	for _, line := range []string{
+		"exc 20240301T090000Z",
+		"inc 2024-03-01",
+		"inc 20240301T090000Z -",
+		"inc 20240301T090000Z tag",
+		`inc 20240301T090000Z # "unterminated`,
+	} {
+		if _, err := ParseData(strings.NewReader(line)); err == nil {
+			t.Errorf("Expected an error for %q", line)
+		}
+	}
+}
+
+func TestParseExport(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "export.json"))
+	if err != nil {
+		t.Fatalf("Failed to open fixture: %v", err)
+	}
+	defer f.Close()
+	intervals, err := ParseExport(f)
+	if err != nil {
+		t.Fatalf("Failed to parse export: %v", err)
+	}
+	if len(intervals) != 3 {
+		t.Fatalf("Expected 3 intervals, got %d", len(intervals))
+	}
+	if intervals[0].ID != 3 || intervals[0].Duration() != 90*time.Minute {
+		t.Errorf("Unexpected first interval: %+v", intervals[0])
+	}
+	if intervals[1].Annotation != "first week" || !intervals[2].Open() {
+		t.Errorf("Expected the annotation and the open interval, got %+v", intervals[1:])
+	}
+}
+
+func TestMatch(t *testing.T) {
+	intervals, err := ReadData("testdata")
+	if err != nil {
+		t.Fatalf("Failed to read data files: %v", err)
+	}
+	matched := Match(fixtureTasks(), intervals)
+
+	want := map[string]int{reportUUID: 2, waterUUID: 1, water2UUID: 1, reviewUUID: 1}
+	for uuid, count := range want {
+		if len(matched[uuid]) != count {
+			t.Errorf("Expected %d intervals for %s, got %+v", count, uuid, matched[uuid])
+		}
+	}
+	if len(matched) != len(want) {
+		t.Errorf("Expected the standup interval unmatched, got %v", matched)
+	}
+	if got := matched[waterUUID][0].Annotation; got != "first week" {
+		t.Errorf("Expected the first week's watering on the first instance, got %q", got)
+	}
+}
+
+func TestBackfill(t *testing.T) {
+	t.Setenv("TASKSH_TIMEDB", filepath.Join(t.TempDir(), "timedb.sqlite3"))
+	db, err := timedb.New()
+	if err != nil {
+		t.Fatalf("Failed to open timedb: %v", err)
+	}
+	defer db.Close()
+
+	tasks := fixtureTasks()
+	// The report was completed in review without saying how long it took
+	if err := db.RecordCompletion(tasks[0], 4.0, 0); err != nil {
+		t.Fatalf("Failed to record completion: %v", err)
+	}
+
+	intervals, err := ReadData("testdata")
+	if err != nil {
+		t.Fatalf("Failed to read data files: %v", err)
+	}
+	result, err := Backfill(db, tasks, intervals)
+	if err != nil {
+		t.Fatalf("Backfill failed: %v", err)
+	}
+	if result != (BackfillResult{Recorded: 2, Updated: 1, Skipped: 1}) {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	for uuid, hours := range map[string]float64{reportUUID: 2.5, waterUUID: 0.75, water2UUID: 0.25} {
+		entry, err := db.GetTimeEntry(uuid)
+		if err != nil || entry == nil {
+			t.Fatalf("Expected a time entry for %s, got %v", uuid, err)
+		}
+		if math.Abs(entry.ActualHours-hours) > 0.001 {
+			t.Errorf("Expected %.2fh for %s, got %.2fh", hours, entry.Description, entry.ActualHours)
+		}
+	}
+	if entry, _ := db.GetTimeEntry(reportUUID); entry.EstimatedHours != 4.0 {
+		t.Errorf("Expected the recorded estimate kept, got %.2f", entry.EstimatedHours)
+	}
+	for _, uuid := range []string{waterUUID, water2UUID} {
+		if entry, _ := db.GetTimeEntry(uuid); entry.EstimatedHours != 0 {
+			t.Errorf("Expected no estimate made up for %s, got %.2f", entry.Description, entry.EstimatedHours)
+		}
+	}
+	if entry, _ := db.GetTimeEntry(water2UUID); !entry.CompletedAt.Equal(tasks[2].End.Time) {
+		t.Errorf("Expected the task's own completion date, got %v", entry.CompletedAt)
+	}
+	for _, uuid := range []string{reviewUUID, bankUUID} {
+		if entry, _ := db.GetTimeEntry(uuid); entry != nil {
+			t.Errorf("Expected no time entry for %s", entry.Description)
+		}
+	}
+}
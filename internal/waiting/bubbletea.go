@@ -0,0 +1,295 @@
+package waiting
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/emiller/tasksh/internal/taskwarrior"
+)
+
+// KeyMap defines the key bindings for the waiting view
+type KeyMap struct {
+	Up      key.Binding
+	Down    key.Binding
+	Nudge   key.Binding
+	Unwait  key.Binding
+	Refresh key.Binding
+	Quit    key.Binding
+}
+
+// DefaultKeyMap returns the default key bindings for the waiting view
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Up: key.NewBinding(
+			key.WithKeys("k", "up"),
+			key.WithHelp("k/↑", "up"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("j", "down"),
+			key.WithHelp("j/↓", "down"),
+		),
+		Nudge: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "nudge"),
+		),
+		Unwait: key.NewBinding(
+			key.WithKeys("u"),
+			key.WithHelp("u", "un-wait"),
+		),
+		Refresh: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "refresh"),
+		),
+		Quit: key.NewBinding(
+			key.WithKeys("q", "esc", "ctrl+c"),
+			key.WithHelp("q", "quit"),
+		),
+	}
+}
+
+// ShortHelp returns the bindings shown in the help line
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Up, k.Down, k.Nudge, k.Unwait, k.Refresh, k.Quit}
+}
+
+// FullHelp returns the bindings shown in the expanded help
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+type groupsLoadedMsg struct {
+	groups []Group
+}
+
+type actionDoneMsg struct {
+	message string
+}
+
+type errorMsg struct {
+	err error
+}
+
+// Model is the waiting-for dashboard
+type Model struct {
+	ctx     context.Context
+	backend taskwarrior.TaskBackend
+	keys    KeyMap
+	help    help.Model
+
+	groups   []Group
+	tasks    []*taskwarrior.TaskData // Tasks in display order
+	selected int
+	message  string
+	err      error
+	now      func() time.Time
+}
+
+// NewModel creates the waiting view for the given groups
+func NewModel(ctx context.Context, backend taskwarrior.TaskBackend, groups []Group) *Model {
+	m := &Model{
+		ctx:     ctx,
+		backend: backend,
+		keys:    DefaultKeyMap(),
+		help:    help.New(),
+		now:     time.Now,
+	}
+	m.setGroups(groups)
+	return m
+}
+
+// setGroups replaces the groups, keeping the selection in range
+func (m *Model) setGroups(groups []Group) {
+	m.groups = groups
+	m.tasks = nil
+	for _, g := range groups {
+		m.tasks = append(m.tasks, g.Tasks...)
+	}
+	m.selected = max(0, min(m.selected, len(m.tasks)-1))
+}
+
+// Init implements tea.Model
+func (m *Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.help.Width = msg.Width
+
+	case groupsLoadedMsg:
+		m.setGroups(msg.groups)
+
+	case actionDoneMsg:
+		m.message = msg.message
+		m.err = nil
+		return m, m.reload()
+
+	case errorMsg:
+		m.err = msg.err
+
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.keys.Quit):
+			return m, tea.Quit
+		case key.Matches(msg, m.keys.Up):
+			if m.selected > 0 {
+				m.selected--
+			}
+		case key.Matches(msg, m.keys.Down):
+			if m.selected < len(m.tasks)-1 {
+				m.selected++
+			}
+		case key.Matches(msg, m.keys.Refresh):
+			m.message = ""
+			return m, m.reload()
+		case key.Matches(msg, m.keys.Nudge):
+			if td := m.current(); td != nil {
+				return m, m.run(fmt.Sprintf("Nudged; waiting %s more.", nudgeInterval()), func(ctx context.Context) error {
+					return Nudge(ctx, m.backend, td)
+				})
+			}
+		case key.Matches(msg, m.keys.Unwait):
+			if td := m.current(); td != nil {
+				return m, m.run("No longer waiting.", func(ctx context.Context) error {
+					return m.backend.RemoveWaitDate(ctx, td.UUID)
+				})
+			}
+		}
+	}
+	return m, nil
+}
+
+// current returns the selected task, or nil when nothing is waiting
+func (m *Model) current() *taskwarrior.TaskData {
+	if m.selected >= len(m.tasks) {
+		return nil
+	}
+	return m.tasks[m.selected]
+}
+
+// run runs an action on the selected task
+func (m *Model) run(message string, action func(ctx context.Context) error) tea.Cmd {
+	ctx := m.ctx
+	return func() tea.Msg {
+		if err := action(ctx); err != nil {
+			return errorMsg{err}
+		}
+		return actionDoneMsg{message: message}
+	}
+}
+
+// reload loads the waiting tasks again
+func (m *Model) reload() tea.Cmd {
+	ctx := m.ctx
+	return func() tea.Msg {
+		groups, err := LoadGroups(ctx, m.backend)
+		if err != nil {
+			return errorMsg{err}
+		}
+		return groupsLoadedMsg{groups: groups}
+	}
+}
+
+// View implements tea.Model
+func (m *Model) View() string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("15")). // Bright white
+		Background(lipgloss.Color("6")).  // Cyan background
+		Bold(true).
+		Padding(0, 1)
+	groupStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("4")).Bold(true)
+	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("6")).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(fmt.Sprintf("Waiting for (%d)", len(m.tasks))))
+	b.WriteString("\n\n")
+
+	if len(m.tasks) == 0 {
+		b.WriteString("Nothing is waiting.\n")
+	}
+	now := m.now()
+	i := 0
+	for _, g := range m.groups {
+		b.WriteString(groupStyle.Render(fmt.Sprintf("%s (%d)", g.Title(), len(g.Tasks))))
+		b.WriteString("\n")
+		for _, td := range g.Tasks {
+			line := fmt.Sprintf("%s  %s", label(td), dimStyle.Render(waitSummary(td, now)))
+			if i == m.selected {
+				b.WriteString(selectedStyle.Render("> ") + line)
+			} else {
+				b.WriteString("  " + line)
+			}
+			b.WriteString("\n")
+			i++
+		}
+		b.WriteString("\n")
+	}
+
+	if m.err != nil {
+		b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Render("Error: " + m.err.Error()))
+		b.WriteString("\n")
+	} else if m.message != "" {
+		b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("2")).Render(m.message))
+		b.WriteString("\n")
+	}
+	b.WriteString(m.help.View(m.keys))
+	return b.String()
+}
+
+// label names a task by ID and description
+func label(td *taskwarrior.TaskData) string {
+	if td.ID > 0 {
+		return fmt.Sprintf("[%d] %s", td.ID, td.Description)
+	}
+	return td.Description
+}
+
+// waitSummary describes how long a task has waited and when its wait ends
+func waitSummary(td *taskwarrior.TaskData, now time.Time) string {
+	summary := "waiting since " + taskwarrior.NewDate(td.WaitingSince()).Relative(now)
+	switch {
+	case td.Wait.IsZero():
+	case td.Wait.After(now):
+		summary += ", back " + td.Wait.Relative(now)
+	default:
+		summary += ", wait ended " + td.Wait.Relative(now)
+	}
+	return summary
+}
+
+// Run starts the waiting view
+func Run() error {
+	return RunWithBackend(context.Background(), taskwarrior.NewExecBackend())
+}
+
+// RunWithBackend starts the waiting view using the given task backend.
+// Cancelling ctx stops any task call in progress.
+func RunWithBackend(ctx context.Context, backend taskwarrior.TaskBackend) error {
+	if err := backend.EnsureReviewConfig(ctx, os.Stdout); err != nil {
+		return fmt.Errorf("failed to configure wait UDAs: %w", err)
+	}
+	groups, err := LoadGroups(ctx, backend)
+	if err != nil {
+		return err
+	}
+	if len(groups) == 0 {
+		fmt.Print("\nNothing is waiting.\n\n")
+		return nil
+	}
+
+	p := tea.NewProgram(NewModel(ctx, backend, groups), tea.WithAltScreen())
+	if _, err := p.Run(); err != nil {
+		return fmt.Errorf("failed to run waiting view: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package waiting
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/emiller/tasksh/internal/taskwarrior"
+)
+
+// Group is the waited tasks that share a person and a reason
+type Group struct {
+	WaitingOn string
+	Reason    string
+	Tasks     []*taskwarrior.TaskData
+}
+
+// Title names the group for display
+func (g Group) Title() string {
+	person := g.WaitingOn
+	if person == "" {
+		person = "No one in particular"
+	}
+	if g.Reason == "" {
+		return person
+	}
+	return person + " — " + g.Reason
+}
+
+// LoadGroups loads the waited tasks grouped by person, then reason
+func LoadGroups(ctx context.Context, backend taskwarrior.TaskBackend) ([]Group, error) {
+	uuids, err := backend.FilterUUIDs(ctx, taskwarrior.WaitingFilter.Args())
+	if err != nil {
+		return nil, fmt.Errorf("failed to find waiting tasks: %w", err)
+	}
+	tasks, err := backend.GetTasksWithDataProgress(ctx, uuids, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load waiting tasks: %w", err)
+	}
+	return groupTasks(tasks), nil
+}
+
+// groupTasks groups tasks by person and reason. Groups with a person come
+// first, by name; within a group the longest waiting task comes first.
+func groupTasks(tasks []*taskwarrior.TaskData) []Group {
+	type groupKey struct{ person, reason string }
+	index := make(map[groupKey]int)
+	var groups []Group
+	for _, td := range tasks {
+		k := groupKey{td.WaitingOn(), td.WaitReason()}
+		i, ok := index[k]
+		if !ok {
+			i = len(groups)
+			index[k] = i
+			groups = append(groups, Group{WaitingOn: k.person, Reason: k.reason})
+		}
+		groups[i].Tasks = append(groups[i].Tasks, td)
+	}
+
+	// Empty names sort last
+	compareNames := func(a, b string) int {
+		if (a == "") != (b == "") {
+			if a == "" {
+				return 1
+			}
+			return -1
+		}
+		return cmp.Compare(strings.ToLower(a), strings.ToLower(b))
+	}
+	slices.SortFunc(groups, func(a, b Group) int {
+		return cmp.Or(compareNames(a.WaitingOn, b.WaitingOn), compareNames(a.Reason, b.Reason))
+	})
+	for _, g := range groups {
+		slices.SortStableFunc(g.Tasks, func(a, b *taskwarrior.TaskData) int {
+			return a.WaitingSince().Compare(b.WaitingSince())
+		})
+	}
+	return groups
+}
+
+// nudgeInterval is how long a nudged task waits again before it comes back,
+// from TASKSH_NUDGE_INTERVAL (a Taskwarrior duration such as 3d or 1w)
+func nudgeInterval() string {
+	if interval := strings.TrimSpace(os.Getenv("TASKSH_NUDGE_INTERVAL")); interval != "" {
+		return interval
+	}
+	return "3d"
+}
+
+// Nudge records that the person a task waits on was chased up and waits
+// again for the nudge interval. How long the task has been waiting is kept.
+func Nudge(ctx context.Context, backend taskwarrior.TaskBackend, td *taskwarrior.TaskData) error {
+	note := "Nudged"
+	if td.WaitingOn() != "" {
+		note += " " + td.WaitingOn()
+	}
+	if err := backend.AnnotateTask(ctx, td.UUID, note); err != nil {
+		return err
+	}
+	return backend.ModifyTask(ctx, td.UUID, "wait:now+"+nudgeInterval())
+}
@@ -0,0 +1,113 @@
+package waiting
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/emiller/tasksh/internal/taskwarrior"
+	"github.com/emiller/tasksh/internal/taskwarrior/memory"
+)
+
+var fixedNow = time.Date(2024, 6, 12, 10, 0, 0, 0, time.Local)
+
+func newWaitingBackend(t *testing.T) *memory.Backend {
+	t.Helper()
+	backend := memory.New()
+	backend.SetClock(func() time.Time { return fixedNow })
+	waits := []struct {
+		desc, reason, person string
+		days                 int // Days waited so far
+	}{
+		{"Contract signature", "signature", "Legal", 2},
+		{"Invoice approval", "signature", "Legal", 9},
+		{"Parts delivery", "shipping", "Acme", 1},
+		{"Press release", "embargo", "", 3},
+	}
+	for _, w := range waits {
+		since := fixedNow.AddDate(0, 0, -w.days).UTC().Format("20060102T150405Z")
+		mods := []string{"wait:friday", "+waiting", "waitreason:" + w.reason, "waitsince:" + since}
+		if w.person != "" {
+			mods = append(mods, "waitingon:"+w.person)
+		}
+		if _, err := backend.Add(w.desc, mods...); err != nil {
+			t.Fatalf("Failed to add task: %v", err)
+		}
+	}
+	if _, err := backend.Add("Not waiting"); err != nil {
+		t.Fatalf("Failed to add task: %v", err)
+	}
+	return backend
+}
+
+func TestLoadGroups(t *testing.T) {
+	groups, err := LoadGroups(t.Context(), newWaitingBackend(t))
+	if err != nil {
+		t.Fatalf("LoadGroups failed: %v", err)
+	}
+
+	var got []string
+	for _, g := range groups {
+		var tasks []string
+		for _, td := range g.Tasks {
+			tasks = append(tasks, td.Description)
+		}
+		got = append(got, g.Title()+": "+strings.Join(tasks, ", "))
+	}
+	want := []string{
+		"Acme — shipping: Parts delivery",
+		"Legal — signature: Invoice approval, Contract signature",
+		"No one in particular — embargo: Press release",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Groups:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestNudgeAndUnwait(t *testing.T) {
+	backend := newWaitingBackend(t)
+	groups, err := LoadGroups(t.Context(), backend)
+	if err != nil {
+		t.Fatalf("LoadGroups failed: %v", err)
+	}
+	model := NewModel(t.Context(), backend, groups)
+	model.now = func() time.Time { return fixedNow }
+	if view := model.View(); !strings.Contains(view, "Invoice approval  waiting since 9d ago, back in 1d") {
+		t.Errorf("Unexpected view:\n%s", view)
+	}
+
+	press := func(keys string) {
+		_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(keys)})
+		for cmd != nil {
+			_, cmd = model.Update(cmd())
+		}
+	}
+
+	// Nudging keeps the task waiting on the same person, for longer
+	t.Setenv("TASKSH_NUDGE_INTERVAL", "1w")
+	press("j")
+	nudged := model.current().UUID
+	press("n")
+	task, err := backend.GetTaskInfo(t.Context(), nudged)
+	if err != nil {
+		t.Fatalf("GetTaskInfo failed: %v", err)
+	}
+	if len(task.Annotations) != 1 || task.Annotations[0].Description != "Nudged Legal" {
+		t.Errorf("Expected a nudge annotation, got %+v", task.Annotations)
+	}
+	if want := fixedNow.AddDate(0, 0, 7); !task.Wait.Equal(want) || task.UDA["waitingon"] != "Legal" {
+		t.Errorf("Expected to wait on Legal until %v, got %v (%v)", want, task.Wait.Time, task.UDA)
+	}
+
+	press("u")
+	if len(model.tasks) != 3 || slices.ContainsFunc(model.tasks, func(td *taskwarrior.TaskData) bool { return td.UUID == nudged }) {
+		t.Fatalf("Expected the un-waited task to leave the view, %d left", len(model.tasks))
+	}
+	task, _ = backend.GetTaskInfo(t.Context(), nudged)
+	if task.Status != "pending" || !task.Wait.IsZero() || len(task.UDA) != 0 {
+		t.Errorf("Expected the wait cleared, got %s until %v with %v", task.Status, task.Wait.Time, task.UDA)
+	}
+}